@@ -0,0 +1,112 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// spokenStatuses are the statuses as read out in the accessible report.
+var spokenStatuses = map[Status]string{
+	StatusFinished:     "finished",
+	StatusLapped:       "lapped",
+	StatusNotFinished:  "did not finish",
+	StatusDisqualified: "disqualified",
+	StatusNotStarted:   "did not start",
+}
+
+// renderAccessible writes the results as a linearized report for screen
+// readers: one fact per line, durations and speeds spelled out, and a blank
+// line between competitors.
+func renderAccessible(w io.Writer, results []Result, _ reportStyle) error {
+	for i, r := range results {
+		var lines []string
+		if r.Place > 0 {
+			lines = append(lines, fmt.Sprintf("Rank %d.", r.Place))
+		} else {
+			lines = append(lines, "No rank.")
+		}
+		if r.PhotoFinish {
+			lines = append(lines, "Photo finish, pending confirmation.")
+		}
+		lines = append(lines, fmt.Sprintf("Competitor %s.", r.Bib))
+		status, ok := spokenStatuses[r.Status]
+		if !ok {
+			status = string(r.Status)
+		}
+		lines = append(lines, fmt.Sprintf("Status %s.", status))
+		if r.Status == StatusFinished {
+			lines = append(lines, fmt.Sprintf("Total time %s.", spokenDuration(r.Total)))
+		}
+		lines = append(lines, fmt.Sprintf("Laps completed %d.", r.LapsCompleted))
+		for k, lap := range r.Laps {
+			line := fmt.Sprintf("Lap %d time %s, speed %s", k+1, spokenDuration(lap.Time), spokenSpeed(lap.Speed))
+			if lap.ClimbSpeed != 0 {
+				line += fmt.Sprintf(", climb adjusted speed %s", spokenSpeed(lap.ClimbSpeed))
+			}
+			lines = append(lines, line+".")
+		}
+		for k, lap := range r.Penalties {
+			lines = append(lines, fmt.Sprintf("Penalty laps %d time %s, speed %s.", k+1, spokenDuration(lap.Time), spokenSpeed(lap.Speed)))
+		}
+		lines = append(lines, fmt.Sprintf("Shooting %d of %d.", r.Hits, r.Shots))
+		lines = append(lines, fmt.Sprintf("Misses %d.", r.Misses))
+		lines = append(lines, fmt.Sprintf("Range time %s.", spokenDuration(r.RangeTime)))
+		for k, t := range r.RangeTimes {
+			lines = append(lines, fmt.Sprintf("Range visit %d time %s.", k+1, spokenDuration(t)))
+		}
+		if r.Status == StatusFinished {
+			lines = append(lines, fmt.Sprintf("Course time excluding the range %s.", spokenDuration(r.CourseTime)))
+		}
+		if r.Reason != "" {
+			lines = append(lines, fmt.Sprintf("Stopped because: %s.", reasonNote(r)))
+		}
+		if i > 0 {
+			lines = append([]string{""}, lines...)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// spokenDuration spells d out for reading, e.g. "1 hour 2 minutes 31.2
+// seconds", leaving out zero hours and minutes and trailing zero
+// milliseconds.
+func spokenDuration(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	var parts []string
+	if h := d / time.Hour; h > 0 {
+		parts = append(parts, plural(int(h), "hour"))
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		parts = append(parts, plural(int(m), "minute"))
+		d -= m * time.Minute
+	}
+	if d > 0 || len(parts) == 0 {
+		seconds := strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+		unit := "seconds"
+		if seconds == "1" {
+			unit = "second"
+		}
+		parts = append(parts, seconds+" "+unit)
+	}
+	return strings.Join(parts, " ")
+}
+
+// spokenSpeed spells out a speed in m/s rounded to two decimals.
+func spokenSpeed(speed float64) string {
+	return strconv.FormatFloat(math.Round(speed*100)/100, 'f', -1, 64) + " meters per second"
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,68 @@
+package biathlon
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderAccessible(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	require.NoError(t, renderAccessible(&out, []Result{resultFixture, {Bib: Bib{Number: 3}, Status: StatusNotStarted, Shots: 10}}, reportStyle{locale: locales["en"]}))
+	require.Equal(t, "Rank 1.\n"+
+		"Photo finish, pending confirmation.\n"+
+		"Competitor 7b.\n"+
+		"Status finished.\n"+
+		"Total time 25 minutes 26.047 seconds.\n"+
+		"Laps completed 2.\n"+
+		"Lap 1 time 12 minutes 1 second, speed 4.85 meters per second, climb adjusted speed 5.12 meters per second.\n"+
+		"Lap 2 time 11 minutes 59 seconds, speed 4.87 meters per second, climb adjusted speed 5.01 meters per second.\n"+
+		"Penalty laps 1 time 29 seconds, speed 5.17 meters per second.\n"+
+		"Shooting 8 of 10.\n"+
+		"Misses 2.\n"+
+		"Range time 59.6 seconds.\n"+
+		"Range visit 1 time 31.2 seconds.\n"+
+		"Range visit 2 time 28.4 seconds.\n"+
+		"Course time excluding the range 24 minutes 26.447 seconds.\n"+
+		"Stopped because: Lost a ski (medic=yes).\n"+
+		"\n"+
+		"No rank.\n"+
+		"Competitor 3.\n"+
+		"Status did not start.\n"+
+		"Laps completed 0.\n"+
+		"Shooting 0 of 10.\n"+
+		"Misses 0.\n"+
+		"Range time 0 seconds.\n", out.String())
+}
+
+func TestSpokenDuration(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{d: 0, expected: "0 seconds"},
+		{d: time.Second, expected: "1 second"},
+		{d: 7*time.Minute + 31*time.Second + 200*time.Millisecond, expected: "7 minutes 31.2 seconds"},
+		{d: time.Hour + time.Minute, expected: "1 hour 1 minute"},
+		{d: 2*time.Hour + 500*time.Millisecond, expected: "2 hours 0.5 seconds"},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, spokenDuration(test.d))
+	}
+}
+
+func TestRunFormatAccessible(t *testing.T) {
+	var stdout bytes.Buffer
+	require.Equal(t, 0, Run([]string{"-format", "accessible"}, &stdout, &bytes.Buffer{}))
+	_, report, ok := strings.Cut(stdout.String(), "Rank 1.\n")
+	require.True(t, ok)
+	require.True(t, strings.HasPrefix(report, "Competitor 2.\nStatus finished.\nTotal time 25 minutes 18.356 seconds.\n"))
+	require.Contains(t, report, "Penalty laps 1 time 1 minute 40 seconds, speed 1.5 meters per second.\n")
+	require.Contains(t, report, "Shooting 8 of 10.\n")
+	require.NotContains(t, report, "00:")
+}
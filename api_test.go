@@ -0,0 +1,76 @@
+package biathlon_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	biathlon "BiathlonCompetitions"
+	"BiathlonCompetitions/parser"
+)
+
+func TestLibraryAPI(t *testing.T) {
+	t.Parallel()
+	cfg, err := biathlon.DecodeConfig(strings.NewReader(`{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "start": "10:00:00.000", "startDelta": "00:01:30"}`))
+	require.NoError(t, err)
+	p, err := biathlon.NewProcessor(cfg, io.Discard)
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		"[09:31:49.285] 1 1",
+		"[09:32:17.531] 1 2",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[09:56:30.000] 2 2 10:01:30.000",
+		"[10:00:01.744] 4 1",
+		"[10:12:35.380] 10 1",
+		"[10:25:26.047] 10 1",
+	} {
+		e, err := parser.ParseEvent(line)
+		require.NoError(t, err)
+		require.NoError(t, p.Process(e))
+	}
+
+	results := p.Results()
+	require.Len(t, results, 2)
+	require.Equal(t, parser.Bib{Number: 1}, results[0].Bib)
+	require.Equal(t, biathlon.StatusFinished, results[0].Status)
+	require.Equal(t, biathlon.StatusNotStarted, results[1].Status)
+
+	var out bytes.Buffer
+	require.NoError(t, biathlon.Render(&out, results, "text"))
+	require.Equal(t, "1. 25:26.047 Competitor 1: laps count 2, laps [{00:12:35.380, 4.633}, {00:12:50.667, 4.542}], Penalty [], Hits 0/10, Misses 0, Range 00:00:00.000 [], Course 00:25:26.047\n"+
+		"- [NotStarted] Competitor 2: laps count 0, laps [], Penalty [], Hits 0/10, Misses 0, Range 00:00:00.000 []\n", out.String())
+
+	out.Reset()
+	require.NoError(t, biathlon.Render(&out, results, "json"))
+	var rows []map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &rows))
+	require.Len(t, rows, 2)
+	require.Equal(t, "Finished", rows[0]["status"])
+	require.Error(t, biathlon.Render(&out, results, "xml"))
+}
+
+func TestNewProcessorInvalidConfig(t *testing.T) {
+	t.Parallel()
+	_, err := biathlon.NewProcessor(biathlon.Config{Start: "10:00", StartDelta: "00:01:30", StartLineTimeout: "00:02:00"}, io.Discard)
+	require.ErrorContains(t, err, "invalid start time in config")
+}
+
+func TestProcessRace(t *testing.T) {
+	t.Parallel()
+	config := `{"laps": 1, "lapLen": 3500, "penaltyLen": 150, "start": "10:00:00.000", "startDelta": "00:01:30"}`
+	events := "[09:30:01.000] 1 2\n[09:30:00.000] 1 1\n[09:45:00.000] 2 1 10:00:00.000\n[10:00:00.500] 4 1\n[10:12:40.000] 10 1\n"
+	results, err := biathlon.ProcessRace(strings.NewReader(config), strings.NewReader(events))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, biathlon.StatusFinished, results[0].Status)
+	require.Equal(t, 12*time.Minute+40*time.Second, results[0].Total)
+
+	_, err = biathlon.ProcessRace(strings.NewReader(`{"laps": 1, "lapLen": 3500, "penaltyLen": 150, "start": "10:00:00.000", "startDelta": "00:01:30", "profile": "profile.json"}`), strings.NewReader(""))
+	require.ErrorContains(t, err, "course profile needs a file")
+}
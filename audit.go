@@ -0,0 +1,93 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	WarnPenaltyLoopsSkipped WarningCode = "penalty_loops_skipped"
+	WarnPenaltyLapsMissing  WarningCode = "penalty_laps_missing"
+)
+
+// estimatedPenaltyLoops estimates how many loops of penaltyLen meters were
+// skied in d at the given speed.
+func estimatedPenaltyLoops(d time.Duration, speed float64, penaltyLen int) float64 {
+	return d.Seconds() * speed / float64(penaltyLen)
+}
+
+// courseSpeed is the competitor's average speed over the main laps, with the
+// time spent in the penalty laps taken out.
+func courseSpeed(comp *Competitor, cfg Config) float64 {
+	if comp.LapsCompleted == 0 {
+		return 0
+	}
+	elapsed := comp.FinishTime.Sub(comp.StartTime) - totalDuration(comp.PenaltyTimes)
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(comp.LapsCompleted*cfg.LapLen) / elapsed.Seconds()
+}
+
+func totalDuration(durations []time.Duration) time.Duration {
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	return total
+}
+
+// auditPenaltyLoops flags competitors whose time in the penalty laps is too
+// short for the number of loops their misses require. Competitors who
+// never skied the penalty laps are left to auditMissingPenaltyLaps.
+func auditPenaltyLoops(competitors map[Bib]*Competitor, cfg Config) []Warning {
+	var warnings []Warning
+	for _, bib := range sortedBibs(competitors) {
+		comp := competitors[bib]
+		required := comp.misses(cfg)
+		speed := courseSpeed(comp, cfg)
+		if required <= 0 || speed == 0 || len(comp.PenaltyTimes) == 0 {
+			continue
+		}
+		duration := totalDuration(comp.PenaltyTimes)
+		estimated := estimatedPenaltyLoops(duration, speed, cfg.PenaltyLen)
+		if estimated+cfg.PenaltyLoopTolerance < float64(required) {
+			warnings = append(warnings, Warning{Code: WarnPenaltyLoopsSkipped, Message: fmt.Sprintf(
+				"competitor(%s) may have skipped penalty loops: required %d, estimated %.1f from %s",
+				bib, required, estimated, formatDuration(duration),
+			)})
+		}
+	}
+	return warnings
+}
+
+// auditMissingPenaltyLaps flags the competitors who missed targets but
+// never went through the penalty laps, entering and leaving them.
+func auditMissingPenaltyLaps(competitors map[Bib]*Competitor, cfg Config) []Warning {
+	var warnings []Warning
+	for _, bib := range sortedBibs(competitors) {
+		comp := competitors[bib]
+		if misses := comp.misses(cfg); misses > 0 && len(comp.PenaltyTimes) == 0 {
+			warnings = append(warnings, Warning{Code: WarnPenaltyLapsMissing, Message: fmt.Sprintf(
+				"competitor(%s) missed %d targets but never skied the penalty laps", bib, misses)})
+		}
+	}
+	return warnings
+}
+
+// printAudit prints the audit warnings followed by every incident reported
+// by the marshals.
+func printAudit(w io.Writer, warnings []Warning, competitors map[Bib]*Competitor) {
+	incidents := allIncidents(competitors)
+	if len(warnings) == 0 && len(incidents) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nAudit:")
+	for _, warning := range warnings {
+		fmt.Fprintln(w, warning)
+	}
+	for _, in := range incidents {
+		fmt.Fprintln(w, in)
+	}
+}
@@ -0,0 +1,86 @@
+package biathlon
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditPenaltyLoops(t *testing.T) {
+	cfg := Config{Laps: 2, LapLen: 3000, PenaltyLen: 150, TargetsPerLine: 5, PenaltyLoopTolerance: 0.5}
+	start, _ := time.Parse(timeLayout, "10:00:00.000")
+	newCompetitor := func(id int, penalties ...time.Duration) *Competitor {
+		// 6000 m of main laps in 1000 s excluding the penalty time: 6 m/s,
+		// so one 150 m penalty loop takes 25 s.
+		return &Competitor{
+			ID:            id,
+			LapsCompleted: 2,
+			Bouts:         make([]Bout, 2),
+			Hits:          8,
+			StartTime:     start,
+			FinishTime:    start.Add(1000*time.Second + totalDuration(penalties)),
+			PenaltyTimes:  penalties,
+		}
+	}
+	competitors := map[Bib]*Competitor{
+		{Number: 1}: newCompetitor(1, 25*time.Second, 25*time.Second),
+		{Number: 2}: newCompetitor(2, 25*time.Second),
+		{Number: 3}: newCompetitor(3, 20*time.Second, 20*time.Second),
+	}
+
+	warnings := auditPenaltyLoops(competitors, cfg)
+	require.Len(t, warnings, 1)
+	require.Equal(t, WarnPenaltyLoopsSkipped, warnings[0].Code)
+	require.Equal(t, "competitor(2) may have skipped penalty loops: required 2, estimated 1.0 from 00:00:25.000", warnings[0].Message)
+}
+
+func TestEstimatedPenaltyLoops(t *testing.T) {
+	require.InDelta(t, 2.0, estimatedPenaltyLoops(50*time.Second, 6, 150), 1e-9)
+	require.InDelta(t, 0.0, estimatedPenaltyLoops(0, 6, 150), 1e-9)
+}
+
+func TestMisses(t *testing.T) {
+	t.Parallel()
+	hits := func(n int) []string {
+		var lines []string
+		for target := 1; target <= n; target++ {
+			lines = append(lines, fmt.Sprintf("[10:05:0%d.000] 6 1 %d", target, target))
+		}
+		return lines
+	}
+	tests := []struct {
+		name     string
+		hits     int
+		penalty  bool
+		misses   int
+		warnings []Warning
+	}{
+		{name: "clean shooting", hits: 5, misses: 0},
+		{name: "partial misses", hits: 3, penalty: true, misses: 2},
+		{name: "full miss bout", hits: 0, misses: 5, warnings: []Warning{{
+			Code: WarnPenaltyLapsMissing, Message: "competitor(1) missed 5 targets but never skied the penalty laps",
+		}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			lines := []string{"[09:31:49.285] 1 1", "[09:55:00.000] 2 1 10:00:00.000", "[10:00:01.744] 4 1", "[10:05:00.000] 5 1 1"}
+			lines = append(lines, hits(test.hits)...)
+			lines = append(lines, "[10:05:30.000] 7 1")
+			if test.penalty {
+				lines = append(lines, "[10:05:40.000] 8 1", "[10:06:30.000] 9 1")
+			}
+			r := newTestRace(t, lines...)
+			p := newProcessor(r, nil, &bytes.Buffer{})
+			require.NoError(t, p.ProcessAll(r.events))
+
+			results := results(p.Competitors(), r.cfg, r.profile)
+			require.Equal(t, test.hits, results[0].Hits)
+			require.Equal(t, test.misses, results[0].Misses)
+			require.Equal(t, test.warnings, auditMissingPenaltyLaps(p.Competitors(), r.cfg))
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package biathlon
+
+import "sort"
+
+func (c *Competitor) Bib() Bib {
+	return Bib{Number: c.ID, Suffix: c.Suffix}
+}
+
+// sortedBibs returns the keys of competitors in bib order.
+func sortedBibs(competitors map[Bib]*Competitor) []Bib {
+	bibs := make([]Bib, 0, len(competitors))
+	for bib := range competitors {
+		bibs = append(bibs, bib)
+	}
+	sort.Slice(bibs, func(i, j int) bool {
+		return bibs[i].Less(bibs[j])
+	})
+	return bibs
+}
@@ -0,0 +1,24 @@
+package biathlon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"BiathlonCompetitions/parser"
+)
+
+func TestSuffixedBibsAreDistinctCompetitors(t *testing.T) {
+	plain, err := parser.ParseEvent("[09:31:49.285] 1 7")
+	require.NoError(t, err)
+	reserve, err := parser.ParseEvent("[09:32:17.531] 1 7b")
+	require.NoError(t, err)
+
+	competitors := map[Bib]*Competitor{
+		plain.Bib():   {ID: plain.CompetitorID, Suffix: plain.Suffix},
+		reserve.Bib(): {ID: reserve.CompetitorID, Suffix: reserve.Suffix},
+	}
+	require.Len(t, competitors, 2)
+	require.Equal(t, []Bib{{Number: 7}, {Number: 7, Suffix: "b"}}, sortedBibs(competitors))
+	require.Equal(t, "7b", competitors[reserve.Bib()].Bib().String())
+}
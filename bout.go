@@ -0,0 +1,239 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Shooting positions of a bout in the config's firing order.
+const (
+	PositionProne    = "P"
+	PositionStanding = "S"
+)
+
+const (
+	WarnShotOutsideBout    WarningCode = "shot_outside_bout"
+	WarnHitOutsideBout     WarningCode = "hit_outside_bout"
+	WarnTargetOutOfRange   WarningCode = "target_out_of_range"
+	WarnDuplicateHit       WarningCode = "duplicate_hit"
+	WarnExitWithoutArrival WarningCode = "range_exit_without_arrival"
+	WarnBoutMismatch       WarningCode = "bout_index_mismatch"
+)
+
+// Shot is a single trigger pull reported by the target system.
+type Shot struct {
+	Time time.Time
+	Hit  bool
+}
+
+// Bout is one visit to the firing range, from onTheFiringRange to
+// leftTheFiringRange. Shots are only known when the target system sends
+// shot events; hits are still counted from hit events.
+type Bout struct {
+	// Index is the 1-based number of the shooting within the race.
+	Index int
+	// Line is the firing range number carried by onTheFiringRange, 0 when
+	// the event had none.
+	Line int
+	// Position is PositionProne or PositionStanding when the config
+	// declares the firing order, empty otherwise.
+	Position string
+	Start    time.Time
+	End      time.Time
+	Shots    []Shot
+	// Hits counts the hit events received during the bout.
+	Hits int
+	// HitTargets are the targets hit during the bout, each at most once.
+	HitTargets []int
+	// Unobserved marks a bout the range system lost, inferred from a visit
+	// to the penalty laps. Its hits are unknown.
+	Unobserved bool
+	// Interrupted marks a bout the competitor left and resumed, as after a
+	// rifle malfunction. Gap is the time spent off the mat in between.
+	Interrupted bool
+	Gap         time.Duration
+}
+
+func (b *Bout) open() bool {
+	return b.End.IsZero()
+}
+
+// position names the shooting position of the bout, or "bout N" when it
+// is unknown.
+func (b *Bout) position() string {
+	switch b.Position {
+	case PositionProne:
+		return "prone"
+	case PositionStanding:
+		return "standing"
+	}
+	return fmt.Sprintf("bout %d", b.Index)
+}
+
+// Rhythm describes the shooting rhythm of a bout.
+type Rhythm struct {
+	Shots          int
+	FirstShotDelay time.Duration
+	Intervals      []time.Duration
+}
+
+// rhythm computes the shooting rhythm of the bout. ok is false when the
+// bout has no shot data.
+func (b *Bout) rhythm() (r Rhythm, ok bool) {
+	if len(b.Shots) == 0 {
+		return Rhythm{}, false
+	}
+	r.Shots = len(b.Shots)
+	r.FirstShotDelay = b.Shots[0].Time.Sub(b.Start)
+	for i := 1; i < len(b.Shots); i++ {
+		r.Intervals = append(r.Intervals, b.Shots[i].Time.Sub(b.Shots[i-1].Time))
+	}
+	return r, true
+}
+
+// misses is the number of targets of the bout that weren't hit. The misses
+// of an unobserved bout are unknown and count as 0.
+func (b *Bout) misses(targets int) int {
+	if b.Unobserved {
+		return 0
+	}
+	return max(targets-b.Hits, 0)
+}
+
+// accuracy formats the hits of the bout out of targets as "range 1: 4/5",
+// naming the bout by number when its range is unknown.
+func (b *Bout) accuracy(targets int) string {
+	name := fmt.Sprintf("bout %d", b.Index)
+	if b.Line != 0 {
+		name = fmt.Sprintf("range %d", b.Line)
+	}
+	if b.Interrupted {
+		return fmt.Sprintf("%s: %d/%d (interrupted)", name, b.Hits, targets)
+	}
+	return fmt.Sprintf("%s: %d/%d", name, b.Hits, targets)
+}
+
+// rangeTime is the time the bout took, less the time off the mat of an
+// interrupted bout when excludeGap is set.
+func (b *Bout) rangeTime(excludeGap bool) time.Duration {
+	if excludeGap {
+		return b.End.Sub(b.Start) - b.Gap
+	}
+	return b.End.Sub(b.Start)
+}
+
+// resumedBout returns the bout a return to the firing range at t resumes,
+// or nil when it starts a new bout. The competitor must have left the last
+// bout of the lap no longer than the resume window ago, with targets still
+// to hit and without going to the penalty laps since.
+func (p *Processor) resumedBout(c *Competitor, t time.Time) *Bout {
+	if p.resumeWindow <= 0 || !c.shotThisLap() {
+		return nil
+	}
+	b := &c.Bouts[len(c.Bouts)-1]
+	if b.open() || b.Unobserved || b.Hits >= p.cfg.TargetsPerLine || t.Sub(b.End) > p.resumeWindow {
+		return nil
+	}
+	if n := len(c.Penalties); n > 0 && !c.Penalties[n-1].Start.Before(b.End) {
+		return nil
+	}
+	return b
+}
+
+// observedBouts counts the bouts the range system reported.
+func (c *Competitor) observedBouts() int {
+	n := 0
+	for i := range c.Bouts {
+		if !c.Bouts[i].Unobserved {
+			n++
+		}
+	}
+	return n
+}
+
+// completedBouts counts the bouts the competitor has left the range after.
+func (c *Competitor) completedBouts() int {
+	n := 0
+	for i := range c.Bouts {
+		if !c.Bouts[i].open() {
+			n++
+		}
+	}
+	return n
+}
+
+// openBout returns the bout the competitor is currently shooting, if any.
+func (c *Competitor) openBout() *Bout {
+	if len(c.Bouts) == 0 || !c.Bouts[len(c.Bouts)-1].open() {
+		return nil
+	}
+	return &c.Bouts[len(c.Bouts)-1]
+}
+
+// printRhythm prints the shooting rhythm of every bout for which shot
+// events were received. Nothing is printed when no bout has shot data.
+func printRhythm(w io.Writer, competitors map[Bib]*Competitor) {
+	if !hasShotData(competitors) {
+		return
+	}
+	fmt.Fprintln(w, "\nShooting rhythm:")
+	for _, bib := range sortedBibs(competitors) {
+		for i := range competitors[bib].Bouts {
+			bout := &competitors[bib].Bouts[i]
+			r, ok := bout.rhythm()
+			if !ok {
+				fmt.Fprintf(w, "Competitor %s, bout %d: no shot data\n", bib, bout.Index)
+				continue
+			}
+			intervals := make([]string, len(r.Intervals))
+			for j, d := range r.Intervals {
+				intervals[j] = formatDuration(d)
+			}
+			fmt.Fprintf(w, "Competitor %s, bout %d: %d shots, first shot after %s, intervals [%s]\n",
+				bib, bout.Index, r.Shots, formatDuration(r.FirstShotDelay), strings.Join(intervals, ", "))
+		}
+	}
+}
+
+func hasShotData(competitors map[Bib]*Competitor) bool {
+	for _, comp := range competitors {
+		for _, b := range comp.Bouts {
+			if len(b.Shots) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// printBoutAccuracy prints the accuracy of every completed and observed bout
+// of every competitor, followed by the overall percentage. Nothing is
+// printed when no bout was completed.
+func printBoutAccuracy(w io.Writer, competitors map[Bib]*Competitor, targets int) {
+	if targets <= 0 {
+		return
+	}
+	header := false
+	for _, bib := range sortedBibs(competitors) {
+		var bouts []string
+		hits := 0
+		for i := range competitors[bib].Bouts {
+			b := &competitors[bib].Bouts[i]
+			if b.open() || b.Unobserved {
+				continue
+			}
+			bouts = append(bouts, b.accuracy(targets))
+			hits += b.Hits
+		}
+		if len(bouts) == 0 {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "\nShooting accuracy:")
+			header = true
+		}
+		fmt.Fprintf(w, "competitor(%s) %s, overall %.0f%%\n", bib, strings.Join(bouts, ", "), float64(hits)*100/float64(len(bouts)*targets))
+	}
+}
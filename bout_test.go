@@ -0,0 +1,237 @@
+package biathlon
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"BiathlonCompetitions/parser"
+)
+
+func TestBoutRhythm(t *testing.T) {
+	start, _ := time.Parse(timeLayout, "10:08:49.000")
+	bout := Bout{Line: 1, Start: start}
+	for _, offset := range []time.Duration{12 * time.Second, 15 * time.Second, 17500 * time.Millisecond, 20 * time.Second, 23200 * time.Millisecond} {
+		bout.Shots = append(bout.Shots, Shot{Time: start.Add(offset), Hit: true})
+	}
+
+	r, ok := bout.rhythm()
+	require.True(t, ok)
+	require.Equal(t, 5, r.Shots)
+	require.Equal(t, 12*time.Second, r.FirstShotDelay)
+	require.Equal(t, []time.Duration{3 * time.Second, 2500 * time.Millisecond, 2500 * time.Millisecond, 3200 * time.Millisecond}, r.Intervals)
+}
+
+func TestBoutRhythmWithoutShots(t *testing.T) {
+	bout := Bout{Line: 2}
+	_, ok := bout.rhythm()
+	require.False(t, ok)
+}
+
+func TestOpenBout(t *testing.T) {
+	start, _ := time.Parse(timeLayout, "10:08:49.000")
+	comp := &Competitor{}
+	require.Nil(t, comp.openBout())
+
+	comp.Bouts = append(comp.Bouts, Bout{Start: start})
+	require.Same(t, &comp.Bouts[0], comp.openBout())
+
+	comp.Bouts[0].End = start.Add(time.Minute)
+	require.Nil(t, comp.openBout())
+}
+
+func TestBoutIndex(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name             string
+		lines            []string
+		expectedLines    []LogLine
+		expectedWarnings []Warning
+	}{
+		{
+			name:  "test_without_embedded_index",
+			lines: []string{"[10:08:49.289] 5 1 3", "[10:08:55.658] 7 1", "[10:21:34.847] 5 1 4"},
+			expectedLines: []LogLine{
+				"[10:08:49.289] The competitor(1) is on the firing range (shooting 1, line 3)",
+				"[10:08:55.658] The competitor(1) left the firing range (0)",
+				"[10:21:34.847] The competitor(1) is on the firing range (shooting 2, line 4)",
+			},
+		},
+		{
+			name:  "test_with_matching_embedded_index",
+			lines: []string{"[10:08:49.289] 5 1 3 1", "[10:08:55.658] 7 1", "[10:21:34.847] 5 1 4 2"},
+			expectedLines: []LogLine{
+				"[10:08:49.289] The competitor(1) is on the firing range (shooting 1, line 3)",
+				"[10:08:55.658] The competitor(1) left the firing range (0)",
+				"[10:21:34.847] The competitor(1) is on the firing range (shooting 2, line 4)",
+			},
+		},
+		{
+			name:  "test_with_mismatching_embedded_index",
+			lines: []string{"[10:08:49.289] 5 1 3 1", "[10:08:55.658] 7 1", "[10:21:34.847] 5 1 4 3"},
+			expectedLines: []LogLine{
+				"[10:08:49.289] The competitor(1) is on the firing range (shooting 1, line 3)",
+				"[10:08:55.658] The competitor(1) left the firing range (0)",
+				"[10:21:34.847] The competitor(1) is on the firing range (shooting 2, line 4)",
+			},
+			expectedWarnings: []Warning{{Code: WarnBoutMismatch, Message: "range system reports shooting 3, expected shooting 2"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+			c := &Competitor{ID: 1}
+			handlers := map[int]handler{onTheFiringRange: handleOnTheFiringRange, leftTheFiringRange: handleLeftTheFiringRange}
+			var lines []LogLine
+			var warnings []Warning
+			for _, line := range test.lines {
+				e, err := parser.ParseEvent(line)
+				require.NoError(t, err)
+				l, w, err := handlers[e.EventID](p, c, e)
+				require.NoError(t, err)
+				lines = append(lines, l...)
+				warnings = append(warnings, w...)
+			}
+			require.Equal(t, test.expectedLines, lines)
+			require.Equal(t, test.expectedWarnings, warnings)
+			require.Equal(t, []int{1, 2}, []int{c.Bouts[0].Index, c.Bouts[1].Index})
+		})
+	}
+}
+
+func TestBoutAccuracy(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:32:17.531] 1 2",
+		"[10:08:49.289] 5 1 1",
+		"[10:08:50.884] 6 1 1",
+		"[10:08:51.400] 6 1 2",
+		"[10:08:55.658] 7 1",
+		"[10:21:34.847] 5 1 2",
+		"[10:21:36.495] 6 1 1",
+		"[10:21:36.920] 6 1 2",
+		"[10:21:37.626] 6 1 3",
+		"[10:21:38.628] 6 1 4",
+		"[10:21:39.628] 6 1 5",
+		"[10:21:41.449] 7 1",
+		"[10:08:00.000] 5 2",
+		"[10:08:01.000] 6 2 1",
+		"[10:08:09.000] 7 2",
+		"[10:20:00.000] 5 2 2",
+	)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+
+	out.Reset()
+	printBoutAccuracy(&out, p.Competitors(), r.cfg.TargetsPerLine)
+	require.Equal(t, "\nShooting accuracy:\n"+
+		"competitor(1) range 1: 2/5, range 2: 5/5, overall 70%\n"+
+		"competitor(2) bout 1: 1/5, overall 20%\n", out.String())
+}
+
+func TestBoutResume(t *testing.T) {
+	t.Parallel()
+	start := []string{"[09:31:49.285] 1 1", "[09:35:00.000] 2 1 10:00:00.000", "[10:00:01.000] 4 1",
+		"[10:08:49.000] 5 1 1 1", "[10:08:50.000] 6 1 1", "[10:08:51.000] 6 1 2", "[10:08:55.000] 7 1"}
+	tests := []struct {
+		name          string
+		window        time.Duration
+		excludeGap    bool
+		lines         []string
+		expectedBouts []string
+		rangeTimes    []time.Duration
+		warnings      int
+	}{
+		{
+			name:   "test_resume_within_window",
+			window: time.Minute,
+			lines: []string{"[10:09:40.000] 5 1 1 1", "[10:09:41.000] 6 1 3", "[10:09:42.000] 6 1 2", "[10:09:43.000] 6 1 4", "[10:10:00.000] 7 1",
+				"[10:12:00.000] 10 1", "[10:21:00.000] 5 1 2 2", "[10:21:10.000] 7 1"},
+			expectedBouts: []string{"range 1: 4/5 (interrupted)", "range 2: 0/5"},
+			rangeTimes:    []time.Duration{71 * time.Second, 10 * time.Second},
+			// The duplicate hit of target 2 across the segments.
+			warnings: 1,
+		},
+		{
+			name:          "test_resume_excluding_gap",
+			window:        time.Minute,
+			excludeGap:    true,
+			lines:         []string{"[10:09:40.000] 5 1 1", "[10:09:41.000] 6 1 3", "[10:10:00.000] 7 1"},
+			expectedBouts: []string{"range 1: 3/5 (interrupted)"},
+			rangeTimes:    []time.Duration{26 * time.Second},
+		},
+		{
+			name:          "test_return_after_window",
+			window:        30 * time.Second,
+			lines:         []string{"[10:09:40.000] 5 1 1 2", "[10:10:00.000] 7 1"},
+			expectedBouts: []string{"range 1: 2/5", "range 1: 0/5"},
+			rangeTimes:    []time.Duration{6 * time.Second, 20 * time.Second},
+		},
+		{
+			name:          "test_separate_bouts_of_two_laps",
+			window:        time.Hour,
+			lines:         []string{"[10:12:00.000] 10 1", "[10:21:00.000] 5 1 2 2", "[10:21:01.000] 6 1 1", "[10:21:10.000] 7 1"},
+			expectedBouts: []string{"range 1: 2/5", "range 2: 1/5"},
+			rangeTimes:    []time.Duration{6 * time.Second, 10 * time.Second},
+		},
+		{
+			name:          "test_penalty_laps_before_return",
+			window:        time.Minute,
+			lines:         []string{"[10:09:00.000] 8 1", "[10:09:30.000] 9 1", "[10:09:40.000] 5 1 1 2", "[10:10:00.000] 7 1"},
+			expectedBouts: []string{"range 1: 2/5", "range 1: 0/5"},
+			rangeTimes:    []time.Duration{6 * time.Second, 20 * time.Second},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r := newTestRace(t, append(append([]string(nil), start...), test.lines...)...)
+			r.resumeWindow = test.window
+			r.cfg.ResumeExcludesGap = test.excludeGap
+			var out bytes.Buffer
+			p := newProcessor(r, nil, &out)
+			require.NoError(t, p.ProcessAll(r.events))
+			require.Equal(t, test.warnings, p.warnings, out.String())
+
+			c := p.Competitors()[Bib{Number: 1}]
+			var bouts []string
+			for i := range c.Bouts {
+				bouts = append(bouts, c.Bouts[i].accuracy(r.cfg.TargetsPerLine))
+			}
+			require.Equal(t, test.expectedBouts, bouts)
+			require.Equal(t, test.rangeTimes, c.RangeTimes)
+		})
+	}
+}
+
+func TestBoutResumeCommentary(t *testing.T) {
+	r := newTestRace(t, "[09:31:49.285] 1 1", "[10:00:01.000] 4 1", "[10:08:49.000] 5 1 1 1", "[10:08:55.000] 7 1", "[10:09:40.000] 5 1 1 1")
+	r.resumeWindow = time.Minute
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Contains(t, out.String(), "[10:09:40.000] The competitor(1) is on the firing range (shooting 1 resumed, line 1)\n")
+	require.NotContains(t, out.String(), "bout_index_mismatch")
+	c := p.Competitors()[Bib{Number: 1}]
+	require.Len(t, c.Bouts, 1)
+	require.Equal(t, 45*time.Second, c.Bouts[0].Gap)
+}
+
+func TestLoadConfigResumeWindow(t *testing.T) {
+	t.Parallel()
+	cfg, err := DecodeConfig(strings.NewReader("{" + baseConfigFields + `, "resumeWindow": "00:01:00", "resumeExcludesGap": true}`))
+	require.NoError(t, err)
+	r, err := newRace(cfg)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, r.resumeWindow)
+	require.True(t, cfg.ResumeExcludesGap)
+
+	_, err = DecodeConfig(strings.NewReader("{" + baseConfigFields + `, "resumeWindow": "1m"}`))
+	require.ErrorContains(t, err, `resumeWindow: invalid delta: "1m"`)
+}
@@ -0,0 +1,118 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// clockTimes is a flag value holding a comma-separated list of clock times
+// such as 11:00,11:30:15, kept in ascending order.
+type clockTimes []time.Time
+
+func (c *clockTimes) String() string {
+	times := make([]string, len(*c))
+	for i, t := range *c {
+		times[i] = t.Format(timeLayout)
+	}
+	return strings.Join(times, ",")
+}
+
+func (c *clockTimes) Set(s string) error {
+	var times clockTimes
+	for _, part := range strings.Split(s, ",") {
+		t, err := parseClockTime(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	*c = times
+	return nil
+}
+
+// parseClockTime parses HH:MM, HH:MM:SS or HH:MM:SS.sss.
+func parseClockTime(s string) (time.Time, error) {
+	for _, layout := range []string{"15:04", "15:04:05", timeLayout} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid clock time %q, expected HH:MM[:SS[.sss]]", s)
+}
+
+// ProcessWithBulletins applies events like ProcessAll and calls bulletin
+// with the 1-based bulletin number at every time of at, once every event up
+// to that time has been applied. Bulletins after the last event are issued
+// once the events are finished and the custom rules applied.
+func (p *Processor) ProcessWithBulletins(events []Event, at []time.Time, bulletin func(n int, asOf time.Time) error) error {
+	p.courseOpened, p.courseClosed = courseWindow(events)
+	p.knownGaps = telemetryGaps(events, p.heartbeats.timeout)
+	next := 0
+	for _, e := range events {
+		for ; next < len(at) && e.Time.After(at[next]); next++ {
+			if err := bulletin(next+1, at[next]); err != nil {
+				return err
+			}
+		}
+		if err := p.Process(e); err != nil {
+			return err
+		}
+	}
+	p.finish()
+	applyRules(p.competitors, p.rules, p.cfg)
+	applyExchangePenalties(p.competitors, p.decisions)
+	settleStatuses(p.competitors, p.cfg)
+	for ; next < len(at); next++ {
+		if err := bulletin(next+1, at[next]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onCourse counts the competitors who have started and neither finished
+// nor dropped out.
+func onCourse(competitors map[Bib]*Competitor, cfg Config) int {
+	n := 0
+	for _, comp := range competitors {
+		if comp.Started && comp.LapsCompleted < cfg.Laps && !comp.retired && !comp.lateStart && !comp.disqualified && comp.pulled == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// bulletinPath is the file of the n-th bulletin in dir.
+func bulletinPath(dir string, n int) string {
+	return filepath.Join(dir, fmt.Sprintf("bulletin-%02d.txt", n))
+}
+
+// writeBulletin writes the intermediate results as of asOf to the n-th
+// bulletin file in dir.
+func writeBulletin(dir string, n int, asOf time.Time, p *Processor, r race, style reportStyle) (err error) {
+	f, err := os.Create(bulletinPath(dir, n))
+	if err != nil {
+		return err
+	}
+	defer func(f *os.File) {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+	printBulletin(f, asOf, p, r, style)
+	return nil
+}
+
+// printBulletin prints the intermediate report labeled with its as-of time
+// and the number of competitors still on the course.
+func printBulletin(w io.Writer, asOf time.Time, p *Processor, r race, style reportStyle) {
+	fmt.Fprintf(w, "Intermediate bulletin as of %s, %d competitors on course\n",
+		asOf.Format(timeLayout), onCourse(p.Competitors(), r.cfg))
+	printReport(w, p, r, style)
+}
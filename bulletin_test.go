@@ -0,0 +1,57 @@
+package biathlon
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockTimesFlag(t *testing.T) {
+	t.Parallel()
+	var c clockTimes
+	require.NoError(t, c.Set("11:30, 11:00:15"))
+	require.Equal(t, "11:00:15.000,11:30:00.000", c.String())
+	require.Error(t, c.Set("11h"))
+}
+
+func TestProcessWithBulletins(t *testing.T) {
+	r, err := loadRace("config/config.json", "", "events", false)
+	require.NoError(t, err)
+	var at clockTimes
+	require.NoError(t, at.Set("10:05,10:20"))
+
+	var bulletins []string
+	p := newProcessor(r, nil, io.Discard)
+	err = p.ProcessWithBulletins(r.events, at, func(n int, asOf time.Time) error {
+		var out bytes.Buffer
+		printBulletin(&out, asOf, p, r, reportStyle{locale: locales["en"]})
+		bulletins = append(bulletins, out.String())
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, bulletins, 2)
+
+	// At 10:05 four competitors have started, at 10:20 all five are out.
+	require.True(t, strings.HasPrefix(bulletins[0], "Intermediate bulletin as of 10:05:00.000, 4 competitors on course\n\nFinal results:\n"))
+	require.True(t, strings.HasPrefix(bulletins[1], "Intermediate bulletin as of 10:20:00.000, 5 competitors on course\n"))
+	require.Contains(t, bulletins[1], "Lap 1:\n")
+	require.NotContains(t, bulletins[1], "Lap 2:\n")
+}
+
+func TestRunWritesBulletins(t *testing.T) {
+	dir := t.TempDir()
+	code := Run([]string{"-bulletin-at", "10:05,23:00", "-bulletin-dir", dir}, io.Discard, io.Discard)
+	require.Equal(t, 0, code)
+	first, err := os.ReadFile(filepath.Join(dir, "bulletin-01.txt"))
+	require.NoError(t, err)
+	require.Contains(t, string(first), "as of 10:05:00.000, 4 competitors on course")
+	last, err := os.ReadFile(filepath.Join(dir, "bulletin-02.txt"))
+	require.NoError(t, err)
+	require.Contains(t, string(last), "as of 23:00:00.000, 0 competitors on course")
+}
@@ -0,0 +1,104 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StartGap is the time between two consecutive actual starts next to the
+// gap their drawn start times call for.
+type StartGap struct {
+	From, To Bib
+	Gap      time.Duration
+	Nominal  time.Duration
+	// DNS are the competitors drawn between From and To who never
+	// started; they make a longer gap expected.
+	DNS []Bib
+}
+
+// Deviation is how much longer than nominal the gap was.
+func (g StartGap) Deviation() time.Duration {
+	return g.Gap - g.Nominal
+}
+
+// startGaps lists the gaps between consecutive actual starts in start order.
+func startGaps(competitors map[Bib]*Competitor) []StartGap {
+	var started, dns []*Competitor
+	for _, bib := range sortedBibs(competitors) {
+		comp := competitors[bib]
+		switch {
+		case comp.Started:
+			started = append(started, comp)
+		case !comp.StartTime.IsZero():
+			dns = append(dns, comp)
+		}
+	}
+	sort.SliceStable(started, func(i, j int) bool {
+		return started[i].ActualStart.Before(started[j].ActualStart)
+	})
+	var gaps []StartGap
+	for i := 1; i < len(started); i++ {
+		from, to := started[i-1], started[i]
+		g := StartGap{
+			From:    from.Bib(),
+			To:      to.Bib(),
+			Gap:     to.ActualStart.Sub(from.ActualStart),
+			Nominal: to.StartTime.Sub(from.StartTime),
+		}
+		for _, comp := range dns {
+			if comp.StartTime.After(from.StartTime) && comp.StartTime.Before(to.StartTime) {
+				g.DNS = append(g.DNS, comp.Bib())
+			}
+		}
+		gaps = append(gaps, g)
+	}
+	return gaps
+}
+
+// longestStall returns the gap that ran longest over its nominal length.
+// ok is false when no gap ran over.
+func longestStall(gaps []StartGap) (stall StartGap, ok bool) {
+	for _, g := range gaps {
+		if g.Deviation() > 0 && (!ok || g.Deviation() > stall.Deviation()) {
+			stall, ok = g, true
+		}
+	}
+	return stall, ok
+}
+
+// printStartCadence prints the start cadence summary and every gap between
+// consecutive starts. Gaps left by competitors who did not start are
+// labeled as expected.
+func printStartCadence(w io.Writer, competitors map[Bib]*Competitor, delta time.Duration) {
+	gaps := startGaps(competitors)
+	if len(gaps) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nStart cadence:")
+	summary := fmt.Sprintf("%d starts, nominal interval %s", len(gaps)+1, formatDuration(delta))
+	if stall, ok := longestStall(gaps); ok {
+		summary += fmt.Sprintf(", longest stall %s after competitor(%s)", formatDuration(stall.Deviation()), stall.From)
+	}
+	fmt.Fprintln(w, summary)
+	for _, g := range gaps {
+		line := fmt.Sprintf("competitor(%s) -> competitor(%s): %s (%s)", g.From, g.To, formatDuration(g.Gap), signedDuration(g.Deviation()))
+		if len(g.DNS) > 0 {
+			bibs := make([]string, len(g.DNS))
+			for i, bib := range g.DNS {
+				bibs[i] = bib.String()
+			}
+			line += ", expected: did not start " + strings.Join(bibs, ", ")
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+func signedDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + formatDuration(-d)
+	}
+	return "+" + formatDuration(d)
+}
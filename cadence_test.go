@@ -0,0 +1,51 @@
+package biathlon
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartCadence(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:31:50.285] 1 2",
+		"[09:31:51.285] 1 3",
+		"[09:31:52.285] 1 4",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[09:55:01.000] 2 2 10:00:30.000",
+		"[09:55:02.000] 2 3 10:01:00.000",
+		"[09:55:03.000] 2 4 10:01:30.000",
+		"[10:00:00.000] 4 1",
+		// Competitor 2 stalls the start by 20 seconds.
+		"[10:00:50.000] 4 2",
+		// Competitor 3 does not start: a 1 minute gap is expected.
+		"[10:01:30.000] 4 4",
+	)
+	r.delta = 30 * time.Second
+	p := newProcessor(r, nil, io.Discard)
+	require.NoError(t, p.ProcessAll(r.events))
+
+	gaps := startGaps(p.Competitors())
+	require.Len(t, gaps, 2)
+	require.Equal(t, 50*time.Second, gaps[0].Gap)
+	require.Equal(t, 20*time.Second, gaps[0].Deviation())
+	require.Empty(t, gaps[0].DNS)
+	require.Equal(t, 40*time.Second, gaps[1].Gap)
+	require.Equal(t, time.Minute, gaps[1].Nominal)
+	require.Equal(t, []Bib{{Number: 3}}, gaps[1].DNS)
+
+	stall, ok := longestStall(gaps)
+	require.True(t, ok)
+	require.Equal(t, Bib{Number: 2}, stall.To)
+
+	var out bytes.Buffer
+	printStartCadence(&out, p.Competitors(), r.delta)
+	require.Equal(t, "\nStart cadence:\n"+
+		"3 starts, nominal interval 00:00:30.000, longest stall 00:00:20.000 after competitor(1)\n"+
+		"competitor(1) -> competitor(2): 00:00:50.000 (+00:00:20.000)\n"+
+		"competitor(2) -> competitor(4): 00:00:40.000 (-00:00:20.000), expected: did not start 3\n", out.String())
+}
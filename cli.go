@@ -0,0 +1,450 @@
+package biathlon
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// defaultCommand runs when the first argument is not a subcommand name.
+const defaultCommand = "process"
+
+// command is a subcommand of the CLI. setup registers the command flags on
+// fs and returns the function running the command once they are parsed,
+// which records its outcome in s; it is also used to generate the help
+// output.
+type command struct {
+	name    string
+	summary string
+	setup   func(fs *flag.FlagSet, stdout io.Writer, s *exitSummary) func() int
+}
+
+var commands = map[string]command{
+	"process": {
+		name:    "process",
+		summary: "process the events and print the commentary and the report",
+		setup:   setupProcess,
+	},
+	"combine": {
+		name:    "combine",
+		summary: "rank the athletes of two races by their weighted percent back from the winners",
+		setup:   setupCombine,
+	},
+	"import-program": {
+		name:    "import-program",
+		summary: "build the race config, the registrations and the draw from a daily program",
+		setup:   setupImportProgram,
+	},
+}
+
+// raceOptions are the flags describing how the race input is loaded.
+type raceOptions struct {
+	configPath   string
+	configFormat string
+	eventsPath   string
+	decisions    string
+	incidents    string
+	reconstruct  bool
+	strictConfig bool
+	lenient      bool
+	mirrored     bool
+	mirrorWindow time.Duration
+	// respace moves colliding draws apart, no further than respaceMargin
+	// slots past the last drawn one.
+	respace       bool
+	respaceMargin int
+}
+
+func (o *raceOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.configPath, "config", "config/config.json", "read the race config from this JSON or YAML file")
+	fs.StringVar(&o.configFormat, "config-format", "", "format of the config: json or yaml, detected from the extension when empty")
+	fs.StringVar(&o.eventsPath, "events", "events", "read the events from this file, or from stdin for -")
+	fs.BoolVar(&o.strictConfig, "strict-config", false, "reject configs with unknown fields instead of warning about them")
+	fs.BoolVar(&o.lenient, "lenient", false, "skip malformed event lines, summarizing them, instead of failing on the first one")
+	fs.StringVar(&o.decisions, "decisions", "", "apply the jury decisions from this JSON file")
+	fs.BoolVar(&o.reconstruct, "reconstruct", false, "synthesize a single lap end the lap mat missed from the surrounding checkpoints")
+	fs.BoolVar(&o.respace, "respace", false, "move the later registered of two competitors drawn for the same start slot to the next free slot")
+	fs.IntVar(&o.respaceMargin, "respace-margin", 0, "how many slots past the last drawn slot -respace may use")
+	fs.StringVar(&o.incidents, "incidents", "", "attach the course marshals' incidents from this log file")
+	fs.BoolVar(&o.mirrored, "mirrored", false, "the events file is written by two mirrored timing systems: drop the duplicates")
+	fs.DurationVar(&o.mirrorWindow, "mirror-window", 250*time.Millisecond, "maximum time between the two records of a mirrored event")
+}
+
+// reportOptions are the flags controlling the report formatting.
+type reportOptions struct {
+	locale    string
+	format    string
+	sparkline string
+	noUnicode bool
+}
+
+func (o *reportOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.locale, "locale", "en", "number and duration formatting of the report: en or ru")
+	fs.StringVar(&o.format, "format", "text", "format of the report: text, or json, markdown, html or accessible (one fact per line, for screen readers) for the final results only")
+	fs.StringVar(&o.sparkline, "sparkline", SparklineCompetitor, "scale the lap sparkline of a finisher by its own laps (competitor), all finishers' laps (field), or draw none (off)")
+	fs.BoolVar(&o.noUnicode, "no-unicode", false, "draw the lap sparklines with ASCII characters")
+}
+
+// outputOptions are the flags choosing where the report goes.
+type outputOptions struct {
+	dest string
+	sink sinkOptions
+}
+
+func (o *outputOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.dest, "out", "-", "write the report to this file or http(s) URL (uploaded with PUT), - for stdout")
+	fs.StringVar(&o.dest, "o", "-", "shorthand for -out")
+	fs.StringVar(&o.sink.contentType, "out-content-type", "text/plain; charset=utf-8", "content type of the report uploaded to an -out URL")
+	fs.StringVar(&o.sink.authEnv, "out-auth-env", "", "environment variable holding the Authorization header for -out URLs")
+	fs.IntVar(&o.sink.retries, "out-retries", 3, "how many times a failed upload to an -out URL is retried")
+	fs.DurationVar(&o.sink.backoff, "out-backoff", time.Second, "wait before the first upload retry, doubled for every next one")
+	fs.DurationVar(&o.sink.timeout, "out-timeout", 30*time.Second, "give up an upload attempt to an -out URL after this long")
+}
+
+// processOptions are the flags of the process command.
+type processOptions struct {
+	race          raceOptions
+	report        reportOptions
+	output        outputOptions
+	verbose       bool
+	quiet         bool
+	logLevel      string
+	logJSON       bool
+	dryRun        bool
+	feedPath      string
+	feedRotate    int64
+	manifest      string
+	payoutsCSV    string
+	whatIf        bool
+	version       bool
+	enforce       bool
+	orphans       bool
+	strictTargets bool
+	// bulletinAt are the clock times of the intermediate bulletins, written
+	// to numbered files in bulletinDir.
+	bulletinAt  clockTimes
+	bulletinDir string
+	// missOverhead is the range time a miss is estimated to cost in the
+	// what-if results.
+	missOverhead time.Duration
+}
+
+func setupProcess(fs *flag.FlagSet, stdout io.Writer, s *exitSummary) func() int {
+	var o processOptions
+	o.race.register(fs)
+	o.report.register(fs)
+	o.output.register(fs)
+	fs.BoolVar(&o.version, "version", false, "print the version and build details and exit")
+	fs.BoolVar(&o.verbose, "verbose", false, "print the effective config before processing, and the miss heat map, start cadence, shooting statistics and fun facts after")
+	fs.BoolVar(&o.quiet, "quiet", false, "leave the narration of the events out of the commentary, keeping the warnings and the report")
+	fs.StringVar(&o.logLevel, "log-level", "info", "lowest level of the commentary written: info for the narration, warn for the warnings and alerts, error for the malformed events")
+	fs.BoolVar(&o.logJSON, "log-json", false, "write the commentary as one JSON object per line with time, level, eventID, competitorID and message")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "validate the config and events, print the warnings and exit")
+	fs.StringVar(&o.feedPath, "checkpoint-feed", "", "write checkpoint crossings as CSV to this file while processing")
+	fs.Int64Var(&o.feedRotate, "checkpoint-feed-rotate", 0, "compress the checkpoint feed into gzip segments every this many bytes (0 disables)")
+	fs.BoolVar(&o.whatIf, "whatif", false, "add the hypothetical results with clean shooting to the report")
+	fs.DurationVar(&o.missOverhead, "whatif-miss-overhead", 0, "range time a miss is estimated to cost, taken off in the -whatif results")
+	fs.Var(&o.bulletinAt, "bulletin-at", "write intermediate bulletins as of these comma-separated clock times, e.g. 11:00,11:30")
+	fs.StringVar(&o.bulletinDir, "bulletin-dir", ".", "directory the -bulletin-at files bulletin-NN.txt are written to")
+	fs.BoolVar(&o.enforce, "enforce-entry-rules", false, "stop with an error on registrations beyond maxCompetitors or outside bibRange")
+	fs.BoolVar(&o.strictTargets, "strict-targets", false, "stop with an error on hits without a target number from 1 to targetsPerLine instead of ignoring them")
+	fs.BoolVar(&o.orphans, "register-orphans", false, "create a competitor, with a warning, for events of one who never registered instead of skipping them")
+	fs.StringVar(&o.manifest, "manifest", "", "write a reproducibility manifest as JSON to this file and summarize it after the report")
+	fs.StringVar(&o.payoutsCSV, "payouts-csv", "", "write the payout sheet of the config payouts as CSV to this file")
+	return func() int { return runProcess(o, fs, stdout, s) }
+}
+
+// Run dispatches args to a subcommand and returns the exit code. Arguments
+// not starting with a subcommand name go to the default command, so the
+// plain flag invocations keep working. An interrupt or termination signal
+// ends the run early.
+func Run(args []string, stdout, stderr io.Writer) int {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	return run(args, stdout, stderr, interrupt)
+}
+
+// run is Run with the signals ending the run read from interrupt. However
+// the run ends, its exit summary is the last line written to stderr.
+func run(args []string, stdout, stderr io.Writer, interrupt <-chan os.Signal) (code int) {
+	var s exitSummary
+	errOut := &lastWriter{w: stderr}
+	defer func() { errOut.seal(&s, code) }()
+
+	done := make(chan int, 1)
+	go func() { done <- dispatch(args, stdout, errOut, &s) }()
+	select {
+	case code = <-done:
+	case sig := <-interrupt:
+		s.addError(fmt.Sprintf("interrupted by %s", sig))
+		code = 1
+		if n, ok := sig.(syscall.Signal); ok {
+			code = 128 + int(n)
+		}
+	}
+	return code
+}
+
+// dispatch runs the subcommand named in args, recording its outcome in s.
+func dispatch(args []string, stdout, stderr io.Writer, s *exitSummary) int {
+	name := defaultCommand
+	if len(args) > 0 && !isFlag(args[0]) {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		return help(args, stdout, stderr)
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		s.addError(fmt.Sprintf("unknown command %q", name))
+		fmt.Fprintf(stderr, "unknown command %q\n", name)
+		usage(stderr)
+		return 2
+	}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	runCmd := cmd.setup(fs, stdout, s)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		s.addError(err.Error())
+		return 2
+	}
+	if fs.NArg() > 0 {
+		s.addError(fmt.Sprintf("%s: unexpected arguments %q", cmd.name, fs.Args()))
+		fmt.Fprintf(stderr, "%s: unexpected arguments %q\n", cmd.name, fs.Args())
+		return 2
+	}
+	return runCmd()
+}
+
+func isFlag(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-'
+}
+
+// help prints the usage of the command named in args, or the command list.
+func help(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stdout)
+		return 0
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	cmd.setup(fs, io.Discard, &exitSummary{})
+	fmt.Fprintf(stdout, "Usage: biathlon %s [flags]\n\n%s\n\nFlags:\n", cmd.name, cmd.summary)
+	fs.SetOutput(stdout)
+	fs.PrintDefaults()
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: biathlon [command] [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(w, "\nWithout a command, %s is run. Use \"biathlon help <command>\" for its flags.\n", defaultCommand)
+}
+
+// runProcess is the process command: it loads the race, applies the events
+// and prints the report, recording the outcome in s.
+func runProcess(o processOptions, fs *flag.FlagSet, w io.Writer, s *exitSummary) int {
+	if o.version {
+		fmt.Fprintln(w, "biathlon", currentBuild())
+		return 0
+	}
+	style, err := o.report.style()
+	if err != nil {
+		return s.fail(w, err)
+	}
+	format, err := lookupRenderer(o.report.format)
+	if err != nil {
+		return s.fail(w, err)
+	}
+	logLevel, err := parseLogLevel(o.logLevel)
+	if err != nil {
+		return s.fail(w, err)
+	}
+	if o.quiet {
+		logLevel = max(logLevel, LevelWarn)
+	}
+	if o.dryRun {
+		return dryRun(o.race, w)
+	}
+
+	if o.race.strictConfig {
+		if _, err := loadConfigStrict(o.race.configPath, o.race.configFormat); err != nil {
+			return s.fail(w, "config error:", err)
+		}
+	}
+	r, err := loadRace(o.race.configPath, o.race.configFormat, o.race.eventsPath, o.race.lenient)
+	if err != nil {
+		return s.fail(w, err)
+	}
+	if r.decisions, err = loadDecisions(o.race.decisions); err != nil {
+		return s.fail(w, "Decisions error:", err)
+	}
+	r.decisions = r.decisions.onRaceDay(r)
+	incidents, err := loadIncidents(o.race.incidents)
+	if err != nil {
+		return s.fail(w, "Incidents error:", err)
+	}
+	for i := range incidents {
+		incidents[i].Time = r.onRaceDay(incidents[i].Time)
+	}
+	warned := 0
+	for _, warning := range r.cfg.warnings() {
+		fmt.Fprintln(w, "Config warning:", warning)
+		warned++
+	}
+	if len(r.skipped) > 0 {
+		fmt.Fprintln(w, skippedLine(r.skipped))
+		warned += len(r.skipped)
+	}
+	if o.race.mirrored {
+		var stats mirrorStats
+		r.events, stats = dedupeMirrored(r.events, o.race.mirrorWindow)
+		printMirrorStats(w, stats)
+	}
+	if o.race.reconstruct {
+		r.events, r.reconstructions = reconstructLaps(r.events, r.cfg)
+	}
+	// The lap times are checked on the events processed, without the
+	// mirrored duplicates a lap end would otherwise split a lap in two with.
+	if line, ok := lapLenWarning(r.events, r.cfg.LapLen); ok {
+		fmt.Fprintln(w, line)
+		warned++
+	}
+	s.update(func(s *exitSummary) {
+		s.RaceID = r.cfg.RaceID
+		s.Warnings = warned
+	})
+	if o.race.respace {
+		r.respaces = respaceDraws(r.events, r.baseStart, r.delta, o.race.respaceMargin)
+		printRespaces(w, r.respaces)
+	}
+	if o.verbose {
+		printConfig(w, r.cfg)
+	}
+
+	var feed *checkpointFeed
+	if o.feedPath != "" {
+		f, err := openFeedFile(o.feedPath, o.feedRotate)
+		if err != nil {
+			return s.fail(w, "Checkpoint feed error:", err)
+		}
+		defer func(f io.Closer) {
+			if err := f.Close(); err != nil {
+				fmt.Fprintln(w, "Checkpoint feed error:", err)
+			}
+		}(f)
+		if feed, err = newCheckpointFeed(f); err != nil {
+			return s.fail(w, "Checkpoint feed error:", err)
+		}
+	}
+
+	var log Logger = textLogger{w: w, level: logLevel}
+	if o.logJSON {
+		log = newJSONLogger(w, logLevel)
+	}
+	p := newLoggingProcessor(r, feed, log)
+	p.enforceEntryRules = o.enforce
+	p.registerOrphans = o.orphans
+	p.strictTargets = o.strictTargets
+	bulletin := func(n int, asOf time.Time) error {
+		if err := writeBulletin(o.bulletinDir, n, asOf, p, r, style); err != nil {
+			return fmt.Errorf("bulletin error: %w", err)
+		}
+		return nil
+	}
+	bulletinAt := make([]time.Time, len(o.bulletinAt))
+	for i, t := range o.bulletinAt {
+		bulletinAt[i] = r.onRaceDay(t)
+	}
+	sort.Slice(bulletinAt, func(i, j int) bool { return bulletinAt[i].Before(bulletinAt[j]) })
+	err = p.ProcessWithBulletins(r.events, bulletinAt, bulletin)
+	warned += p.warnings
+	s.update(func(s *exitSummary) { s.Warnings = warned })
+	if err != nil {
+		return s.fail(w, err)
+	}
+	for _, warning := range attachIncidents(incidents, p.Competitors()) {
+		fmt.Fprintln(w, "Incidents warning:", warning)
+		warned++
+	}
+	finishers := 0
+	for _, res := range results(p.Competitors(), r.cfg, r.profile) {
+		if res.Status == StatusFinished {
+			finishers++
+		}
+	}
+	s.update(func(s *exitSummary) {
+		s.Warnings = warned
+		s.Finishers = finishers
+	})
+	var m Manifest
+	if o.manifest != "" {
+		if m, err = newManifest(o.race, r, fs, time.Now()); err != nil {
+			return s.fail(w, "Manifest error:", err)
+		}
+		if err := writeManifest(o.manifest, m); err != nil {
+			return s.fail(w, "Manifest error:", err)
+		}
+	}
+	if o.payoutsCSV != "" {
+		if err := writePayoutsCSV(o.payoutsCSV, payouts(results(p.Competitors(), r.cfg, r.profile), r.cfg.Payouts)); err != nil {
+			return s.fail(w, "Payouts error:", err)
+		}
+	}
+
+	out, err := openSink(o.output.dest, w, o.output.sink)
+	if err != nil {
+		return s.fail(w, "Output error:", err)
+	}
+	if o.report.format == "text" {
+		printTextReport(out, o, p, r, style, m)
+	} else if err := format.render(out, results(p.Competitors(), r.cfg, r.profile), style); err != nil {
+		return s.fail(w, "Output error:", err)
+	}
+	if err := out.Close(); err != nil {
+		return s.fail(w, "Output error:", err)
+	}
+	return 0
+}
+
+// printTextReport writes the text report with the sections the options ask
+// for. m is only used with -manifest.
+func printTextReport(out io.Writer, o processOptions, p *Processor, r race, style reportStyle, m Manifest) {
+	printReport(out, p, r, style)
+	if o.whatIf {
+		printWhatIf(out, whatIfClean(p.Competitors(), r.cfg, o.missOverhead), o.missOverhead)
+	}
+	if o.verbose {
+		printMissHeatMap(out, missHeatMap(p.Competitors(), r.cfg.TargetsPerLine))
+		printStartCadence(out, p.Competitors(), r.delta)
+		printPressure(out, p.Competitors(), r.cfg.TargetsPerLine)
+		printPositionAccuracy(out, p.Competitors(), r.cfg.TargetsPerLine)
+		printFunFacts(out, p.Competitors())
+	}
+	if o.manifest != "" {
+		printManifestFooter(out, m)
+	}
+}
@@ -0,0 +1,110 @@
+package biathlon
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDispatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		code     int
+		stdout   string
+		stderr   string
+		noStdout bool
+	}{
+		{name: "default command", args: nil, code: 0, stdout: "Final results:"},
+		{name: "default command with flags", args: []string{"-dry-run"}, code: 0, stdout: "Validation passed"},
+		{name: "explicit process", args: []string{"process", "-dry-run"}, code: 0, stdout: "Validation passed"},
+		{name: "bad locale", args: []string{"process", "-locale", "fr"}, code: 1, stdout: "unknown locale"},
+		{name: "bad sparkline", args: []string{"process", "-sparkline", "team"}, code: 1, stdout: `unknown sparkline "team"`},
+		{name: "unknown command", args: []string{"simulate"}, code: 2, stderr: `unknown command "simulate"`, noStdout: true},
+		{name: "unknown flag", args: []string{"-nope"}, code: 2, stderr: "flag provided but not defined: -nope", noStdout: true},
+		{name: "stray argument", args: []string{"process", "extra"}, code: 2, stderr: `unexpected arguments ["extra"]`, noStdout: true},
+		{name: "flag help", args: []string{"process", "-h"}, code: 0, stderr: "-checkpoint-feed", noStdout: true},
+		{name: "help", args: []string{"help"}, code: 0, stdout: "  process    process the events"},
+		{name: "help command", args: []string{"help", "process"}, code: 0, stdout: "Usage: biathlon process [flags]"},
+		{name: "help unknown command", args: []string{"help", "draw"}, code: 2, stderr: `unknown command "draw"`, noStdout: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			require.Equal(t, test.code, Run(test.args, &stdout, &stderr))
+			require.Contains(t, stdout.String(), test.stdout)
+			require.Contains(t, stderr.String(), test.stderr)
+			if test.noStdout {
+				require.Empty(t, stdout.String())
+			}
+		})
+	}
+}
+
+func TestHelpListsEveryFlag(t *testing.T) {
+	var stdout bytes.Buffer
+	require.Equal(t, 0, Run([]string{"help", "process"}, &stdout, &bytes.Buffer{}))
+	for _, name := range []string{"-verbose", "-quiet", "-log-level", "-log-json", "-dry-run", "-decisions", "-checkpoint-feed", "-mirrored", "-mirror-window", "-locale", "-manifest", "-incidents", "-out", "-o", "-out-content-type", "-out-auth-env", "-out-retries", "-out-backoff", "-out-timeout", "-whatif", "-whatif-miss-overhead", "-strict-config", "-version", "-bulletin-at", "-bulletin-dir", "-enforce-entry-rules", "-reconstruct", "-checkpoint-feed-rotate", "-config", "-config-format", "-events", "-format", "-sparkline", "-no-unicode", "-register-orphans", "-payouts-csv", "-lenient", "-strict-targets", "-respace", "-respace-margin"} {
+		require.Contains(t, stdout.String(), name)
+	}
+}
+
+func TestRunPaths(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "race.json")
+	eventsPath := filepath.Join(dir, "race.log")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"laps": 1, "lapLen": 3000, "penaltyLen": 150, "firingLines": 1,
+		"targetsPerLine": 5, "start": "10:00:00.000", "startDelta": "00:00:30", "startLineTimeout": "00:02:00"}`), 0o644))
+	invalidPath := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalidPath, []byte(`{"laps": 0, "lapLen": -100, "penaltyLen": 150, "start": "10 am", "startDelta": "00:00:30"}`), 0o644))
+	require.NoError(t, os.WriteFile(eventsPath, []byte("[09:30:00.000] 1 42\n"+
+		"[09:31:00.000] 2 42 10:00:00.000\n"+
+		"[09:59:00.000] 3 42\n"+
+		"[10:00:01.000] 4 42\n"+
+		"[10:10:00.000] 10 42\n"), 0o644))
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+	}{
+		{name: "both paths", args: []string{"-config", configPath, "-events", eventsPath}, code: 0, stdout: "Competitor 42: laps count 1"},
+		{name: "missing config", args: []string{"-config", filepath.Join(dir, "nope.json"), "-events", eventsPath}, code: 1, stdout: "config error: config file not found: open " + filepath.Join(dir, "nope.json")},
+		{name: "invalid config", args: []string{"-config", invalidPath, "-events", eventsPath}, code: 1, stdout: invalidPath + `: invalid config: laps must be positive, got 0; lapLen must be positive, got -100; start must be a time like 10:00:00.000, got "10 am"`},
+		{name: "missing events", args: []string{"-config", configPath, "-events", filepath.Join(dir, "nope.log")}, code: 1, stdout: "events error: open " + filepath.Join(dir, "nope.log")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			require.Equal(t, test.code, Run(test.args, &stdout, &bytes.Buffer{}))
+			require.Contains(t, stdout.String(), test.stdout)
+		})
+	}
+}
+
+func TestRunLenient(t *testing.T) {
+	dir := t.TempDir()
+	eventsPath := filepath.Join(dir, "race.log")
+	require.NoError(t, os.WriteFile(eventsPath, []byte("[09:31:49.285] 1 1\n"+
+		"[09:55:00.000] 2 1\n"+
+		"[09:56:00.000] 2 1 10:00:00.000\n"+
+		"[10:00:01.744] 4\n"+
+		"[10:00:01.744] 4 1\n"+
+		"[10:12:35.380] 10 x\n"+
+		"[10:12:35.380] 10 1\n"+
+		"[10:25:26.047] 10 1\n"), 0o644))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 1, Run([]string{"-events", eventsPath}, &stdout, &bytes.Buffer{}))
+	require.Contains(t, stdout.String(), "events error: "+eventsPath+`:4: "[10:00:01.744] 4": invalid event line`)
+	require.NotContains(t, stdout.String(), "Final results:")
+
+	stdout.Reset()
+	require.Equal(t, 0, Run([]string{"-lenient", "-events", eventsPath}, &stdout, &bytes.Buffer{}))
+	require.Contains(t, stdout.String(), "2 lines skipped: 4, 6\n")
+	require.Contains(t, stdout.String(), "Competitor 1: laps count 2")
+}
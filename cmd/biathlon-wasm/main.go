@@ -0,0 +1,13 @@
+//go:build js && wasm
+
+// Command biathlon-wasm runs in the browser and exposes the race processing
+// as the global function processRace(configJSON, eventsText).
+package main
+
+import "BiathlonCompetitions/wasm"
+
+func main() {
+	wasm.Register()
+	// The function is called from JavaScript for as long as the page lives.
+	select {}
+}
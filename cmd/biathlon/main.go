@@ -0,0 +1,12 @@
+// Command biathlon processes the events of a biathlon race.
+package main
+
+import (
+	"os"
+
+	biathlon "BiathlonCompetitions"
+)
+
+func main() {
+	os.Exit(biathlon.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
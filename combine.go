@@ -0,0 +1,196 @@
+package biathlon
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"BiathlonCompetitions/parser"
+)
+
+// CombinedResult is an athlete's place in a combined classification of two
+// races, ranked by the weighted sum of the percent back from each race's
+// winner. PercentBack is nil for a race the athlete didn't finish, which
+// then scores the missing penalty.
+type CombinedResult struct {
+	Place       int
+	Competitor  string
+	PercentBack [2]*float64
+	Score       float64
+}
+
+// combineOptions are the flags of the combine command.
+type combineOptions struct {
+	races          [2]string
+	weights        [2]float64
+	missingPenalty float64
+	format         string
+}
+
+func setupCombine(fs *flag.FlagSet, stdout io.Writer, s *exitSummary) func() int {
+	var o combineOptions
+	fs.StringVar(&o.races[0], "race1", "", "read the results of the first race from this JSON report")
+	fs.StringVar(&o.races[1], "race2", "", "read the results of the second race from this JSON report")
+	fs.Float64Var(&o.weights[0], "weight1", 1, "weight of the percent back in the first race")
+	fs.Float64Var(&o.weights[1], "weight2", 1, "weight of the percent back in the second race")
+	fs.Float64Var(&o.missingPenalty, "missing-penalty", -1, "percent back scored for a race an athlete didn't finish; negative leaves them out")
+	fs.StringVar(&o.format, "format", "text", "format of the combined classification: text or json")
+	return func() int { return runCombine(o, stdout, s) }
+}
+
+// runCombine is the combine command: it ranks the athletes of two races by
+// their weighted percent back.
+func runCombine(o combineOptions, w io.Writer, s *exitSummary) int {
+	if o.races[0] == "" || o.races[1] == "" {
+		return s.fail(w, "Combine error: -race1 and -race2 are required")
+	}
+	if o.format != "text" && o.format != "json" {
+		return s.fail(w, fmt.Sprintf("Combine error: unknown format %q, expected one of json, text", o.format))
+	}
+	var totals [2]map[string]int64
+	for i, path := range o.races {
+		var err error
+		if totals[i], err = readFinishTimes(path); err != nil {
+			return s.fail(w, "Combine error:", err)
+		}
+	}
+	combined, excluded := combine(totals, o.weights, o.missingPenalty)
+	if o.format == "json" {
+		if err := renderCombinedJSON(w, combined); err != nil {
+			return s.fail(w, "Output error:", err)
+		}
+	} else {
+		printCombined(w, combined, excluded)
+	}
+	s.update(func(s *exitSummary) { s.Finishers = len(combined) })
+	return 0
+}
+
+// readFinishTimes reads a JSON report and returns the total time, in
+// milliseconds, of every finisher by competitor.
+func readFinishTimes(path string) (map[string]int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results []jsonResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	totals := map[string]int64{}
+	for _, r := range results {
+		if r.TotalMs != nil && *r.TotalMs > 0 {
+			totals[r.Competitor] = *r.TotalMs
+		}
+	}
+	if len(totals) == 0 {
+		return nil, fmt.Errorf("%s: no finishers", path)
+	}
+	return totals, nil
+}
+
+// combine ranks every athlete who finished either race by the weighted sum
+// of their percent back from each race's winner. An athlete missing a race
+// scores missingPenalty for it, or is excluded when it is negative; the
+// excluded athletes are returned in order.
+func combine(totals [2]map[string]int64, weights [2]float64, missingPenalty float64) (combined []CombinedResult, excluded []string) {
+	var winners [2]int64
+	athletes := map[string]bool{}
+	for i, race := range totals {
+		for competitor, total := range race {
+			if winners[i] == 0 || total < winners[i] {
+				winners[i] = total
+			}
+			athletes[competitor] = true
+		}
+	}
+	for competitor := range athletes {
+		r := CombinedResult{Competitor: competitor}
+		missing := false
+		for i, race := range totals {
+			total, ok := race[competitor]
+			if !ok {
+				missing = true
+				r.Score += weights[i] * missingPenalty
+				continue
+			}
+			pb := float64(total-winners[i]) * 100 / float64(winners[i])
+			r.PercentBack[i] = &pb
+			r.Score += weights[i] * pb
+		}
+		if missing && missingPenalty < 0 {
+			excluded = append(excluded, competitor)
+			continue
+		}
+		combined = append(combined, r)
+	}
+	sort.Slice(combined, func(i, j int) bool {
+		if combined[i].Score != combined[j].Score {
+			return combined[i].Score < combined[j].Score
+		}
+		return lessCompetitor(combined[i].Competitor, combined[j].Competitor)
+	})
+	for i := range combined {
+		if i > 0 && combined[i].Score == combined[i-1].Score {
+			combined[i].Place = combined[i-1].Place
+		} else {
+			combined[i].Place = i + 1
+		}
+	}
+	sort.Slice(excluded, func(i, j int) bool { return lessCompetitor(excluded[i], excluded[j]) })
+	return combined, excluded
+}
+
+// lessCompetitor orders the competitors of a JSON report by bib, falling
+// back to their text for ids that aren't bibs.
+func lessCompetitor(a, b string) bool {
+	ba, errA := parser.ParseBib(a)
+	bb, errB := parser.ParseBib(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return ba.Less(bb)
+}
+
+func formatPercentBack(pb *float64) string {
+	if pb == nil {
+		return "missing"
+	}
+	return fmt.Sprintf("%.2f%%", *pb)
+}
+
+// printCombined prints the combined classification and the athletes left
+// out of it.
+func printCombined(w io.Writer, combined []CombinedResult, excluded []string) {
+	fmt.Fprintln(w, "Combined results:")
+	for _, r := range combined {
+		fmt.Fprintf(w, "%d. competitor(%s) %.2f (race 1 %s, race 2 %s)\n",
+			r.Place, r.Competitor, r.Score, formatPercentBack(r.PercentBack[0]), formatPercentBack(r.PercentBack[1]))
+	}
+	if len(excluded) > 0 {
+		fmt.Fprintf(w, "Excluded for missing a race: %s\n", strings.Join(excluded, ", "))
+	}
+}
+
+type jsonCombinedResult struct {
+	Place       int        `json:"place"`
+	Competitor  string     `json:"competitor"`
+	PercentBack []*float64 `json:"percentBack"`
+	Score       float64    `json:"score"`
+}
+
+// renderCombinedJSON writes the combined classification as an indented
+// JSON array; the percent back of a missing race is null.
+func renderCombinedJSON(w io.Writer, combined []CombinedResult) error {
+	all := make([]jsonCombinedResult, len(combined))
+	for i, r := range combined {
+		all[i] = jsonCombinedResult{Place: r.Place, Competitor: r.Competitor, PercentBack: r.PercentBack[:], Score: r.Score}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(all)
+}
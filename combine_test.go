@@ -0,0 +1,107 @@
+package biathlon
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeResults(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestCombine(t *testing.T) {
+	t.Parallel()
+	biathlon := writeResults(t, "biathlon.json", `[
+		{"place": 1, "competitor": "1", "status": "Finished", "totalMs": 1000000},
+		{"place": 2, "competitor": "3", "status": "Finished", "totalMs": 1050000},
+		{"place": 3, "competitor": "2", "status": "Finished", "totalMs": 1100000}]`)
+	xc := writeResults(t, "xc.json", `[
+		{"place": 1, "competitor": "2", "status": "Finished", "totalMs": 2000000},
+		{"place": 2, "competitor": "4", "status": "Finished", "totalMs": 2100000},
+		{"place": 3, "competitor": "1", "status": "Finished", "totalMs": 2200000},
+		{"competitor": "5", "status": "NotStarted"}]`)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			// 1: 0% + 0.5 * 10% = 5, 2: 10% + 0.5 * 0% = 10.
+			name: "missing excluded",
+			args: []string{"-weight2", "0.5"},
+			expected: "Combined results:\n" +
+				"1. competitor(1) 5.00 (race 1 0.00%, race 2 10.00%)\n" +
+				"2. competitor(2) 10.00 (race 1 10.00%, race 2 0.00%)\n" +
+				"Excluded for missing a race: 3, 4\n",
+		},
+		{
+			// 3: 5% + 0.5 * 20% = 15, 4: 20% + 0.5 * 5% = 22.5.
+			name: "missing penalty",
+			args: []string{"-weight2", "0.5", "-missing-penalty", "20"},
+			expected: "Combined results:\n" +
+				"1. competitor(1) 5.00 (race 1 0.00%, race 2 10.00%)\n" +
+				"2. competitor(2) 10.00 (race 1 10.00%, race 2 0.00%)\n" +
+				"3. competitor(3) 15.00 (race 1 5.00%, race 2 missing)\n" +
+				"4. competitor(4) 22.50 (race 1 missing, race 2 5.00%)\n",
+		},
+		{
+			name: "equal weights tie",
+			args: nil,
+			expected: "Combined results:\n" +
+				"1. competitor(1) 10.00 (race 1 0.00%, race 2 10.00%)\n" +
+				"1. competitor(2) 10.00 (race 1 10.00%, race 2 0.00%)\n" +
+				"Excluded for missing a race: 3, 4\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var stdout bytes.Buffer
+			args := append([]string{"combine", "-race1", biathlon, "-race2", xc}, test.args...)
+			require.Equal(t, 0, Run(args, &stdout, &bytes.Buffer{}))
+			require.Equal(t, test.expected, stdout.String())
+		})
+	}
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, Run([]string{"combine", "-race1", biathlon, "-race2", xc, "-missing-penalty", "20", "-format", "json"}, &stdout, &bytes.Buffer{}))
+	var got []jsonCombinedResult
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &got))
+	require.Len(t, got, 4)
+	require.Equal(t, "3", got[2].Competitor)
+	require.Equal(t, 25.0, got[2].Score)
+	require.Equal(t, 5.0, *got[2].PercentBack[0])
+	require.Nil(t, got[2].PercentBack[1])
+}
+
+func TestCombineErrors(t *testing.T) {
+	t.Parallel()
+	results := writeResults(t, "results.json", `[{"place": 1, "competitor": "1", "status": "Finished", "totalMs": 1000}]`)
+	empty := writeResults(t, "empty.json", `[{"competitor": "1", "status": "NotStarted"}]`)
+	tests := []struct {
+		name string
+		args []string
+		out  string
+	}{
+		{name: "missing race", args: []string{"-race1", results}, out: "Combine error: -race1 and -race2 are required"},
+		{name: "no finishers", args: []string{"-race1", results, "-race2", empty}, out: "Combine error: " + empty + ": no finishers"},
+		{name: "bad format", args: []string{"-race1", results, "-race2", results, "-format", "xml"}, out: `unknown format "xml"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var stdout bytes.Buffer
+			require.Equal(t, 1, Run(append([]string{"combine"}, test.args...), &stdout, &bytes.Buffer{}))
+			require.Contains(t, stdout.String(), test.out)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package biathlon
+
+import "fmt"
+
+// CommentaryLimit bounds the commentary a Processor retains to the most
+// recent MaxLines records and MaxBytes bytes of their messages, and the
+// events it keeps for CompetitorCommentary to the most recent MaxEvents. A
+// limit of 0 leaves that bound off.
+type CommentaryLimit struct {
+	MaxLines  int
+	MaxBytes  int
+	MaxEvents int
+}
+
+// commentaryBuffer retains the most recent records logged to it within
+// limit, dropping the oldest ones when it runs over.
+type commentaryBuffer struct {
+	limit   CommentaryLimit
+	records []LogRecord
+	bytes   int
+	dropped int
+}
+
+func (b *commentaryBuffer) Log(r LogRecord) {
+	b.records = append(b.records, r)
+	b.bytes += len(r.Message)
+	b.trim()
+}
+
+// trim drops the oldest records until the buffer is within its limit.
+func (b *commentaryBuffer) trim() {
+	n := 0
+	for n < len(b.records) && b.over(len(b.records)-n) {
+		b.bytes -= len(b.records[n].Message)
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	b.dropped += n
+	// Copying keeps the dropped records from pinning the backing array.
+	b.records = append([]LogRecord(nil), b.records[n:]...)
+}
+
+// over reports whether lines records of b.bytes bytes run over the limit.
+func (b *commentaryBuffer) over(lines int) bool {
+	return (b.limit.MaxLines > 0 && lines > b.limit.MaxLines) || (b.limit.MaxBytes > 0 && b.bytes > b.limit.MaxBytes)
+}
+
+// teeLogger logs every record to each of its loggers.
+type teeLogger []Logger
+
+func (t teeLogger) Log(r LogRecord) {
+	for _, l := range t {
+		l.Log(r)
+	}
+}
+
+// competitorLogger keeps the records about one competitor.
+type competitorLogger struct {
+	bib     Bib
+	records []LogRecord
+}
+
+func (l *competitorLogger) Log(r LogRecord) {
+	if r.Competitor == l.bib {
+		l.records = append(l.records, r)
+	}
+}
+
+// LimitCommentary bounds the commentary p retains from now on, dropping the
+// oldest retained records that are already over the limit. The commentary is
+// retained without a bound until then.
+func (p *Processor) LimitCommentary(limit CommentaryLimit) {
+	p.retained.limit = limit
+	p.retained.trim()
+	p.trimApplied()
+}
+
+// keep adds e to the events kept for CompetitorCommentary.
+func (p *Processor) keep(e Event) {
+	p.applied = append(p.applied, e)
+	p.trimApplied()
+}
+
+// trimApplied drops the oldest kept events over the MaxEvents limit.
+// Reslicing is enough: the next append past the capacity copies only the
+// kept events, releasing the dropped ones.
+func (p *Processor) trimApplied() {
+	limit := p.retained.limit.MaxEvents
+	if n := len(p.applied) - limit; limit > 0 && n > 0 {
+		p.applied = p.applied[n:]
+		p.droppedEvents += n
+	}
+}
+
+// Commentary returns the retained commentary, oldest first, and whether
+// older records were dropped to keep it within the limit.
+func (p *Processor) Commentary() ([]LogRecord, bool) {
+	return p.retained.records, p.retained.dropped > 0
+}
+
+// CompetitorCommentary renders the commentary about competitor b again by
+// replaying the events applied so far through a fresh processor, so it is
+// complete however much of the retained commentary was dropped. It fails
+// with ErrReplayDropped once events were dropped over MaxEvents.
+func (p *Processor) CompetitorCommentary(b Bib) ([]LogRecord, error) {
+	if p.droppedEvents > 0 {
+		return nil, fmt.Errorf("%w: the %d oldest events are over the limit of %d", ErrReplayDropped, p.droppedEvents, p.retained.limit.MaxEvents)
+	}
+	log := &competitorLogger{bib: b}
+	q := newLoggingProcessor(p.source, nil, log)
+	q.enforceEntryRules = p.enforceEntryRules
+	q.registerOrphans = p.registerOrphans
+	q.strictTargets = p.strictTargets
+	q.knownGaps = p.knownGaps
+	// The course window may come from events ahead of the replayed ones,
+	// as ProcessWithBulletins finds it before processing.
+	q.courseOpened, q.courseClosed = p.courseOpened, p.courseClosed
+	for id, h := range p.handlers {
+		q.handlers[id] = h
+	}
+	for _, e := range p.applied {
+		if err := q.Process(e); err != nil {
+			return nil, err
+		}
+	}
+	if p.finished {
+		q.finish()
+	}
+	return log.records, nil
+}
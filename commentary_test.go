@@ -0,0 +1,118 @@
+package biathlon
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentaryLimit(t *testing.T) {
+	events := []string{"[09:30:00.000] 1 1", "[09:30:01.000] 1 2", "[09:45:00.000] 2 1 10:00:00.000",
+		"[09:45:01.000] 2 2 10:01:30.000", "[10:00:00.500] 4 1", "[10:01:30.500] 4 2"}
+	var full recordLogger
+	r := newTestRace(t, events...)
+	require.NoError(t, newLoggingProcessor(r, nil, &full).ProcessAll(r.events))
+	require.Len(t, full.records, 6)
+
+	tests := []struct {
+		name     string
+		limit    CommentaryLimit
+		expected []LogRecord
+		overflow bool
+	}{
+		{name: "unlimited", expected: full.records},
+		{name: "lines", limit: CommentaryLimit{MaxLines: 2}, expected: full.records[4:], overflow: true},
+		// The start messages are 29 bytes each, the draws 80.
+		{name: "bytes", limit: CommentaryLimit{MaxBytes: 150}, expected: full.records[3:], overflow: true},
+		{name: "both", limit: CommentaryLimit{MaxLines: 4, MaxBytes: 60}, expected: full.records[4:], overflow: true},
+		{name: "within", limit: CommentaryLimit{MaxLines: 6, MaxBytes: 1000}, expected: full.records},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := newTestRace(t, events...)
+			var out bytes.Buffer
+			p := newProcessor(r, nil, &out)
+			p.LimitCommentary(test.limit)
+			require.NoError(t, p.ProcessAll(r.events))
+			retained, overflow := p.Commentary()
+			require.Equal(t, test.expected, retained)
+			require.Equal(t, test.overflow, overflow)
+			require.Len(t, bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n")), 6, "the bound doesn't cut the logged commentary")
+		})
+	}
+}
+
+func TestLimitCommentaryTrims(t *testing.T) {
+	r := newTestRace(t, "[09:30:00.000] 1 1", "[09:30:01.000] 1 2", "[09:30:02.000] 1 3")
+	p := newProcessor(r, nil, &bytes.Buffer{})
+	require.NoError(t, p.ProcessAll(r.events))
+	retained, overflow := p.Commentary()
+	require.Len(t, retained, 3)
+	require.False(t, overflow)
+
+	p.LimitCommentary(CommentaryLimit{MaxLines: 1})
+	retained, overflow = p.Commentary()
+	require.Equal(t, "The competitor(3) registered", retained[0].Message)
+	require.Len(t, retained, 1)
+	require.True(t, overflow)
+}
+
+// TestCompetitorCommentary re-renders the commentary about every competitor
+// of the sample race from a processor retaining a single line, and requires
+// it to match the lines about the competitor in the full commentary.
+func TestCompetitorCommentary(t *testing.T) {
+	r, err := loadRace("config/config.json", "", "events", false)
+	require.NoError(t, err)
+	var full recordLogger
+	require.NoError(t, newLoggingProcessor(r, nil, &full).ProcessAll(r.events))
+
+	p := newProcessor(r, nil, &bytes.Buffer{})
+	p.LimitCommentary(CommentaryLimit{MaxLines: 1})
+	require.NoError(t, p.ProcessAll(r.events))
+	for bib := range p.Competitors() {
+		var expected []LogRecord
+		for _, record := range full.records {
+			if record.Competitor == bib {
+				expected = append(expected, record)
+			}
+		}
+		got, err := p.CompetitorCommentary(bib)
+		require.NoError(t, err)
+		require.NotEmpty(t, got)
+		require.Equal(t, expected, got, bib.String())
+	}
+
+	got, err := p.CompetitorCommentary(Bib{Number: 99})
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	// The course opens after an event of competitor 1, which the replay
+	// must ignore as the run did, although it only replays up to then.
+	r = newTestRace(t, "[09:31:49.285] 1 1", "[09:40:00.000] 5 1 1", "[09:50:00.000] 13 0", "[09:55:00.000] 2 1 10:00:00.000")
+	full = recordLogger{}
+	require.NoError(t, newLoggingProcessor(r, nil, &full).ProcessAll(r.events))
+	p = newProcessor(r, nil, &bytes.Buffer{})
+	require.NoError(t, p.ProcessAll(r.events))
+	got, err = p.CompetitorCommentary(Bib{Number: 1})
+	require.NoError(t, err)
+	require.Equal(t, []LogRecord{full.records[0], full.records[1], full.records[3]}, got)
+	require.Contains(t, got[1].Message, "outside_course_window")
+}
+
+func TestCompetitorCommentaryDropped(t *testing.T) {
+	r := newTestRace(t, "[09:30:00.000] 1 1", "[09:30:01.000] 1 2", "[09:30:02.000] 1 3")
+	p := newProcessor(r, nil, &bytes.Buffer{})
+	p.LimitCommentary(CommentaryLimit{MaxEvents: 2})
+	require.NoError(t, p.Process(r.events[0]))
+	require.NoError(t, p.Process(r.events[1]))
+	got, err := p.CompetitorCommentary(Bib{Number: 1})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	require.NoError(t, p.Process(r.events[2]))
+	require.Len(t, p.applied, 2)
+	_, err = p.CompetitorCommentary(Bib{Number: 1})
+	require.ErrorIs(t, err, ErrReplayDropped)
+	require.EqualError(t, err, "replayed events dropped: the 1 oldest events are over the limit of 2")
+}
@@ -0,0 +1,618 @@
+package biathlon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"BiathlonCompetitions/parser"
+)
+
+const (
+	WarnConfigDefault      WarningCode = "config_default"
+	WarnUnknownConfigField WarningCode = "unknown_config_field"
+)
+
+// configDefaults holds the values optional config fields take when absent.
+var configDefaults = map[string]any{
+	"firingLines":          2,
+	"targetsPerLine":       5,
+	"penaltyLoopTolerance": 0.5,
+	"startLineTimeout":     "00:02:00",
+}
+
+type Config struct {
+	// RaceID names the race in the exit summary and Discipline is the race
+	// format, such as sprint. Both are optional metadata.
+	RaceID         string `json:"raceId,omitempty"`
+	Discipline     string `json:"discipline,omitempty"`
+	Laps           int    `json:"laps"`
+	LapLen         int    `json:"lapLen"`
+	PenaltyLen     int    `json:"penaltyLen"`
+	FiringLines    int    `json:"firingLines"`
+	TargetsPerLine int    `json:"targetsPerLine"`
+	Start          string `json:"start"`
+	StartDelta     string `json:"startDelta"`
+	Profile        string `json:"profile,omitempty"`
+
+	// PenaltyLoopTolerance is how many penalty loops short of the required
+	// count the estimate from penalty time may be before the audit flags it.
+	PenaltyLoopTolerance float64 `json:"penaltyLoopTolerance"`
+
+	// MaxCompetitors caps the number of registrations and BibRange holds
+	// the lowest and highest allowed start numbers. Both are unchecked when
+	// absent.
+	MaxCompetitors int   `json:"maxCompetitors,omitempty"`
+	BibRange       []int `json:"bibRange,omitempty"`
+
+	// StartLineTimeout is how long after the startLine event the start is
+	// expected, in the startDelta format.
+	StartLineTimeout string `json:"startLineTimeout"`
+
+	// FiringOrder is the shooting position of every bout in order, "P" for
+	// prone and "S" for standing. Bouts beyond it have no position.
+	FiringOrder []string `json:"firingOrder,omitempty"`
+
+	// Rules are the league's custom rules, evaluated after processing.
+	Rules []RuleConfig `json:"rules,omitempty"`
+
+	// Payouts is the prize money of every paid place.
+	Payouts map[int]float64 `json:"payouts,omitempty"`
+
+	// Cutoffs are the intermediate time limits at lap ends.
+	Cutoffs []Cutoff `json:"cutoffs,omitempty"`
+
+	// ResumeWindow is how long a competitor may be off the mat, in the
+	// startDelta format, for a return within the same lap to resume the
+	// bout instead of starting a new one, as after a rifle malfunction.
+	// Resumes aren't detected without it. ResumeExcludesGap leaves the time
+	// off the mat out of the range time of a resumed bout.
+	ResumeWindow      string `json:"resumeWindow,omitempty"`
+	ResumeExcludesGap bool   `json:"resumeExcludesGap,omitempty"`
+
+	// Relays are the relay teams with their legs, and ExchangeTolerance is
+	// how long, in the startDelta format, an outgoing leg may start before
+	// the incoming leg finishes. It is 0 when absent.
+	Relays            []RelayTeam `json:"relays,omitempty"`
+	ExchangeTolerance string      `json:"exchangeTolerance,omitempty"`
+
+	// HeartbeatTimeout is how long, in the startDelta format, the timing
+	// system may go without a heartbeat before the window counts as a
+	// telemetry gap. Gaps aren't detected without it.
+	HeartbeatTimeout string `json:"heartbeatTimeout,omitempty"`
+
+	// Defaulted lists the JSON names of optional fields that were absent
+	// from the config file and got their default value.
+	Defaulted []string `json:"-"`
+	// Unknown lists the fields of the config file that aren't config
+	// fields, typically typos.
+	Unknown []string `json:"-"`
+}
+
+// rawConfig mirrors Config with pointer fields so that a field absent from
+// the file can be told apart from one explicitly set to its zero value.
+type rawConfig struct {
+	RaceID         *string `json:"raceId"`
+	Discipline     *string `json:"discipline"`
+	Laps           *int    `json:"laps"`
+	LapLen         *int    `json:"lapLen"`
+	PenaltyLen     *int    `json:"penaltyLen"`
+	FiringLines    *int    `json:"firingLines"`
+	TargetsPerLine *int    `json:"targetsPerLine"`
+	Start          *string `json:"start"`
+	StartDelta     *string `json:"startDelta"`
+	Profile        *string `json:"profile"`
+
+	PenaltyLoopTolerance *float64        `json:"penaltyLoopTolerance"`
+	StartLineTimeout     *string         `json:"startLineTimeout"`
+	MaxCompetitors       *int            `json:"maxCompetitors"`
+	BibRange             []int           `json:"bibRange"`
+	FiringOrder          []string        `json:"firingOrder"`
+	Rules                []RuleConfig    `json:"rules"`
+	Payouts              map[int]float64 `json:"payouts"`
+	Cutoffs              []Cutoff        `json:"cutoffs"`
+	ResumeWindow         *string         `json:"resumeWindow"`
+	ResumeExcludesGap    *bool           `json:"resumeExcludesGap"`
+	Relays               []RelayTeam     `json:"relays"`
+	ExchangeTolerance    *string         `json:"exchangeTolerance"`
+	HeartbeatTimeout     *string         `json:"heartbeatTimeout"`
+}
+
+// Config file formats.
+const (
+	configJSON = "json"
+	configYAML = "yaml"
+)
+
+// LoadConfig reads the config at path, as YAML for the .yaml and .yml
+// extensions and as JSON otherwise. Unknown fields are recorded in
+// Config.Unknown and reported as warnings.
+func LoadConfig(path string) (Config, error) {
+	return readConfig(path, "", false)
+}
+
+// DecodeConfig reads a JSON config from r like LoadConfig.
+func DecodeConfig(r io.Reader) (Config, error) {
+	return decodeConfig(r, configJSON, false)
+}
+
+// loadConfigStrict reads the config at path like LoadConfig, but unknown
+// fields are an error.
+func loadConfigStrict(path, format string) (Config, error) {
+	return readConfig(path, format, true)
+}
+
+// configFormat returns the format of the config at path: format when set,
+// otherwise the one its extension names.
+func configFormat(path, format string) (string, error) {
+	switch format {
+	case configJSON, configYAML:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unknown config format %q, expected json or yaml", format)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return configYAML, nil
+	}
+	return configJSON, nil
+}
+
+// readConfig opens the config at path and decodes it in format, detected
+// from the extension when empty, labeling decoding errors with the path.
+func readConfig(path, format string, strict bool) (cfg Config, err error) {
+	if format, err = configFormat(path, format); err != nil {
+		return Config{}, err
+	}
+	f, err := openConfigFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer func(f *os.File) {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+	if cfg, err = decodeConfig(f, format, strict); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func decodeConfig(r io.Reader, format string, strict bool) (Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Config{}, err
+	}
+	if format == configYAML {
+		if data, err = yamlToJSON(data); err != nil {
+			return Config{}, err
+		}
+	}
+	unknown := unknownConfigFields(data)
+	var raw rawConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(&raw); err != nil {
+		if format == configYAML {
+			err = yamlFieldError(err)
+		}
+		if strict && len(unknown) > 0 {
+			return Config{}, fmt.Errorf("%w (did you mean %q?)", err, closestConfigField(unknown[0]))
+		}
+		return Config{}, err
+	}
+	cfg, err := raw.resolve()
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Unknown = unknown
+	return cfg, nil
+}
+
+// yamlToJSON converts a YAML config to JSON, so that it decodes with the
+// same field names and checks as a JSON one.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, io.EOF
+	}
+	return json.Marshal(jsonValue(doc))
+}
+
+// jsonValue turns the mappings of a decoded YAML value into JSON objects,
+// whose keys are strings: the payouts places are numbers in YAML.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = jsonValue(e)
+		}
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case []any:
+		for i, e := range v {
+			v[i] = jsonValue(e)
+		}
+	}
+	return v
+}
+
+// yamlFieldError rewords an error of the JSON decoding for a YAML config,
+// naming the field by its path.
+func yamlFieldError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		if msg, ok := strings.CutPrefix(err.Error(), "json: "); ok {
+			return errors.New("yaml: " + msg)
+		}
+		return err
+	}
+	if typeErr.Field == "" {
+		return fmt.Errorf("yaml: the config must be a mapping of fields, got %s", typeErr.Value)
+	}
+	return fmt.Errorf("yaml: field %s: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+}
+
+// configFields are the JSON names of the config fields.
+func configFields() []string {
+	t := reflect.TypeOf(rawConfig{})
+	names := make([]string, t.NumField())
+	for i := range names {
+		names[i] = t.Field(i).Tag.Get("json")
+	}
+	return names
+}
+
+// unknownConfigFields returns the top-level keys of a config file that
+// don't name a config field, in file order. Like encoding/json, names match
+// case-insensitively. Malformed JSON is left for the decoder to report.
+func unknownConfigFields(data []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	var unknown []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return unknown
+		}
+		key, _ := tok.(string)
+		if !slices.ContainsFunc(configFields(), func(name string) bool { return strings.EqualFold(name, key) }) {
+			unknown = append(unknown, key)
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return unknown
+		}
+	}
+	return unknown
+}
+
+// closestConfigField returns the config field name nearest to name by edit
+// distance, ignoring case.
+func closestConfigField(name string) string {
+	best, bestDist := "", -1
+	for _, field := range configFields() {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(field)); bestDist < 0 || d < bestDist {
+			best, bestDist = field, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// openConfigFile opens a config file, reporting a missing file as ErrConfigNotFound.
+func openConfigFile(path string) (*os.File, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w", ErrConfigNotFound, err)
+	}
+	return f, err
+}
+
+// Validate checks the settings the processing divides by or parses: the
+// counts and lengths must be positive, and the start and startDelta must
+// be times in the event and HH:MM:SS formats. The error lists every
+// violated field.
+func (c Config) Validate() error {
+	if problems := c.problems(nil); len(problems) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// problems lists the violations Validate reports, leaving out the fields
+// in skip.
+func (c Config) problems(skip map[string]bool) []string {
+	var problems []string
+	positive := func(name string, v int) {
+		if v <= 0 && !skip[name] {
+			problems = append(problems, fmt.Sprintf("%s must be positive, got %d", name, v))
+		}
+	}
+	positive("laps", c.Laps)
+	positive("lapLen", c.LapLen)
+	positive("penaltyLen", c.PenaltyLen)
+	positive("firingLines", c.FiringLines)
+	positive("targetsPerLine", c.TargetsPerLine)
+	if _, err := parser.ParseTime(c.Start); err != nil && !skip["start"] {
+		problems = append(problems, fmt.Sprintf("start must be a time like 10:00:00.000, got %q", c.Start))
+	}
+	if _, err := parser.ParseDelta(c.StartDelta); err != nil && !skip["startDelta"] {
+		problems = append(problems, fmt.Sprintf("startDelta: %s", err))
+	}
+	return problems
+}
+
+// resolve turns the decoded fields into a Config. Required fields must be
+// present, optional ones fall back to their defaults when absent, and the
+// result must pass Validate.
+func (r rawConfig) resolve() (Config, error) {
+	var cfg Config
+	var problems []string
+	missing := make(map[string]bool)
+	required := func(name string, v *int, dst *int) {
+		if v == nil {
+			problems = append(problems, fmt.Sprintf("%s is required", name))
+			missing[name] = true
+			return
+		}
+		*dst = *v
+	}
+	optional := func(name string, v *int, dst *int) {
+		if v == nil {
+			*dst = configDefaults[name].(int)
+			cfg.Defaulted = append(cfg.Defaulted, name)
+			return
+		}
+		*dst = *v
+	}
+	requiredString := func(name string, v *string, dst *string) {
+		if v == nil || *v == "" {
+			problems = append(problems, fmt.Sprintf("%s is required", name))
+			missing[name] = true
+			return
+		}
+		*dst = *v
+	}
+
+	if r.RaceID != nil {
+		cfg.RaceID = *r.RaceID
+	}
+	if r.Discipline != nil {
+		cfg.Discipline = *r.Discipline
+	}
+	required("laps", r.Laps, &cfg.Laps)
+	required("lapLen", r.LapLen, &cfg.LapLen)
+	required("penaltyLen", r.PenaltyLen, &cfg.PenaltyLen)
+	optional("firingLines", r.FiringLines, &cfg.FiringLines)
+	optional("targetsPerLine", r.TargetsPerLine, &cfg.TargetsPerLine)
+	requiredString("start", r.Start, &cfg.Start)
+	requiredString("startDelta", r.StartDelta, &cfg.StartDelta)
+	problems = append(problems, cfg.problems(missing)...)
+	if r.Profile != nil {
+		cfg.Profile = *r.Profile
+	}
+	switch {
+	case r.PenaltyLoopTolerance == nil:
+		cfg.PenaltyLoopTolerance = configDefaults["penaltyLoopTolerance"].(float64)
+		cfg.Defaulted = append(cfg.Defaulted, "penaltyLoopTolerance")
+	case *r.PenaltyLoopTolerance < 0:
+		problems = append(problems, fmt.Sprintf("penaltyLoopTolerance must not be negative, got %g", *r.PenaltyLoopTolerance))
+	default:
+		cfg.PenaltyLoopTolerance = *r.PenaltyLoopTolerance
+	}
+
+	if r.MaxCompetitors != nil {
+		if *r.MaxCompetitors <= 0 {
+			problems = append(problems, fmt.Sprintf("maxCompetitors must be positive, got %d", *r.MaxCompetitors))
+		}
+		cfg.MaxCompetitors = *r.MaxCompetitors
+	}
+	if r.BibRange != nil {
+		if len(r.BibRange) != 2 || r.BibRange[0] > r.BibRange[1] {
+			problems = append(problems, fmt.Sprintf("bibRange must be [lowest, highest], got %v", r.BibRange))
+		}
+		cfg.BibRange = r.BibRange
+	}
+	if r.StartLineTimeout == nil {
+		cfg.StartLineTimeout = configDefaults["startLineTimeout"].(string)
+		cfg.Defaulted = append(cfg.Defaulted, "startLineTimeout")
+	} else if _, err := parser.ParseDelta(*r.StartLineTimeout); err != nil {
+		problems = append(problems, fmt.Sprintf("startLineTimeout: %s", err))
+	} else {
+		cfg.StartLineTimeout = *r.StartLineTimeout
+	}
+
+	for _, position := range r.FiringOrder {
+		if position != PositionProne && position != PositionStanding {
+			problems = append(problems, fmt.Sprintf("firingOrder must list P or S for every bout, got %q", position))
+			break
+		}
+	}
+	cfg.FiringOrder = r.FiringOrder
+	if _, err := compileRules(r.Rules); err != nil {
+		problems = append(problems, err.Error())
+	}
+	cfg.Rules = r.Rules
+	for _, place := range sortedPlaces(r.Payouts) {
+		if place < 1 || r.Payouts[place] < 0 {
+			problems = append(problems, fmt.Sprintf("payouts must map places from 1 to non-negative amounts, got %d: %v", place, r.Payouts[place]))
+			break
+		}
+	}
+	cfg.Payouts = r.Payouts
+	if _, err := parseCutoffs(r.Cutoffs, cfg.Laps); err != nil {
+		problems = append(problems, err.Error())
+	}
+	cfg.Cutoffs = r.Cutoffs
+	if r.ResumeWindow != nil {
+		if _, err := parser.ParseDelta(*r.ResumeWindow); err != nil {
+			problems = append(problems, fmt.Sprintf("resumeWindow: %s", err))
+		}
+		cfg.ResumeWindow = *r.ResumeWindow
+	}
+	if r.ResumeExcludesGap != nil {
+		cfg.ResumeExcludesGap = *r.ResumeExcludesGap
+	}
+	if err := parseRelays(r.Relays); err != nil {
+		problems = append(problems, err.Error())
+	}
+	cfg.Relays = r.Relays
+	if r.ExchangeTolerance != nil {
+		if _, err := parser.ParseDelta(*r.ExchangeTolerance); err != nil {
+			problems = append(problems, fmt.Sprintf("exchangeTolerance: %s", err))
+		}
+		cfg.ExchangeTolerance = *r.ExchangeTolerance
+	}
+	if r.HeartbeatTimeout != nil {
+		if _, err := parser.ParseDelta(*r.HeartbeatTimeout); err != nil {
+			problems = append(problems, fmt.Sprintf("heartbeatTimeout: %s", err))
+		}
+		cfg.HeartbeatTimeout = *r.HeartbeatTimeout
+	}
+
+	if len(problems) > 0 {
+		return Config{}, fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+	return cfg, nil
+}
+
+// warnings reports every unknown field, with the closest known field name,
+// and every optional field that was defaulted.
+func (cfg Config) warnings() []Warning {
+	var warnings []Warning
+	for _, name := range cfg.Unknown {
+		warnings = append(warnings, Warning{Code: WarnUnknownConfigField, Message: fmt.Sprintf("unknown field %q is ignored, did you mean %q?", name, closestConfigField(name))})
+	}
+	for _, name := range cfg.Defaulted {
+		warnings = append(warnings, Warning{Code: WarnConfigDefault, Message: fmt.Sprintf("%s is not set, using default %v", name, configDefaults[name])})
+	}
+	return warnings
+}
+
+func (cfg Config) isDefaulted(name string) bool {
+	for _, d := range cfg.Defaulted {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+// printConfig prints the effective config, marking defaulted values.
+func printConfig(w io.Writer, cfg Config) {
+	fmt.Fprintln(w, "Effective config:")
+	field := func(name string, value any) {
+		mark := ""
+		if cfg.isDefaulted(name) {
+			mark = " (default)"
+		}
+		fmt.Fprintf(w, "  %s: %v%s\n", name, value, mark)
+	}
+	if cfg.RaceID != "" {
+		field("raceId", cfg.RaceID)
+	}
+	if cfg.Discipline != "" {
+		field("discipline", cfg.Discipline)
+	}
+	field("laps", cfg.Laps)
+	field("lapLen", cfg.LapLen)
+	field("penaltyLen", cfg.PenaltyLen)
+	field("firingLines", cfg.FiringLines)
+	field("targetsPerLine", cfg.TargetsPerLine)
+	field("start", cfg.Start)
+	field("startDelta", cfg.StartDelta)
+	if cfg.Profile != "" {
+		field("profile", cfg.Profile)
+	}
+	field("penaltyLoopTolerance", cfg.PenaltyLoopTolerance)
+	field("startLineTimeout", cfg.StartLineTimeout)
+	if cfg.MaxCompetitors != 0 {
+		field("maxCompetitors", cfg.MaxCompetitors)
+	}
+	if cfg.BibRange != nil {
+		field("bibRange", cfg.BibRange)
+	}
+	if cfg.FiringOrder != nil {
+		field("firingOrder", strings.Join(cfg.FiringOrder, ","))
+	}
+	for i, r := range cfg.Rules {
+		action := r.Action
+		if r.Action == ActionPenalty {
+			action += " " + r.Penalty
+		}
+		field(fmt.Sprintf("rules[%d]", i), fmt.Sprintf("%s: when %s then %s", r.Name, r.When, action))
+	}
+	if cfg.Payouts != nil {
+		var paid []string
+		for _, place := range sortedPlaces(cfg.Payouts) {
+			paid = append(paid, fmt.Sprintf("%d=%s", place, formatCents(toCents(cfg.Payouts[place]))))
+		}
+		field("payouts", strings.Join(paid, ","))
+	}
+	if cfg.Cutoffs != nil {
+		field("cutoffs", strings.Join(formatCutoffs(cfg.Cutoffs), ","))
+	}
+	if cfg.ResumeWindow != "" {
+		field("resumeWindow", cfg.ResumeWindow)
+		field("resumeExcludesGap", cfg.ResumeExcludesGap)
+	}
+	for _, r := range cfg.Relays {
+		field("relays["+r.Team+"]", strings.Join(r.Legs, ","))
+	}
+	if cfg.ExchangeTolerance != "" {
+		field("exchangeTolerance", cfg.ExchangeTolerance)
+	}
+	if cfg.HeartbeatTimeout != "" {
+		field("heartbeatTimeout", cfg.HeartbeatTimeout)
+	}
+}
+
+// sortedPlaces returns the places of a payout table in ascending order.
+func sortedPlaces(table map[int]float64) []int {
+	return slices.Sorted(maps.Keys(table))
+}
@@ -0,0 +1,285 @@
+package biathlon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// baseConfigFields are the required fields plus the optional ones not under test.
+const baseConfigFields = `"laps": 2, "lapLen": 3500, "penaltyLen": 150, "start": "10:00:00.000", "startDelta": "00:01:30", "penaltyLoopTolerance": 0.5, "startLineTimeout": "00:02:00"`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadConfigOptionalFields(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		fields            string
+		expectedLines     int
+		expectedTargets   int
+		expectedDefaulted []string
+		expectedError     bool
+	}{
+		{
+			name:              "test_absent_optional_fields",
+			fields:            "",
+			expectedLines:     2,
+			expectedTargets:   5,
+			expectedDefaulted: []string{"firingLines", "targetsPerLine"},
+		},
+		{
+			name:          "test_zero_firing_lines",
+			fields:        `, "firingLines": 0, "targetsPerLine": 5`,
+			expectedError: true,
+		},
+		{
+			name:          "test_zero_targets_per_line",
+			fields:        `, "firingLines": 2, "targetsPerLine": 0`,
+			expectedError: true,
+		},
+		{
+			name:            "test_explicit_optional_fields",
+			fields:          `, "firingLines": 4, "targetsPerLine": 3`,
+			expectedLines:   4,
+			expectedTargets: 3,
+		},
+		{
+			name:              "test_explicit_firing_lines_only",
+			fields:            `, "firingLines": 1`,
+			expectedLines:     1,
+			expectedTargets:   5,
+			expectedDefaulted: []string{"targetsPerLine"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			cfg, err := DecodeConfig(strings.NewReader("{" + baseConfigFields + test.fields + "}"))
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedLines, cfg.FiringLines)
+			require.Equal(t, test.expectedTargets, cfg.TargetsPerLine)
+			require.Equal(t, test.expectedDefaulted, cfg.Defaulted)
+			require.Len(t, cfg.warnings(), len(test.expectedDefaulted))
+		})
+	}
+}
+
+func TestLoadConfigPenaltyLoopTolerance(t *testing.T) {
+	t.Parallel()
+	required := `"laps": 2, "lapLen": 3500, "penaltyLen": 150, "firingLines": 2, "targetsPerLine": 5, "start": "10:00:00.000", "startDelta": "00:01:30", "startLineTimeout": "00:02:00"`
+
+	cfg, err := DecodeConfig(strings.NewReader("{" + required + "}"))
+	require.NoError(t, err)
+	require.Equal(t, 0.5, cfg.PenaltyLoopTolerance)
+	require.Equal(t, []string{"penaltyLoopTolerance"}, cfg.Defaulted)
+
+	cfg, err = DecodeConfig(strings.NewReader("{" + required + `, "penaltyLoopTolerance": 0}`))
+	require.NoError(t, err)
+	require.Equal(t, 0.0, cfg.PenaltyLoopTolerance)
+	require.Empty(t, cfg.Defaulted)
+
+	_, err = DecodeConfig(strings.NewReader("{" + required + `, "penaltyLoopTolerance": -1}`))
+	require.Error(t, err)
+}
+
+func TestLoadConfigRequiredFields(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "test_absent_laps",
+			content: `{"lapLen": 3500, "penaltyLen": 150, "start": "10:00:00.000", "startDelta": "00:01:30"}`,
+		},
+		{
+			name:    "test_zero_laps",
+			content: `{"laps": 0, "lapLen": 3500, "penaltyLen": 150, "start": "10:00:00.000", "startDelta": "00:01:30"}`,
+		},
+		{
+			name:    "test_zero_lap_len",
+			content: `{"laps": 2, "lapLen": 0, "penaltyLen": 150, "start": "10:00:00.000", "startDelta": "00:01:30"}`,
+		},
+		{
+			name:    "test_absent_start",
+			content: `{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "startDelta": "00:01:30"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := DecodeConfig(strings.NewReader(test.content))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+	valid := Config{Laps: 2, LapLen: 3500, PenaltyLen: 150, FiringLines: 2, TargetsPerLine: 5, Start: "10:00:00.000", StartDelta: "00:01:30"}
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		err    string
+	}{
+		{name: "valid", modify: func(*Config) {}},
+		{name: "zero laps", modify: func(c *Config) { c.Laps = 0 }, err: "invalid config: laps must be positive, got 0"},
+		{name: "negative lap length", modify: func(c *Config) { c.LapLen = -100 }, err: "invalid config: lapLen must be positive, got -100"},
+		{name: "zero penalty length", modify: func(c *Config) { c.PenaltyLen = 0 }, err: "invalid config: penaltyLen must be positive, got 0"},
+		{name: "zero firing lines", modify: func(c *Config) { c.FiringLines = 0 }, err: "invalid config: firingLines must be positive, got 0"},
+		{name: "zero targets", modify: func(c *Config) { c.TargetsPerLine = 0 }, err: "invalid config: targetsPerLine must be positive, got 0"},
+		{name: "unparsable start", modify: func(c *Config) { c.Start = "10:00" }, err: `invalid config: start must be a time like 10:00:00.000, got "10:00"`},
+		{name: "unparsable start delta", modify: func(c *Config) { c.StartDelta = "90s" }, err: `invalid config: startDelta: invalid delta: "90s"`},
+		{
+			name:   "every violation",
+			modify: func(c *Config) { *c = Config{} },
+			err: "invalid config: laps must be positive, got 0; lapLen must be positive, got 0; penaltyLen must be positive, got 0; " +
+				`firingLines must be positive, got 0; targetsPerLine must be positive, got 0; start must be a time like 10:00:00.000, got ""; startDelta: invalid delta: ""`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := valid
+			test.modify(&cfg)
+			err := cfg.Validate()
+			if test.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, test.err)
+		})
+	}
+
+	_, err := DecodeConfig(strings.NewReader(`{"laps": 2, "penaltyLen": 150, "start": "morning", "startDelta": "00:01:30"}`))
+	require.EqualError(t, err, `invalid config: lapLen is required; start must be a time like 10:00:00.000, got "morning"`, "a missing field is reported once")
+}
+
+func TestLoadConfigUnknownFields(t *testing.T) {
+	t.Parallel()
+	typo := `{"laps": 2, "LapLenght": 3500, "lapLen": 3500, "penaltyLen": 150, "start": "10:00:00.000", "startDelta": "00:01:30", "PENALTYLEN": 150}`
+	path := writeConfig(t, typo)
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"LapLenght"}, cfg.Unknown)
+	warnings := cfg.warnings()
+	require.Equal(t, Warning{Code: WarnUnknownConfigField, Message: `unknown field "LapLenght" is ignored, did you mean "lapLen"?`}, warnings[0])
+
+	_, err = loadConfigStrict(path, "")
+	require.EqualError(t, err, path+`: json: unknown field "LapLenght" (did you mean "lapLen"?)`)
+
+	cfg, err = loadConfigStrict(writeConfig(t, `{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "start": "10:00:00.000", "startDelta": "00:01:30"}`), "")
+	require.NoError(t, err)
+	require.Empty(t, cfg.Unknown)
+}
+
+func TestDecodeConfigMalformed(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "empty", content: "", err: "EOF"},
+		{name: "truncated", content: `{"laps": 2, "lapLen": 35`, err: "unexpected EOF"},
+		{name: "wrong type", content: `{"laps": "two"}`, err: "json: cannot unmarshal string into Go struct field rawConfig.laps of type int"},
+		{name: "not an object", content: `[1, 2]`, err: "json: cannot unmarshal array into Go value of type biathlon.rawConfig"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := DecodeConfig(strings.NewReader(test.content))
+			require.EqualError(t, err, test.err)
+		})
+	}
+
+	_, err := DecodeConfig(iotest.ErrReader(errors.New("connection reset")))
+	require.EqualError(t, err, "connection reset")
+}
+
+func TestLoadConfigLabelsErrors(t *testing.T) {
+	t.Parallel()
+	path := writeConfig(t, `{"laps": 2,`)
+	_, err := LoadConfig(path)
+	require.EqualError(t, err, path+": unexpected EOF")
+}
+
+func TestEditDistance(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, 0, editDistance("laps", "laps"))
+	require.Equal(t, 3, editDistance("laplenght", "laplen"))
+	require.Equal(t, 1, editDistance("lap", "laps"))
+	require.Equal(t, 4, editDistance("", "laps"))
+	require.Equal(t, "firingLines", closestConfigField("firingLine"))
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	t.Parallel()
+	want, err := LoadConfig("testdata/config.json")
+	require.NoError(t, err)
+	got, err := LoadConfig("testdata/config.yaml")
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	require.Equal(t, map[int]float64{1: 500, 2: 250.5}, got.Payouts)
+
+	yml := filepath.Join(t.TempDir(), "race.yml")
+	data, err := os.ReadFile("testdata/config.yaml")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(yml, data, 0o644))
+	got, err = LoadConfig(yml)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+
+	_, err = readConfig(writeConfig(t, string(data)), configJSON, false)
+	require.Error(t, err)
+	got, err = readConfig(writeConfig(t, string(data)), configYAML, false)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	_, err = readConfig("testdata/config.yaml", "toml", false)
+	require.EqualError(t, err, `unknown config format "toml", expected json or yaml`)
+}
+
+func TestDecodeConfigMalformedYAML(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "empty", content: "", err: "EOF"},
+		{name: "syntax", content: "laps: 2\n  lapLen: 3500\n", err: "yaml: line 2: mapping values are not allowed in this context"},
+		{name: "wrong type", content: "laps: two\n", err: "yaml: field laps: expected int, got string"},
+		{name: "nested field", content: "cutoffs:\n  - afterLap: first\n", err: "yaml: field cutoffs.0.afterLap: expected int, got string"},
+		{name: "not a mapping", content: "- 1\n- 2\n", err: "yaml: the config must be a mapping of fields, got array"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := decodeConfig(strings.NewReader(test.content), configYAML, false)
+			require.EqualError(t, err, test.err)
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("laps: 2\nLapLenght: 3500\npenaltyLen: 150\nstart: \"10:00:00.000\"\nstartDelta: \"00:01:30\"\n"), 0o644))
+	_, err := loadConfigStrict(path, "")
+	require.EqualError(t, err, path+`: yaml: unknown field "LapLenght" (did you mean "lapLen"?)`)
+}
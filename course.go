@@ -0,0 +1,123 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const WarnOutsideCourseWindow WarningCode = "outside_course_window"
+
+// TimelineEntry is a race-level event, such as the course opening by the
+// forerunners. Note is the event's extra params and Seq its number.
+type TimelineEntry struct {
+	Time    time.Time
+	EventID int
+	Note    string
+	Seq     int
+}
+
+// raceHandler applies one kind of race-level event and returns the
+// commentary lines it produced.
+type raceHandler func(p *Processor, e Event) []LogLine
+
+var raceHandlers = map[int]raceHandler{
+	courseOpened: handleCourseOpened,
+	courseClosed: handleCourseClosed,
+}
+
+// onCourseEvents are the competitor events that only happen on an open
+// course. Registrations, draws, the start line and comments may come at
+// any time.
+var onCourseEvents = map[int]bool{
+	isStarted:             true,
+	onTheFiringRange:      true,
+	hit:                   true,
+	leftTheFiringRange:    true,
+	enteredThePenaltyLaps: true,
+	leftThePenaltyLaps:    true,
+	endedTheMainLap:       true,
+	shot:                  true,
+}
+
+func handleCourseOpened(p *Processor, e Event) []LogLine {
+	if p.courseOpened.IsZero() {
+		p.courseOpened = e.Time
+	}
+	return []LogLine{logf(e, "The course is opened%s", courseNote(e))}
+}
+
+func handleCourseClosed(p *Processor, e Event) []LogLine {
+	p.courseClosed = e.Time
+	return []LogLine{logf(e, "The course is closed%s", courseNote(e))}
+}
+
+func courseNote(e Event) string {
+	if e.Extra == "" {
+		return ""
+	}
+	return " (" + e.Extra + ")"
+}
+
+// courseWindow returns when the course was first opened and last closed
+// in events, each zero when it wasn't logged.
+func courseWindow(events []Event) (opened, closed time.Time) {
+	for _, e := range events {
+		switch e.EventID {
+		case courseOpened:
+			if opened.IsZero() {
+				opened = e.Time
+			}
+		case courseClosed:
+			closed = e.Time
+		}
+	}
+	return opened, closed
+}
+
+// outsideCourse reports an on-course event timed before the course opened
+// or after it closed. Either bound is unchecked while it is zero.
+func outsideCourse(e Event, opened, closed time.Time) (Warning, bool) {
+	if !onCourseEvents[e.EventID] {
+		return Warning{}, false
+	}
+	if !opened.IsZero() && e.Time.Before(opened) {
+		return Warning{Code: WarnOutsideCourseWindow, Message: fmt.Sprintf("event %d before the course opened at %s is ignored", e.EventID, opened.Format(timeLayout))}, true
+	}
+	if !closed.IsZero() && e.Time.After(closed) {
+		return Warning{Code: WarnOutsideCourseWindow, Message: fmt.Sprintf("event %d after the course closed at %s is ignored", e.EventID, closed.Format(timeLayout))}, true
+	}
+	return Warning{}, false
+}
+
+// processRace applies a race-level event and records it in the timeline.
+func (p *Processor) processRace(h raceHandler, e Event) {
+	for _, line := range h(p, e) {
+		p.narrate(e, line)
+	}
+	p.timeline = append(p.timeline, TimelineEntry{Time: e.Time, EventID: e.EventID, Note: e.Extra, Seq: e.Seq})
+}
+
+// Timeline returns the race-level events in the order they were applied.
+func (p *Processor) Timeline() []TimelineEntry {
+	return p.timeline
+}
+
+// printTimeline prints the race-level events if any were logged.
+func printTimeline(w io.Writer, timeline []TimelineEntry) {
+	if len(timeline) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nRace timeline:")
+	for _, entry := range timeline {
+		what := "course opened"
+		if entry.EventID == courseClosed {
+			what = "course closed"
+		}
+		what += eventRef(entry.Seq)
+		if entry.Note != "" {
+			what += ": " + entry.Note
+		}
+		fmt.Fprintf(w, "[%s] %s\n", entry.Time.Format(timeLayout), what)
+	}
+}
@@ -0,0 +1,64 @@
+package biathlon
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCourseWindow(t *testing.T) {
+	t.Parallel()
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:40:00.000] 5 1 1",
+		"[09:50:00.000] 13 0 by forerunners",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[10:00:01.744] 4 1",
+		"[10:12:35.380] 10 1",
+		"[10:25:26.047] 10 1",
+		"[10:30:00.000] 14 0",
+		"[10:31:00.000] 10 1",
+	)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+
+	require.Contains(t, out.String(), "[09:40:00.000] Warning for competitor(1): outside_course_window: event 5 before the course opened at 09:50:00.000 is ignored\n")
+	require.Contains(t, out.String(), "[09:50:00.000] The course is opened (by forerunners)\n")
+	require.Contains(t, out.String(), "[10:30:00.000] The course is closed\n")
+	require.Contains(t, out.String(), "[10:31:00.000] Warning for competitor(1): outside_course_window: event 10 after the course closed at 10:30:00.000 is ignored\n")
+	require.NotContains(t, out.String(), "The competitor(1) is on the firing range")
+
+	c := p.Competitors()[Bib{Number: 1}]
+	require.Empty(t, c.Bouts)
+	require.Equal(t, 2, c.LapsCompleted)
+	require.Equal(t, 2, p.warnings)
+	require.Zero(t, p.quality.NonPositiveCompetitors)
+
+	opened, _ := time.Parse(timeLayout, "09:50:00.000")
+	closed, _ := time.Parse(timeLayout, "10:30:00.000")
+	require.Equal(t, []TimelineEntry{
+		{Time: opened, EventID: courseOpened, Note: "by forerunners"},
+		{Time: closed, EventID: courseClosed},
+	}, p.Timeline())
+
+	var report bytes.Buffer
+	printTimeline(&report, p.Timeline())
+	require.Equal(t, "\nRace timeline:\n[09:50:00.000] course opened: by forerunners\n[10:30:00.000] course closed\n", report.String())
+}
+
+func TestCourseWindowUnbounded(t *testing.T) {
+	t.Parallel()
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:40:00.000] 5 1 1",
+	)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.NotContains(t, out.String(), "outside_course_window")
+	require.Len(t, p.Competitors()[Bib{Number: 1}].Bouts, 1)
+	require.Empty(t, p.Timeline())
+}
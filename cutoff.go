@@ -0,0 +1,78 @@
+package biathlon
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"BiathlonCompetitions/parser"
+)
+
+const (
+	WarnLapCutoff        WarningCode = "lap_cutoff"
+	WarnPulledCompetitor WarningCode = "pulled_competitor"
+)
+
+// Cutoff is an intermediate time limit: a competitor whose elapsed time at
+// the end of lap AfterLap is over MaxElapsed, in the startDelta format, is
+// pulled from the course.
+type Cutoff struct {
+	AfterLap   int    `json:"afterLap"`
+	MaxElapsed string `json:"maxElapsed"`
+}
+
+// parseCutoffs returns the time limits of cutoffs by lap. Every lap must be
+// one of the laps of the race and have a single cutoff.
+func parseCutoffs(cutoffs []Cutoff, laps int) (map[int]time.Duration, error) {
+	limits := make(map[int]time.Duration, len(cutoffs))
+	for _, c := range cutoffs {
+		if c.AfterLap < 1 || c.AfterLap > laps {
+			return nil, fmt.Errorf("cutoff afterLap must be a lap from 1 to %d, got %d", laps, c.AfterLap)
+		}
+		if _, dup := limits[c.AfterLap]; dup {
+			return nil, fmt.Errorf("cutoff after lap %d is set twice", c.AfterLap)
+		}
+		limit, err := parser.ParseDelta(c.MaxElapsed)
+		if err != nil {
+			return nil, fmt.Errorf("cutoff after lap %d: %w", c.AfterLap, err)
+		}
+		limits[c.AfterLap] = limit
+	}
+	return limits, nil
+}
+
+// elapsed is the competitor's race time at t: the time since the scheduled
+// start less any start compensation.
+func (c *Competitor) elapsed(t time.Time) time.Duration {
+	return t.Sub(c.StartTime) - c.Compensation
+}
+
+// checkCutoff pulls c if its lap just ended at e is over the cutoff of the
+// lap. The warning alerts the marshals to take the competitor off the
+// course.
+func (p *Processor) checkCutoff(c *Competitor, e Event) ([]LogLine, []Warning) {
+	limit, ok := p.cutoffs[c.LapsCompleted]
+	if !ok || c.pulled != 0 {
+		return nil, nil
+	}
+	elapsed := c.elapsed(e.Time)
+	if elapsed <= limit {
+		return nil, nil
+	}
+	c.pulled = c.LapsCompleted
+	return []LogLine{logf(e, "The competitor(%s) is pulled after lap %d", e.Bib(), c.LapsCompleted)},
+		[]Warning{{Code: WarnLapCutoff, Message: fmt.Sprintf("pull the competitor off the course: %s after lap %d is over the %s cutoff",
+			formatDuration(elapsed), c.LapsCompleted, formatDuration(limit))}}
+}
+
+// formatCutoffs formats the config cutoffs as lap=maxElapsed pairs in lap
+// order.
+func formatCutoffs(cutoffs []Cutoff) []string {
+	sorted := slices.Clone(cutoffs)
+	slices.SortFunc(sorted, func(a, b Cutoff) int { return a.AfterLap - b.AfterLap })
+	pairs := make([]string, len(sorted))
+	for i, c := range sorted {
+		pairs[i] = fmt.Sprintf("%d=%s", c.AfterLap, c.MaxElapsed)
+	}
+	return pairs
+}
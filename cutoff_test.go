@@ -0,0 +1,86 @@
+package biathlon
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCutoffs(t *testing.T) {
+	t.Parallel()
+	limits, err := parseCutoffs([]Cutoff{{AfterLap: 2, MaxElapsed: "00:25:00"}, {AfterLap: 1, MaxElapsed: "00:12:30.5"}}, 3)
+	require.NoError(t, err)
+	require.Equal(t, map[int]time.Duration{1: 12*time.Minute + 30500*time.Millisecond, 2: 25 * time.Minute}, limits)
+
+	tests := []struct {
+		name    string
+		cutoffs []Cutoff
+		err     string
+	}{
+		{name: "lap zero", cutoffs: []Cutoff{{AfterLap: 0, MaxElapsed: "00:25:00"}}, err: "cutoff afterLap must be a lap from 1 to 3, got 0"},
+		{name: "beyond the last lap", cutoffs: []Cutoff{{AfterLap: 4, MaxElapsed: "00:25:00"}}, err: "cutoff afterLap must be a lap from 1 to 3, got 4"},
+		{name: "twice", cutoffs: []Cutoff{{AfterLap: 2, MaxElapsed: "00:25:00"}, {AfterLap: 2, MaxElapsed: "00:26:00"}}, err: "cutoff after lap 2 is set twice"},
+		{name: "bad time", cutoffs: []Cutoff{{AfterLap: 2, MaxElapsed: "25m"}}, err: `cutoff after lap 2: invalid delta: "25m"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parseCutoffs(test.cutoffs, 3)
+			require.EqualError(t, err, test.err)
+		})
+	}
+
+	cfg, err := DecodeConfig(bytes.NewReader([]byte("{" + baseConfigFields + `, "cutoffs": [{"afterLap": 1, "maxElapsed": "00:13:00"}]}`)))
+	require.NoError(t, err)
+	require.Equal(t, []Cutoff{{AfterLap: 1, MaxElapsed: "00:13:00"}}, cfg.Cutoffs)
+	_, err = DecodeConfig(bytes.NewReader([]byte("{" + baseConfigFields + `, "cutoffs": [{"afterLap": 3, "maxElapsed": "00:13:00"}]}`)))
+	require.ErrorContains(t, err, "cutoff afterLap must be a lap from 1 to 2, got 3")
+}
+
+func TestLapCutoff(t *testing.T) {
+	t.Parallel()
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:32:17.531] 1 2",
+		"[09:33:00.000] 1 3",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[09:55:10.000] 2 2 10:01:30.000",
+		"[09:55:20.000] 2 3 10:03:00.000",
+		"[10:00:01.000] 4 1",
+		"[10:01:31.000] 4 2",
+		"[10:03:01.000] 4 3",
+		"[10:12:00.000] 10 1",
+		"[10:14:00.000] 10 2",
+		"[10:15:00.000] 10 3",
+		"[10:24:00.000] 10 1",
+		// 25m01s after the start of competitor 2.
+		"[10:26:31.000] 10 2",
+		"[10:27:30.000] 10 3",
+		"[10:36:00.000] 10 1",
+		"[10:39:00.000] 5 2 1",
+		"[10:39:30.000] 10 3",
+		"[10:40:00.000] 10 2",
+	)
+	r.cfg.Laps = 3
+	r.cutoffs = map[int]time.Duration{2: 25 * time.Minute}
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+
+	require.Contains(t, out.String(), "[10:26:31.000] The competitor(2) is pulled after lap 2\n"+
+		"[10:26:31.000] Warning for competitor(2): lap_cutoff: pull the competitor off the course: 00:25:01.000 after lap 2 is over the 00:25:00.000 cutoff\n")
+	require.Contains(t, out.String(), "[10:39:00.000] Warning for competitor(2): pulled_competitor: event 5 of a competitor pulled after lap 2 is ignored\n")
+	require.Contains(t, out.String(), "[10:40:00.000] Warning for competitor(2): pulled_competitor: event 10 of a competitor pulled after lap 2 is ignored\n")
+	require.NotContains(t, out.String(), "competitor(1) is pulled")
+	require.NotContains(t, out.String(), "competitor(3) is pulled")
+
+	pulled := p.Competitors()[Bib{Number: 2}]
+	require.Equal(t, 2, pulled.LapsCompleted)
+	require.Empty(t, pulled.Bouts)
+
+	results := p.Results()
+	require.Equal(t, []Bib{{Number: 1}, {Number: 3}, {Number: 2}}, []Bib{results[0].Bib, results[1].Bib, results[2].Bib})
+	require.Equal(t, []Status{StatusFinished, StatusFinished, StatusLapped}, []Status{results[0].Status, results[1].Status, results[2].Status})
+}
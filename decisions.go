@@ -0,0 +1,112 @@
+package biathlon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"BiathlonCompetitions/parser"
+)
+
+// Decisions are the jury decisions applied on top of the recorded events.
+type Decisions struct {
+	StartCompensations []StartCompensation `json:"startCompensations"`
+	ExchangePenalties  []ExchangePenalty   `json:"exchangePenalties"`
+}
+
+// StartCompensation corrects a start gate fault: every competitor whose
+// isStarted event falls within [From, To] is credited with Correction,
+// which is taken off both the actual start time and the total time.
+type StartCompensation struct {
+	From       time.Time
+	To         time.Time
+	Correction time.Duration
+}
+
+func (c *StartCompensation) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		From       string `json:"from"`
+		To         string `json:"to"`
+		Correction string `json:"correction"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	from, err := time.Parse(timeLayout, raw.From)
+	if err != nil {
+		return fmt.Errorf("start compensation from: %w", err)
+	}
+	to, err := time.Parse(timeLayout, raw.To)
+	if err != nil {
+		return fmt.Errorf("start compensation to: %w", err)
+	}
+	correction, err := parser.ParseDelta(raw.Correction)
+	if err != nil {
+		return fmt.Errorf("start compensation correction: %w", err)
+	}
+	if to.Before(from) {
+		return fmt.Errorf("start compensation ends at %s before it starts at %s", raw.To, raw.From)
+	}
+	*c = StartCompensation{From: from, To: to, Correction: correction}
+	return nil
+}
+
+// loadDecisions reads the decisions file at path. An empty path means no
+// decisions were taken.
+func loadDecisions(path string) (d Decisions, err error) {
+	if path == "" {
+		return Decisions{}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Decisions{}, err
+	}
+	defer func(f *os.File) {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+	if err := json.NewDecoder(f).Decode(&d); err != nil {
+		return Decisions{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return d, nil
+}
+
+// onRaceDay places the clock times of d on the day of race r.
+func (d Decisions) onRaceDay(r race) Decisions {
+	compensations := make([]StartCompensation, len(d.StartCompensations))
+	for i, c := range d.StartCompensations {
+		compensations[i] = StartCompensation{From: r.onRaceDay(c.From), To: r.onRaceDay(c.To), Correction: c.Correction}
+	}
+	d.StartCompensations = compensations
+	return d
+}
+
+// startCompensation returns the correction for a start recorded at t.
+func (d Decisions) startCompensation(t time.Time) (time.Duration, bool) {
+	for _, c := range d.StartCompensations {
+		if !t.Before(c.From) && !t.After(c.To) {
+			return c.Correction, true
+		}
+	}
+	return 0, false
+}
+
+// printCompensations lists the competitors credited with a start gate correction.
+func printCompensations(w io.Writer, competitors map[Bib]*Competitor) {
+	var compensated []Bib
+	for _, bib := range sortedBibs(competitors) {
+		if competitors[bib].Compensation != 0 {
+			compensated = append(compensated, bib)
+		}
+	}
+	if len(compensated) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nStart compensations:")
+	for _, bib := range compensated {
+		fmt.Fprintf(w, "Competitor %s: %s\n", bib, formatDuration(competitors[bib].Compensation))
+	}
+}
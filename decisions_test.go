@@ -0,0 +1,71 @@
+package biathlon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDecisions(t *testing.T) {
+	d, err := loadDecisions("")
+	require.NoError(t, err)
+	require.Empty(t, d.StartCompensations)
+
+	path := filepath.Join(t.TempDir(), "decisions.json")
+	content := `{"startCompensations": [{"from": "10:00:00.000", "to": "10:05:00.000", "correction": "00:00:05"}]}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	d, err = loadDecisions(path)
+	require.NoError(t, err)
+	require.Len(t, d.StartCompensations, 1)
+	require.Equal(t, 5*time.Second, d.StartCompensations[0].Correction)
+
+	content = `{"startCompensations": [{"from": "10:05:00.000", "to": "10:00:00.000", "correction": "00:00:05"}]}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	_, err = loadDecisions(path)
+	require.Error(t, err)
+}
+
+func TestStartCompensation(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:00.000] 1 1",
+		"[09:32:00.000] 1 2",
+		"[09:33:00.000] 1 3",
+		"[09:34:00.000] 1 4",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[09:55:01.000] 2 2 10:01:30.000",
+		"[09:55:02.000] 2 3 10:03:00.000",
+		"[09:55:03.000] 2 4 10:04:30.000",
+		"[10:00:05.000] 4 1",
+		"[10:01:35.000] 4 2",
+		"[10:04:31.000] 4 3",
+		"[10:06:05.000] 4 4",
+		"[10:13:00.000] 10 1",
+		"[10:14:30.000] 10 2",
+		"[10:16:00.000] 10 3",
+		"[10:17:30.000] 10 4",
+		"[10:26:00.000] 10 1",
+		"[10:27:30.000] 10 2",
+		"[10:29:00.000] 10 3",
+		"[10:30:30.000] 10 4",
+	)
+	from, _ := time.Parse(timeLayout, "10:00:00.000")
+	to, _ := time.Parse(timeLayout, "10:05:00.000")
+	r.decisions = Decisions{StartCompensations: []StartCompensation{{From: from, To: to, Correction: 5 * time.Second}}}
+
+	competitors, err := process(r, nil)
+	require.NoError(t, err)
+
+	for _, id := range []int{1, 2, 3} {
+		comp := competitors[Bib{Number: id}]
+		require.Equal(t, 5*time.Second, comp.Compensation, "competitor %d", id)
+		require.Equal(t, 26*time.Minute-5*time.Second, comp.totalTime(), "competitor %d", id)
+		require.False(t, comp.lateStart, "competitor %d", id)
+	}
+	outside := competitors[Bib{Number: 4}]
+	require.Zero(t, outside.Compensation)
+	require.Equal(t, 26*time.Minute, outside.totalTime())
+	require.True(t, outside.lateStart, "late start outside the fault window stands")
+}
@@ -0,0 +1,68 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// LapStanding is a competitor's place after a lap by time since the
+// scheduled start (virtual rank) next to their position on the road.
+type LapStanding struct {
+	Bib          Bib
+	Elapsed      time.Duration
+	Rank         int
+	RoadPosition int
+}
+
+// lapStandings ranks every competitor who completed lap (1-based) by the
+// time elapsed since their scheduled start. Equal times share a rank.
+func lapStandings(competitors map[Bib]*Competitor, lap int) []LapStanding {
+	var standings []LapStanding
+	for _, bib := range sortedBibs(competitors) {
+		comp := competitors[bib]
+		if len(comp.LapEnds) < lap {
+			continue
+		}
+		standings = append(standings, LapStanding{
+			Bib:          bib,
+			Elapsed:      comp.LapEnds[lap-1].Sub(comp.StartTime),
+			RoadPosition: comp.RoadPositions[lap-1],
+		})
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Elapsed < standings[j].Elapsed
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && standings[i].Elapsed == standings[i-1].Elapsed {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+	return standings
+}
+
+// printRaceDevelopment prints the standings after every lap, annotated
+// with the incidents each competitor had on it.
+func printRaceDevelopment(w io.Writer, competitors map[Bib]*Competitor) {
+	laps := 0
+	for _, comp := range competitors {
+		laps = max(laps, len(comp.LapEnds))
+	}
+	if laps == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nRace development:")
+	for lap := 1; lap <= laps; lap++ {
+		fmt.Fprintf(w, "Lap %d:\n", lap)
+		for _, s := range lapStandings(competitors, lap) {
+			fmt.Fprintf(w, "  %d. Competitor %s %s, road position %d\n", s.Rank, s.Bib, formatDuration(s.Elapsed), s.RoadPosition)
+			for _, in := range competitors[s.Bib].Incidents {
+				if in.Lap == lap {
+					fmt.Fprintf(w, "     incident at %s: %s\n", in.RawTime, in.description())
+				}
+			}
+		}
+	}
+}
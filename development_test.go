@@ -0,0 +1,46 @@
+package biathlon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoadPositionDiffersFromRank(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:00.000] 1 1",
+		"[09:32:00.000] 1 2",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[09:55:01.000] 2 2 10:01:30.000",
+		"[10:00:00.500] 4 1",
+		"[10:01:30.500] 4 2",
+		"[10:12:40.000] 10 1",
+		"[10:13:30.000] 10 2",
+		"[10:25:00.000] 10 2",
+		"[10:25:30.000] 10 1",
+	)
+	competitors, err := process(r, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, []LapStanding{
+		{Bib: Bib{Number: 2}, Elapsed: 12 * time.Minute, Rank: 1, RoadPosition: 2},
+		{Bib: Bib{Number: 1}, Elapsed: 12*time.Minute + 40*time.Second, Rank: 2, RoadPosition: 1},
+	}, lapStandings(competitors, 1))
+	require.Equal(t, []LapStanding{
+		{Bib: Bib{Number: 2}, Elapsed: 23*time.Minute + 30*time.Second, Rank: 1, RoadPosition: 1},
+		{Bib: Bib{Number: 1}, Elapsed: 25*time.Minute + 30*time.Second, Rank: 2, RoadPosition: 2},
+	}, lapStandings(competitors, 2))
+}
+
+func TestLapStandingsTies(t *testing.T) {
+	start, _ := time.Parse(timeLayout, "10:00:00.000")
+	competitors := map[Bib]*Competitor{
+		{Number: 1}: {ID: 1, StartTime: start, LapEnds: []time.Time{start.Add(time.Minute)}, RoadPositions: []int{1}},
+		{Number: 2}: {ID: 2, StartTime: start.Add(time.Second), LapEnds: []time.Time{start.Add(time.Minute + time.Second)}, RoadPositions: []int{2}},
+		{Number: 3}: {ID: 3, StartTime: start, LapEnds: []time.Time{start.Add(2 * time.Minute)}, RoadPositions: []int{3}},
+	}
+	standings := lapStandings(competitors, 1)
+	require.Equal(t, []int{1, 1, 3}, []int{standings[0].Rank, standings[1].Rank, standings[2].Rank})
+	require.Empty(t, lapStandings(competitors, 2))
+}
@@ -0,0 +1,91 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+)
+
+// preflight checks a loaded race without processing it: it collects the
+// warnings attached to events at load time and validates every drawn start
+// time against the startDelta grid and every on-course event against the
+// course window. It also flags the competitors on the start line who don't
+// start within the start line timeout, and a lapLen the median lap time
+// makes implausible.
+func preflight(r race) []string {
+	var lines []string
+	if line, ok := lapLenWarning(r.events, r.cfg.LapLen); ok {
+		lines = append(lines, line)
+	}
+	slots := make(slotMap)
+	startLines := newStartLineWatch(r.startLineTimeout)
+	opened, closed := courseWindow(r.events)
+	for _, e := range r.events {
+		for _, a := range startLines.expire(e.Time) {
+			lines = append(lines, alertLine(a, startLines.timeout))
+		}
+		switch e.EventID {
+		case startLine:
+			startLines.line(e.Bib(), e.Time)
+		case isStarted:
+			startLines.resolve(e.Bib())
+		}
+		for _, w := range e.Warnings {
+			lines = append(lines, warningLine(e, w))
+		}
+		if w, outside := outsideCourse(e, opened, closed); outside {
+			lines = append(lines, warningLine(e, w))
+		}
+		draw, ok := e.Payload.(DrawTime)
+		if e.EventID != startTime || !ok {
+			continue
+		}
+		_, warnings := slots.assign(e.Bib(), draw.Time, r.baseStart, r.delta)
+		for _, w := range warnings {
+			lines = append(lines, warningLine(e, w))
+		}
+	}
+	for _, a := range startLines.close() {
+		lines = append(lines, alertLine(a, startLines.timeout))
+	}
+	return lines
+}
+
+// dryRun loads and validates the race as o loads it for processing, prints
+// the effective config and the preflight warnings to w, and returns the
+// process exit code: 0 when the inputs are clean, 1 otherwise. Defaulted
+// config fields and the draws -respace moved alone don't fail the
+// validation; the lines skipped with -lenient do.
+func dryRun(o raceOptions, w io.Writer) int {
+	if o.strictConfig {
+		if _, err := loadConfigStrict(o.configPath, o.configFormat); err != nil {
+			fmt.Fprintln(w, "config error:", err)
+			return 1
+		}
+	}
+	r, err := loadRace(o.configPath, o.configFormat, o.eventsPath, o.lenient)
+	if err != nil {
+		fmt.Fprintln(w, err)
+		return 1
+	}
+	for _, warning := range r.cfg.warnings() {
+		fmt.Fprintln(w, "Config warning:", warning)
+	}
+	printConfig(w, r.cfg)
+	if o.respace {
+		printRespaces(w, respaceDraws(r.events, r.baseStart, r.delta, o.respaceMargin))
+	}
+	var lines []string
+	if len(r.skipped) > 0 {
+		lines = append(lines, skippedLine(r.skipped))
+	}
+	lines = append(lines, preflight(r)...)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	if len(lines) > 0 {
+		fmt.Fprintf(w, "Validation failed: %d warnings\n", len(lines))
+		return 1
+	}
+	fmt.Fprintln(w, "Validation passed")
+	return 0
+}
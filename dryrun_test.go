@@ -0,0 +1,108 @@
+package biathlon
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		events       string
+		expectedCode int
+		expectedLine string
+	}{
+		{
+			name: "test_clean_setup",
+			events: "[09:31:49.285] 1 1\n" +
+				"[09:32:17.531] 1 2\n" +
+				"[09:55:00.000] 2 1 10:00:00.000\n" +
+				"[09:56:30.000] 2 2 10:01:30.000\n",
+			expectedCode: 0,
+			expectedLine: "Validation passed",
+		},
+		{
+			name: "test_off_grid_draw",
+			events: "[09:31:49.285] 1 1\n" +
+				"[09:32:17.531] 1 2\n" +
+				"[09:55:00.000] 2 1 10:00:00.000\n" +
+				"[09:56:30.000] 2 2 10:02:00.000\n",
+			expectedCode: 1,
+			expectedLine: "[09:56:30.000] Warning for competitor(2) (event #4): off_grid_start: start time 10:02:00.000 is off the startDelta grid",
+		},
+		{
+			name: "test_event_before_course_opened",
+			events: "[09:31:49.285] 1 1\n" +
+				"[09:40:00.000] 5 1 1\n" +
+				"[09:50:00.000] 13 0 by forerunners\n" +
+				"[09:55:00.000] 2 1 10:00:00.000\n",
+			expectedCode: 1,
+			expectedLine: "[09:40:00.000] Warning for competitor(1) (event #2): outside_course_window: event 5 before the course opened at 09:50:00.000 is ignored",
+		},
+		{
+			name:         "test_missing_events_file",
+			expectedCode: 1,
+			expectedLine: "events error",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			eventsPath := filepath.Join(dir, "events")
+			if test.events != "" {
+				require.NoError(t, os.WriteFile(eventsPath, []byte(test.events), 0o644))
+			}
+			var out bytes.Buffer
+			code := dryRun(raceOptions{configPath: "config/config.json", eventsPath: eventsPath}, &out)
+			require.Equal(t, test.expectedCode, code)
+			require.Contains(t, out.String(), test.expectedLine)
+			require.NotContains(t, out.String(), "Final results")
+		})
+	}
+}
+
+// TestDryRunOptions checks that -dry-run loads the race the way processing
+// would with -strict-config, -lenient and -respace.
+func TestDryRunOptions(t *testing.T) {
+	t.Parallel()
+	config := writeConfig(t, `{`+baseConfigFields+`, "LapLenght": 3500}`)
+	dir := t.TempDir()
+	malformed := filepath.Join(dir, "malformed")
+	require.NoError(t, os.WriteFile(malformed, []byte("[09:30:00.000] 1 1\nnot an event\n"), 0o644))
+	collision := filepath.Join(dir, "collision")
+	require.NoError(t, os.WriteFile(collision, []byte("[09:30:00.000] 1 1\n[09:30:01.000] 1 2\n"+
+		"[09:45:00.000] 2 1 10:00:00.000\n[09:45:01.000] 2 2 10:00:00.000\n"), 0o644))
+	tests := []struct {
+		name         string
+		args         []string
+		expectedCode int
+		expectedLine string
+	}{
+		{name: "test_strict_config", args: []string{"-events", collision, "-strict-config"}, expectedCode: 1, expectedLine: `config error: ` + config + `: json: unknown field "LapLenght"`},
+		{name: "test_malformed_line", args: []string{"-events", malformed}, expectedCode: 1, expectedLine: "events error"},
+		{name: "test_lenient", args: []string{"-events", malformed, "-lenient"}, expectedCode: 1, expectedLine: "1 lines skipped: 2\nValidation failed: 1 warnings\n"},
+		{name: "test_collision", args: []string{"-events", collision}, expectedCode: 1, expectedLine: "slot_collision"},
+		{
+			name:         "test_respace",
+			args:         []string{"-events", collision, "-respace", "-respace-margin", "1"},
+			expectedCode: 0,
+			expectedLine: "RESPACED: competitor(2) moved from slot #1 (10:00:00.000) to slot #2 (10:01:30.000), it collided with competitor(1)\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var out bytes.Buffer
+			code := Run(append([]string{"-dry-run", "-config", config}, test.args...), &out, &bytes.Buffer{})
+			require.Equal(t, test.expectedCode, code, out.String())
+			require.Contains(t, out.String(), test.expectedLine)
+		})
+	}
+}
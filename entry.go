@@ -0,0 +1,33 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	WarnFieldFull     WarningCode = "field_full"
+	WarnBibOutOfRange WarningCode = "bib_out_of_range"
+)
+
+// entryRuleViolations checks a registration against the maximum field size
+// and the allowed bib range. registered is the number of competitors
+// registered before this one.
+func entryRuleViolations(cfg Config, bib Bib, registered int) []Warning {
+	var warnings []Warning
+	if cfg.MaxCompetitors > 0 && registered >= cfg.MaxCompetitors {
+		warnings = append(warnings, Warning{Code: WarnFieldFull, Message: fmt.Sprintf("registration beyond the field cap of %d", cfg.MaxCompetitors)})
+	}
+	if len(cfg.BibRange) == 2 && (bib.Number < cfg.BibRange[0] || bib.Number > cfg.BibRange[1]) {
+		warnings = append(warnings, Warning{Code: WarnBibOutOfRange, Message: fmt.Sprintf("bib %s is outside the range %d-%d", bib, cfg.BibRange[0], cfg.BibRange[1])})
+	}
+	return warnings
+}
+
+// printEntries prints the number of entries against the field cap, if any.
+func printEntries(w io.Writer, competitors map[Bib]*Competitor, cfg Config) {
+	if cfg.MaxCompetitors == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nEntries: %d of %d\n", len(competitors), cfg.MaxCompetitors)
+}
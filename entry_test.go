@@ -0,0 +1,57 @@
+package biathlon
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryRules(t *testing.T) {
+	lines := []string{
+		"[09:31:49.285] 1 1",
+		"[09:31:50.285] 1 2",
+		"[09:31:51.285] 1 3",
+		"[09:31:52.285] 1 4",
+		"[09:31:53.285] 1 121",
+		"[09:55:00.000] 2 4 10:00:00.000",
+	}
+
+	r := newTestRace(t, lines...)
+	r.cfg.MaxCompetitors = 3
+	r.cfg.BibRange = []int{1, 120}
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Contains(t, out.String(), "[09:31:52.285] Warning for competitor(4): field_full: registration beyond the field cap of 3\n")
+	require.Contains(t, out.String(), "[09:31:53.285] Warning for competitor(121): field_full")
+	require.Contains(t, out.String(), "[09:31:53.285] Warning for competitor(121): bib_out_of_range: bib 121 is outside the range 1-120\n")
+	require.True(t, p.Competitors()[Bib{Number: 4}].outsideEntryRules)
+	require.False(t, p.Competitors()[Bib{Number: 3}].outsideEntryRules)
+	// Competitor 4 is kept off the start grid.
+	require.Contains(t, out.String(), "[09:55:00.000] The start time for the competitor(4) was set by a draw to 10:00:00.000\n")
+	require.Empty(t, p.slots)
+
+	out.Reset()
+	printEntries(&out, p.Competitors(), r.cfg)
+	require.Equal(t, "\nEntries: 5 of 3\n", out.String())
+
+	p = newProcessor(r, nil, &out)
+	p.enforceEntryRules = true
+	err := p.ProcessAll(r.events)
+	require.ErrorIs(t, err, ErrEntryRule)
+	require.EqualError(t, err, "entry rule violated: competitor(4): registration beyond the field cap of 3")
+}
+
+func TestLoadConfigEntryRules(t *testing.T) {
+	t.Parallel()
+	cfg, err := LoadConfig(writeConfig(t, "{"+baseConfigFields+`, "maxCompetitors": 120, "bibRange": [1, 120]}`))
+	require.NoError(t, err)
+	require.Equal(t, 120, cfg.MaxCompetitors)
+	require.Equal(t, []int{1, 120}, cfg.BibRange)
+
+	_, err = LoadConfig(writeConfig(t, "{"+baseConfigFields+`, "bibRange": [120, 1]}`))
+	require.ErrorContains(t, err, "bibRange must be [lowest, highest]")
+	_, err = LoadConfig(writeConfig(t, "{"+baseConfigFields+`, "maxCompetitors": 0}`))
+	require.ErrorContains(t, err, "maxCompetitors must be positive")
+}
@@ -0,0 +1,30 @@
+package biathlon
+
+import (
+	"errors"
+
+	"BiathlonCompetitions/parser"
+)
+
+var (
+	// ErrConfigNotFound is returned when a config or a file it references doesn't exist.
+	ErrConfigNotFound = errors.New("config file not found")
+	// ErrInvalidEventLine is returned for an events line that can't be parsed.
+	ErrInvalidEventLine = parser.ErrInvalidEventLine
+	// ErrInvalidIncidentLine is returned for an incidents line that can't be parsed.
+	ErrInvalidIncidentLine = errors.New("invalid incident line")
+	// ErrEntryRule is returned for a registration breaking the entry rules
+	// when they are enforced.
+	ErrEntryRule = errors.New("entry rule violated")
+	// ErrInvalidTarget is returned for a hit without a valid target number
+	// when targets are checked strictly.
+	ErrInvalidTarget = errors.New("invalid target number")
+	// ErrInvalidDelta is returned for a duration not in HH:MM:SS[.sss] format.
+	ErrInvalidDelta = parser.ErrInvalidDelta
+	// ErrInvalidRule is returned for a custom rule in the config that can't
+	// be compiled.
+	ErrInvalidRule = errors.New("invalid rule")
+	// ErrReplayDropped is returned for a commentary replay missing the
+	// events dropped to stay within CommentaryLimit.MaxEvents.
+	ErrReplayDropped = errors.New("replayed events dropped")
+)
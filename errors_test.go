@@ -0,0 +1,21 @@
+package biathlon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigNotFound(t *testing.T) {
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	require.True(t, errors.Is(err, ErrConfigNotFound))
+	require.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestLoadProfileNotFound(t *testing.T) {
+	_, err := loadProfile(Config{Laps: 2, Profile: "missing.json"}, filepath.Join(t.TempDir(), "config.json"))
+	require.True(t, errors.Is(err, ErrConfigNotFound))
+}
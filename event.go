@@ -0,0 +1,53 @@
+// Package biathlon processes the events of a biathlon race into commentary
+// and results. Event lines are read by the parser package.
+package biathlon
+
+import (
+	"time"
+
+	"BiathlonCompetitions/parser"
+)
+
+// The event types are the parser's.
+type (
+	Event        = parser.Event
+	Bib          = parser.Bib
+	Payload      = parser.Payload
+	DrawTime     = parser.DrawTime
+	FiringLine   = parser.FiringLine
+	TargetNumber = parser.TargetNumber
+	Reason       = parser.Reason
+	ShotResult   = parser.ShotResult
+	Warning      = parser.Warning
+	WarningCode  = parser.WarningCode
+)
+
+const timeLayout = parser.TimeLayout
+
+// formatStamp formats t as the events log it: with its date when they have
+// one.
+func formatStamp(t time.Time) string {
+	if parser.Dated(t) {
+		return t.Format(parser.DateTimeLayout)
+	}
+	return t.Format(timeLayout)
+}
+
+const (
+	undefined             = parser.Undefined
+	register              = parser.Register
+	startTime             = parser.StartTime
+	startLine             = parser.StartLine
+	isStarted             = parser.IsStarted
+	onTheFiringRange      = parser.OnTheFiringRange
+	hit                   = parser.Hit
+	leftTheFiringRange    = parser.LeftTheFiringRange
+	enteredThePenaltyLaps = parser.EnteredThePenaltyLaps
+	leftThePenaltyLaps    = parser.LeftThePenaltyLaps
+	endedTheMainLap       = parser.EndedTheMainLap
+	comment               = parser.Comment
+	shot                  = parser.Shot
+	courseOpened          = parser.CourseOpened
+	courseClosed          = parser.CourseClosed
+	heartbeat             = parser.Heartbeat
+)
@@ -0,0 +1,84 @@
+package biathlon
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var checkpointFeedHeader = []string{"timestamp", "competitor", "checkpoint", "cumulative", "rank", "road"}
+
+// checkpointFeed writes an append-only CSV of checkpoint crossings for the
+// broadcast graphics. Every row goes out with a single Write so a reader
+// tailing the file never sees half a row, and finish rows are synced to
+// disk when the writer supports it.
+type checkpointFeed struct {
+	w     io.Writer
+	times map[string][]time.Duration // sorted cumulative times per checkpoint
+}
+
+func newCheckpointFeed(w io.Writer) (*checkpointFeed, error) {
+	f := &checkpointFeed{w: w, times: make(map[string][]time.Duration)}
+	return f, f.writeRow(checkpointFeedHeader, false)
+}
+
+// record writes the checkpoint crossed by event e, if any. It must be called
+// after e has been applied to comp. A nil feed records nothing.
+func (f *checkpointFeed) record(e Event, comp *Competitor, cfg Config) error {
+	if f == nil || comp == nil || !comp.Started {
+		return nil
+	}
+	var checkpoint, rankKey, road string
+	switch e.EventID {
+	case onTheFiringRange:
+		checkpoint = fmt.Sprintf("range%d", comp.Bouts[len(comp.Bouts)-1].Index)
+		rankKey = checkpoint
+	case leftThePenaltyLaps:
+		checkpoint = "penalty"
+		rankKey = fmt.Sprintf("penalty%d", len(comp.PenaltyTimes))
+	case endedTheMainLap:
+		checkpoint = fmt.Sprintf("lap%d", comp.LapsCompleted)
+		if comp.LapsCompleted == cfg.Laps {
+			checkpoint = "finish"
+		}
+		rankKey = checkpoint
+		road = strconv.Itoa(comp.RoadPositions[len(comp.RoadPositions)-1])
+	default:
+		return nil
+	}
+	cumulative := e.Time.Sub(comp.StartTime)
+	row := []string{e.RawTime, e.Bib().String(), checkpoint, formatDuration(cumulative), strconv.Itoa(f.rank(rankKey, cumulative)), road}
+	return f.writeRow(row, checkpoint == "finish")
+}
+
+// rank adds cumulative to the times recorded at checkpoint and returns its
+// provisional rank among them. Equal times share a rank.
+func (f *checkpointFeed) rank(checkpoint string, cumulative time.Duration) int {
+	times := f.times[checkpoint]
+	i := sort.Search(len(times), func(i int) bool { return times[i] >= cumulative })
+	times = append(times, 0)
+	copy(times[i+1:], times[i:])
+	times[i] = cumulative
+	f.times[checkpoint] = times
+	return i + 1
+}
+
+func (f *checkpointFeed) writeRow(row []string, sync bool) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(row); err != nil {
+		return err
+	}
+	w.Flush()
+	if _, err := f.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if s, ok := f.w.(interface{ Sync() error }); ok && sync {
+		return s.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package biathlon
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointFeed(t *testing.T) {
+	r, err := loadRace("config/config.json", "", "events", false)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	feed, err := newCheckpointFeed(&out)
+	require.NoError(t, err)
+
+	_, err = process(r, feed)
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, checkpointFeedHeader, rows[0])
+	require.Equal(t, []string{"10:08:49.289", "1", "range1", "00:08:49.289", "1", ""}, rows[1])
+	require.Equal(t, []string{"10:10:43.232", "1", "penalty", "00:10:43.232", "1", ""}, rows[3])
+
+	var finishes [][]string
+	for _, row := range rows[1:] {
+		if row[2] == "finish" {
+			finishes = append(finishes, row)
+		}
+	}
+	require.Equal(t, [][]string{
+		{"10:25:26.047", "1", "finish", "00:25:26.047", "1", "1"},
+		{"10:26:48.356", "2", "finish", "00:25:18.356", "1", "2"},
+		{"10:28:34.773", "3", "finish", "00:25:34.773", "3", "3"},
+		{"10:30:36.413", "4", "finish", "00:26:06.413", "4", "4"},
+		{"10:32:22.472", "5", "finish", "00:26:22.472", "5", "5"},
+	}, finishes)
+}
+
+func TestCheckpointFeedRankTies(t *testing.T) {
+	feed, err := newCheckpointFeed(&bytes.Buffer{})
+	require.NoError(t, err)
+	require.Equal(t, 1, feed.rank("lap1", 100))
+	require.Equal(t, 1, feed.rank("lap1", 90))
+	require.Equal(t, 2, feed.rank("lap1", 100))
+	require.Equal(t, 4, feed.rank("lap1", 120))
+	require.Equal(t, 1, feed.rank("lap2", 200))
+}
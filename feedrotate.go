@@ -0,0 +1,130 @@
+package biathlon
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// rotatingFile is the checkpoint feed file with rotation: once the live tail
+// at path reaches size bytes it is compressed into the next segment
+// path.N.gz and restarted. The first write is the CSV header and starts every
+// tail, so a consumer tailing path always finds it. The SHA-256 of every
+// segment is listed in the path.sha256 sidecar in sha256sum format, rewritten
+// on each rotation.
+type rotatingFile struct {
+	path     string
+	size     int64
+	tail     *os.File
+	written  int64
+	header   []byte
+	segments []string // sidecar lines
+}
+
+// openFeedFile creates the checkpoint feed file at path, rotating it every
+// rotate bytes when rotate is positive.
+func openFeedFile(path string, rotate int64) (io.WriteCloser, error) {
+	if rotate <= 0 {
+		return os.Create(path)
+	}
+	tail, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &rotatingFile{path: path, size: rotate, tail: tail}, nil
+}
+
+// Write writes p to the live tail and rotates it once the tail is full. A
+// single write is never split across segments.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	if f.header == nil {
+		f.header = append([]byte(nil), p...)
+	}
+	n, err := f.tail.Write(p)
+	f.written += int64(n)
+	if err != nil {
+		return n, err
+	}
+	if f.written >= f.size && f.written > int64(len(f.header)) {
+		if err := f.rotate(); err != nil {
+			return n, fmt.Errorf("rotate %s: %w", f.path, err)
+		}
+	}
+	return n, nil
+}
+
+func (f *rotatingFile) Sync() error {
+	return f.tail.Sync()
+}
+
+func (f *rotatingFile) Close() error {
+	return f.tail.Close()
+}
+
+// rotate compresses the live tail into the next segment, records its hash in
+// the sidecar and restarts the tail with the header.
+func (f *rotatingFile) rotate() error {
+	if err := f.tail.Close(); err != nil {
+		return err
+	}
+	segment := fmt.Sprintf("%s.%d.gz", f.path, len(f.segments)+1)
+	if err := gzipFile(f.path, segment); err != nil {
+		return err
+	}
+	sum, err := fileSHA256(segment)
+	if err != nil {
+		return err
+	}
+	f.segments = append(f.segments, fmt.Sprintf("%s  %s\n", sum, filepath.Base(segment)))
+	if err := writeFileAtomic(f.path+".sha256", []byte(strings.Join(f.segments, ""))); err != nil {
+		return err
+	}
+	// Truncate the tail in place so a consumer following path keeps its file.
+	if f.tail, err = os.Create(f.path); err != nil {
+		return err
+	}
+	f.written = 0
+	n, err := f.tail.Write(f.header)
+	f.written = int64(n)
+	return err
+}
+
+// gzipFile writes the gzip compression of the file at src to dst.
+func gzipFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func(in *os.File) {
+		if cerr := in.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}(in)
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func(out *os.File) {
+		if cerr := out.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}(out)
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeFileAtomic replaces the file at path with data, so a reader never
+// sees it half written.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
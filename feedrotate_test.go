@@ -0,0 +1,57 @@
+package biathlon
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFeed(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "feed.csv")
+	header := "timestamp,competitor\n"
+	rows := []string{"10:00:00.000,1\n", "10:00:01.000,2\n", "10:00:02.000,3\n"}
+
+	// The tail rotates once it holds the header and two rows.
+	f, err := openFeedFile(path, int64(len(header)+len(rows[0])+len(rows[1])))
+	require.NoError(t, err)
+	for _, row := range append([]string{header}, rows...) {
+		_, err := io.WriteString(f, row)
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	segment, err := os.ReadFile(path + ".1.gz")
+	require.NoError(t, err)
+	sum := sha256.Sum256(segment)
+	sidecar, err := os.ReadFile(path + ".sha256")
+	require.NoError(t, err)
+	require.Equal(t, hex.EncodeToString(sum[:])+"  feed.csv.1.gz\n", string(sidecar))
+
+	zr, err := gzip.NewReader(mustOpen(t, path+".1.gz"))
+	require.NoError(t, err)
+	rotated, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	require.Equal(t, header+rows[0]+rows[1], string(rotated))
+
+	tail, err := csv.NewReader(mustOpen(t, path)).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"timestamp", "competitor"}, {"10:00:02.000", "3"}}, tail)
+	_, err = os.Stat(path + ".2.gz")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func mustOpen(t *testing.T, path string) *os.File {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, f.Close()) })
+	return f
+}
@@ -0,0 +1,227 @@
+package biathlon
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"BiathlonCompetitions/parser"
+)
+
+func handleRegister(p *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error) {
+	var warnings []Warning
+	if c == nil {
+		warnings = entryRuleViolations(p.cfg, e.Bib(), len(p.competitors))
+	}
+	if len(warnings) > 0 && p.enforceEntryRules {
+		return nil, nil, fmt.Errorf("%w: competitor(%s): %s", ErrEntryRule, e.Bib(), warnings[0].Message)
+	}
+	p.competitors[e.Bib()] = &Competitor{ID: e.CompetitorID, Suffix: e.Suffix, outsideEntryRules: len(warnings) > 0}
+	return []LogLine{logf(e, "The competitor(%s) registered", e.Bib())}, warnings, nil
+}
+
+func handleStartTime(p *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error) {
+	var lines []LogLine
+	var warnings []Warning
+	draw, ok := e.Payload.(DrawTime)
+	if ok {
+		c.StartTime = draw.Time
+	}
+	slot := 0
+	if ok && !c.outsideEntryRules {
+		slot, warnings = p.slots.assign(e.Bib(), c.StartTime, p.baseStart, p.delta)
+	}
+	if slot == 0 {
+		lines = append(lines, logf(e, "The start time for the competitor(%s) was set by a draw to %s", e.Bib(), c.StartTime.Format(timeLayout)))
+	} else {
+		lines = append(lines, logf(e, "The start time for the competitor(%s) was set by a draw to %s (slot #%d)", e.Bib(), c.StartTime.Format(timeLayout), slot))
+	}
+	if ok && c.Started {
+		warnings = append(warnings, Warning{Code: WarnRetroactiveDraw, Message: fmt.Sprintf("start time drawn after the start at %s", c.ActualStart.Format(timeLayout))})
+		lines = append(lines, p.judgeStart(c, e)...)
+	}
+	return lines, warnings, nil
+}
+
+// judgeStart decides whether c started late once both its drawn and its
+// actual start are known, at whichever of the two events came last.
+func (p *Processor) judgeStart(c *Competitor, e Event) []LogLine {
+	c.lateStart = c.startedLate(p.delta)
+	if !c.lateStart {
+		return nil
+	}
+	return []LogLine{logf(e, "The competitor(%s) is disqualified for late start", e.Bib())}
+}
+
+func handleStartLine(p *Processor, _ *Competitor, e Event) ([]LogLine, []Warning, error) {
+	p.startLines.line(e.Bib(), e.Time)
+	return []LogLine{logf(e, "The competitor is on the start line")}, nil, nil
+}
+
+func handleIsStarted(p *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error) {
+	var lines []LogLine
+	p.startLines.resolve(e.Bib())
+	c.ActualStart = e.Time
+	if correction, ok := p.decisions.startCompensation(e.Time); ok {
+		c.ActualStart = e.Time.Add(-correction)
+		c.Compensation = correction
+		lines = append(lines, logf(e, "The start of the competitor(%s) is compensated by %s for a start gate fault", e.Bib(), formatDuration(correction)))
+	}
+	if !c.StartTime.IsZero() {
+		lines = append(lines, p.judgeStart(c, e)...)
+	}
+	c.Started = true
+	lines = append(lines, logf(e, "The competitor(%s) has started", e.Bib()))
+	return lines, nil, nil
+}
+
+// handleOnTheFiringRange opens a bout, or reopens the last one when the
+// return resumes it.
+func handleOnTheFiringRange(p *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error) {
+	shooting := "shooting %d"
+	var index int
+	if bout := p.resumedBout(c, e.Time); bout != nil {
+		bout.Interrupted = true
+		bout.Gap += e.Time.Sub(bout.End)
+		bout.End = time.Time{}
+		index, shooting = bout.Index, "shooting %d resumed"
+	} else {
+		index = c.completedBouts() + 1
+		bout := Bout{Index: index, Start: e.Time}
+		if index <= len(p.cfg.FiringOrder) {
+			bout.Position = p.cfg.FiringOrder[index-1]
+		}
+		c.Bouts = append(c.Bouts, bout)
+	}
+	shooting = fmt.Sprintf(shooting, index)
+	line, ok := e.Payload.(FiringLine)
+	if !ok {
+		return []LogLine{logf(e, "The competitor(%s) is on the firing range (%s)", e.Bib(), shooting)}, nil, nil
+	}
+	if bout := c.openBout(); bout.Line == 0 {
+		bout.Line = line.Line
+	}
+	var warnings []Warning
+	if line.Bout != 0 && line.Bout != index {
+		warnings = append(warnings, Warning{Code: WarnBoutMismatch, Message: fmt.Sprintf("range system reports shooting %d, expected shooting %d", line.Bout, index)})
+	}
+	return []LogLine{logf(e, "The competitor(%s) is on the firing range (%s, line %d)", e.Bib(), shooting, line.Line)}, warnings, nil
+}
+
+func handleHit(p *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error) {
+	target, ok := e.Payload.(TargetNumber)
+	if !ok || target.Target > p.cfg.TargetsPerLine {
+		return rejectHit(p, e, target, ok)
+	}
+	bout := c.openBout()
+	if bout != nil && slices.Contains(bout.HitTargets, target.Target) {
+		return nil, []Warning{{Code: WarnDuplicateHit, Message: fmt.Sprintf("competitor(%s) hit target %d again in bout %d, the hit is ignored", e.Bib(), target.Target, bout.Index)}}, nil
+	}
+	c.Hits++
+	var warnings []Warning
+	if bout != nil {
+		bout.Hits++
+		bout.HitTargets = append(bout.HitTargets, target.Target)
+	} else {
+		warnings = append(warnings, Warning{Code: WarnHitOutsideBout, Message: "hit outside of a firing range visit"})
+	}
+	return []LogLine{logf(e, "The target has been hit (%d) by competitor(%s)", target.Target, e.Bib())}, warnings, nil
+}
+
+// rejectHit ignores a hit without a valid target number. The parser already
+// warned about a missing or malformed one; a target beyond the firing line
+// is warned about here. With strictTargets both are errors instead.
+func rejectHit(p *Processor, e Event, target TargetNumber, parsed bool) ([]LogLine, []Warning, error) {
+	w := Warning{Code: WarnTargetOutOfRange, Message: fmt.Sprintf("target %d is outside 1..%d, the hit is ignored", target.Target, p.cfg.TargetsPerLine)}
+	if !parsed {
+		w = Warning{Code: parser.WarnInvalidTargetNumber, Message: "hit without a target number"}
+		if len(e.Warnings) > 0 {
+			w = e.Warnings[0]
+		}
+	}
+	if p.strictTargets {
+		return nil, nil, fmt.Errorf("%w: [%s] competitor(%s)%s: %s", ErrInvalidTarget, e.RawTime, e.Bib(), eventRef(e.Seq), w.Message)
+	}
+	if !parsed {
+		return nil, nil, nil
+	}
+	return nil, []Warning{w}, nil
+}
+
+func handleLeftTheFiringRange(p *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error) {
+	bout := c.openBout()
+	if bout == nil {
+		return []LogLine{logf(e, "The competitor(%s) left the firing range (%d)", e.Bib(), c.LapsCompleted)},
+			[]Warning{{Code: WarnExitWithoutArrival, Message: "left the firing range without arriving on it, no range time"}}, nil
+	}
+	bout.End = e.Time
+	if bout.Interrupted {
+		// The range time of the first leave is replaced by the whole bout.
+		c.RangeTimes[len(c.RangeTimes)-1] = bout.rangeTime(p.cfg.ResumeExcludesGap)
+	} else {
+		c.RangeTimes = append(c.RangeTimes, bout.rangeTime(false))
+	}
+	return []LogLine{logf(e, "The competitor(%s) left the firing range (%d)", e.Bib(), c.LapsCompleted)}, nil, nil
+}
+
+func handleEnteredThePenaltyLaps(p *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error) {
+	c.StartPenalty = e.Time
+	bouts := c.unservedBouts(p.cfg.TargetsPerLine)
+	if len(bouts) == 0 && c.openBout() == nil && !c.shotThisLap() {
+		bout := c.completedBouts() + 1
+		c.Bouts = append(c.Bouts, Bout{Index: bout, Start: e.Time, End: e.Time, Unobserved: true})
+		bouts = []int{bout}
+	}
+	c.Penalties = append(c.Penalties, PenaltyVisit{Span: Span{Start: e.Time}, Bouts: bouts})
+	return []LogLine{logf(e, "The competitor(%s) entered the penalty laps", e.Bib())}, nil, nil
+}
+
+func handleLeftThePenaltyLaps(_ *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error) {
+	c.PenaltyTimes = append(c.PenaltyTimes, e.Time.Sub(c.StartPenalty))
+	if n := len(c.Penalties); n > 0 && c.Penalties[n-1].End.IsZero() {
+		c.Penalties[n-1].End = e.Time
+	}
+	return []LogLine{logf(e, "The competitor(%s) left the penalty laps", e.Bib())}, nil, nil
+}
+
+func handleEndedTheMainLap(p *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error) {
+	c.LapsCompleted++
+	c.lapTimes = append(c.lapTimes, e.Time.Sub(c.lapStart()))
+	c.FinishTime, c.finishLine = e.Time, e.Line
+	c.LapEnds = append(c.LapEnds, e.Time)
+	p.lapCrossings[c.LapsCompleted]++
+	c.RoadPositions = append(c.RoadPositions, p.lapCrossings[c.LapsCompleted])
+	line := logf(e, "The competitor(%s) ended the main lap", e.Bib())
+	if e.Synthetic {
+		c.SyntheticLaps = append(c.SyntheticLaps, c.LapsCompleted)
+		line = logf(e, "The competitor(%s) ended the main lap (synthetic)", e.Bib())
+	}
+	pulled, warnings := p.checkCutoff(c, e)
+	return append([]LogLine{line}, pulled...), warnings, nil
+}
+
+func handleComment(p *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error) {
+	if c.LapsCompleted != p.cfg.Laps {
+		c.lapTimes = append(c.lapTimes, e.Time.Sub(c.lapStart()))
+	}
+	c.retired = true
+	var reason string
+	if r, ok := e.Payload.(Reason); ok {
+		reason = r.Text
+		c.Reason = &r
+	}
+	return []LogLine{logf(e, "The competitor(%s) can`t continue: %s", e.Bib(), reason)}, nil, nil
+}
+
+func handleShot(_ *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error) {
+	result, ok := e.Payload.(ShotResult)
+	if !ok {
+		return nil, nil, nil
+	}
+	bout := c.openBout()
+	if bout == nil {
+		return nil, []Warning{{Code: WarnShotOutsideBout, Message: "shot outside of a firing range visit"}}, nil
+	}
+	bout.Shots = append(bout.Shots, Shot{Time: e.Time, Hit: result.Hit})
+	return []LogLine{logf(e, "The competitor(%s) fired a shot (%s)", e.Bib(), e.Extra)}, nil, nil
+}
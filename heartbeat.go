@@ -0,0 +1,98 @@
+package biathlon
+
+import (
+	"fmt"
+	"time"
+)
+
+const WarnTelemetryGap WarningCode = "telemetry_gap"
+
+// TelemetryGap is a window without heartbeats from the timing system longer
+// than the heartbeat timeout: the events logged in it are suspect.
+type TelemetryGap struct {
+	From time.Time
+	To   time.Time
+}
+
+func (g TelemetryGap) String() string {
+	return fmt.Sprintf("%s to %s (%s)", g.From.Format(timeLayout), g.To.Format(timeLayout), formatDuration(g.To.Sub(g.From)))
+}
+
+// contains reports whether t falls strictly inside the gap.
+func (g TelemetryGap) contains(t time.Time) bool {
+	return t.After(g.From) && t.Before(g.To)
+}
+
+// heartbeatWatch tracks the heartbeats of the timing system and records the
+// gaps between them longer than timeout. Nothing is tracked while timeout
+// is 0 or before the first heartbeat.
+type heartbeatWatch struct {
+	timeout time.Duration
+	last    time.Time
+	// now is the race clock, and alerted marks the current silence as
+	// alerted about.
+	now     time.Time
+	alerted bool
+	gaps    []TelemetryGap
+}
+
+// beat records a heartbeat at t, closing the gap since the last one when
+// it ran over the timeout.
+func (h *heartbeatWatch) beat(t time.Time) {
+	if h.timeout > 0 && !h.last.IsZero() && t.Sub(h.last) > h.timeout {
+		h.gaps = append(h.gaps, TelemetryGap{From: h.last, To: t})
+	}
+	h.last, h.alerted = t, false
+}
+
+// silent reports whether the heartbeats have stopped for longer than the
+// timeout at t.
+func (h *heartbeatWatch) silent(t time.Time) bool {
+	return h.timeout > 0 && !h.last.IsZero() && t.Sub(h.last) > h.timeout
+}
+
+// expire advances the race clock to now and returns an alert, once per
+// silence, when the heartbeats have stopped.
+func (h *heartbeatWatch) expire(now time.Time) (LogRecord, bool) {
+	h.now = now
+	if h.alerted || !h.silent(now) {
+		return LogRecord{}, false
+	}
+	h.alerted = true
+	w := Warning{Code: WarnTelemetryGap, Message: fmt.Sprintf("no heartbeat from the timing system since %s", formatStamp(h.last))}
+	return LogRecord{Level: LevelWarn, Time: h.last.Add(h.timeout), Message: fmt.Sprintf("Warning: %s", w)}, true
+}
+
+// close records the silence at the end of the events as a gap, when it
+// ran over the timeout.
+func (h *heartbeatWatch) close() {
+	if h.silent(h.now) {
+		h.gaps = append(h.gaps, TelemetryGap{From: h.last, To: h.now})
+	}
+}
+
+// telemetryGaps returns the gaps of the heartbeats in events longer than
+// timeout, including the silence after the last heartbeat up to the last
+// event, so that the events in a gap are known before it closes.
+func telemetryGaps(events []Event, timeout time.Duration) []TelemetryGap {
+	h := heartbeatWatch{timeout: timeout}
+	for _, e := range events {
+		if e.EventID == heartbeat {
+			h.beat(e.Time)
+		}
+		h.now = e.Time
+	}
+	h.close()
+	return h.gaps
+}
+
+// duringTelemetryGap reports whether t falls inside a known gap of the
+// heartbeats, or in the current silence.
+func (p *Processor) duringTelemetryGap(t time.Time) bool {
+	for _, g := range p.knownGaps {
+		if g.contains(t) {
+			return true
+		}
+	}
+	return p.heartbeats.silent(t)
+}
@@ -0,0 +1,99 @@
+package biathlon
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTelemetryGap processes a race whose heartbeats stop for 45 seconds
+// while competitor 1 is on the firing range.
+func TestTelemetryGap(t *testing.T) {
+	r := newTestRace(t,
+		"[09:30:00.000] 1 1",
+		"[09:30:01.000] 1 2",
+		"[09:45:00.000] 2 1 10:00:00.000",
+		"[09:45:01.000] 2 2 10:01:30.000",
+		"[10:00:00.500] 4 1",
+		"[10:01:30.500] 4 2",
+		"[10:04:40.000] 15 0",
+		"[10:04:50.000] 15 0",
+		"[10:05:00.000] 15 0",
+		"[10:05:02.000] 5 1 1",
+		"[10:05:05.000] 6 1 1",
+		"[10:05:20.000] 7 1",
+		"[10:05:45.000] 15 0",
+		"[10:05:50.000] 5 2 2",
+		"[10:05:55.000] 15 0",
+	)
+	r.heartbeatTimeout = 30 * time.Second
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	_, commentary, _ := strings.Cut(out.String(), "The competitor(2) has started\n")
+	require.Equal(t, "[10:05:02.000] The competitor(1) is on the firing range (shooting 1, line 1) (during telemetry gap)\n"+
+		"[10:05:05.000] The target has been hit (1) by competitor(1) (during telemetry gap)\n"+
+		"[10:05:20.000] The competitor(1) left the firing range (0) (during telemetry gap)\n"+
+		"[10:05:30.000] Warning: telemetry_gap: no heartbeat from the timing system since 10:05:00.000\n"+
+		"[10:05:50.000] The competitor(2) is on the firing range (shooting 1, line 2)\n", commentary)
+	require.Equal(t, []TelemetryGap{{From: r.events[8].Time, To: r.events[12].Time}}, p.quality.TelemetryGaps)
+
+	out.Reset()
+	printDataQuality(&out, p.quality)
+	require.Equal(t, "\nData quality:\n1 telemetry gaps without heartbeats, their events are suspect:\n"+
+		"10:05:00.000 to 10:05:45.000 (00:00:45.000)\n", out.String())
+}
+
+// TestHeartbeatsStop ticks a live race past the heartbeat timeout: the
+// stop is alerted about once, and until the heartbeats resume the events
+// are during the gap.
+func TestHeartbeatsStop(t *testing.T) {
+	r := newTestRace(t)
+	r.heartbeatTimeout = 30 * time.Second
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	at := func(clock string) time.Time {
+		t.Helper()
+		ts, err := time.Parse(timeLayout, clock)
+		require.NoError(t, err)
+		return ts
+	}
+	require.NoError(t, p.Process(Event{Time: at("10:00:00.000"), RawTime: "10:00:00.000", EventID: heartbeat}))
+	p.Tick(at("10:00:30.000"))
+	require.Empty(t, out.String())
+	require.False(t, p.duringTelemetryGap(at("10:00:30.000")))
+
+	p.Tick(at("10:00:31.000"))
+	p.Tick(at("10:01:00.000"))
+	require.Equal(t, "[10:00:30.000] Warning: telemetry_gap: no heartbeat from the timing system since 10:00:00.000\n", out.String())
+	require.True(t, p.duringTelemetryGap(at("10:01:00.000")))
+
+	require.NoError(t, p.Process(Event{Time: at("10:01:05.000"), RawTime: "10:01:05.000", EventID: heartbeat}))
+	require.False(t, p.duringTelemetryGap(at("10:01:06.000")))
+	require.Equal(t, []TelemetryGap{{From: at("10:00:00.000"), To: at("10:01:05.000")}}, p.heartbeats.gaps)
+	require.Equal(t, 1, p.warnings)
+}
+
+func TestHeartbeatsWithoutTimeout(t *testing.T) {
+	r := newTestRace(t, "[10:00:00.000] 15 0", "[10:05:00.000] 15 0")
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Empty(t, out.String())
+	require.Empty(t, p.quality.TelemetryGaps)
+	require.Zero(t, p.quality.NonPositiveCompetitors, "heartbeats are race events")
+}
+
+func TestLoadConfigHeartbeatTimeout(t *testing.T) {
+	path := writeConfig(t, `{`+baseConfigFields+`, "heartbeatTimeout": "00:00:30"}`)
+	r, err := loadRace(path, "", "events", false)
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, r.heartbeatTimeout)
+
+	path = writeConfig(t, `{`+baseConfigFields+`, "heartbeatTimeout": "30s"}`)
+	_, err = LoadConfig(path)
+	require.ErrorContains(t, err, "heartbeatTimeout: ")
+}
@@ -0,0 +1,103 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// MissHeatMap counts the misses of the field per target position. A target
+// of a completed bout counts as missed when no hit event named it.
+type MissHeatMap struct {
+	// Competitors is the number of competitors with target number data.
+	Competitors int
+	// ByBout maps the bout index to the misses per target, target 1 first.
+	ByBout  map[int][]int
+	Overall []int
+}
+
+// missHeatMap aggregates the misses of the competitors that sent target
+// numbers with their hit events; the others are left out.
+func missHeatMap(competitors map[Bib]*Competitor, targets int) MissHeatMap {
+	m := MissHeatMap{ByBout: map[int][]int{}, Overall: make([]int, targets)}
+	for _, comp := range competitors {
+		if !hasTargetData(comp) {
+			continue
+		}
+		m.Competitors++
+		for i := range comp.Bouts {
+			bout := &comp.Bouts[i]
+			if bout.open() || bout.Unobserved {
+				continue
+			}
+			hit := make([]bool, targets)
+			for _, t := range bout.HitTargets {
+				if t >= 1 && t <= targets {
+					hit[t-1] = true
+				}
+			}
+			misses, ok := m.ByBout[bout.Index]
+			if !ok {
+				misses = make([]int, targets)
+				m.ByBout[bout.Index] = misses
+			}
+			for t := range hit {
+				if !hit[t] {
+					misses[t]++
+					m.Overall[t]++
+				}
+			}
+		}
+	}
+	return m
+}
+
+func hasTargetData(c *Competitor) bool {
+	for _, b := range c.Bouts {
+		if len(b.HitTargets) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// printMissHeatMap prints the misses per target as a table with a row per
+// bout index and a total row. Nothing is printed without target data.
+func printMissHeatMap(w io.Writer, m MissHeatMap) {
+	if m.Competitors == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nMisses by target (%d competitors):\n", m.Competitors)
+	header := []string{"bout"}
+	for t := range m.Overall {
+		header = append(header, fmt.Sprint(t+1))
+	}
+	fmt.Fprintln(w, heatMapRow(header))
+	bouts := make([]int, 0, len(m.ByBout))
+	for index := range m.ByBout {
+		bouts = append(bouts, index)
+	}
+	sort.Ints(bouts)
+	for _, index := range bouts {
+		fmt.Fprintln(w, heatMapRow(countCells(fmt.Sprint(index), m.ByBout[index])))
+	}
+	fmt.Fprintln(w, heatMapRow(countCells("all", m.Overall)))
+}
+
+func countCells(label string, counts []int) []string {
+	cells := []string{label}
+	for _, n := range counts {
+		cells = append(cells, fmt.Sprint(n))
+	}
+	return cells
+}
+
+func heatMapRow(cells []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-4s", cells[0])
+	for _, cell := range cells[1:] {
+		fmt.Fprintf(&b, " %3s", cell)
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package biathlon
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissHeatMap(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:31:50.285] 1 2",
+		"[09:31:51.285] 1 3",
+		// Competitor 1: bout 1 misses 4 and 5, bout 2 misses 1.
+		"[10:05:00.000] 5 1 1",
+		"[10:05:01.000] 6 1 1",
+		"[10:05:02.000] 6 1 2",
+		"[10:05:03.000] 6 1 3",
+		"[10:05:30.000] 7 1",
+		"[10:15:00.000] 5 1 2",
+		"[10:15:01.000] 6 1 2",
+		"[10:15:02.000] 6 1 3",
+		"[10:15:03.000] 6 1 4",
+		"[10:15:04.000] 6 1 5",
+		"[10:15:30.000] 7 1",
+		// Competitor 2: bout 1 misses everything, bout 2 misses 5.
+		"[10:06:00.000] 5 2 1",
+		"[10:06:30.000] 7 2",
+		"[10:16:00.000] 5 2 2",
+		"[10:16:01.000] 6 2 1",
+		"[10:16:02.000] 6 2 2",
+		"[10:16:03.000] 6 2 3",
+		"[10:16:04.000] 6 2 4",
+		"[10:16:30.000] 7 2",
+		// Competitor 3 has no target numbers and is left out.
+		"[10:07:00.000] 5 3 1",
+		"[10:07:01.000] 6 3",
+		"[10:07:30.000] 7 3",
+	)
+	p := newProcessor(r, nil, io.Discard)
+	require.NoError(t, p.ProcessAll(r.events))
+
+	m := missHeatMap(p.Competitors(), 5)
+	require.Equal(t, 2, m.Competitors)
+	require.Equal(t, map[int][]int{1: {1, 1, 1, 2, 2}, 2: {1, 0, 0, 0, 1}}, m.ByBout)
+	require.Equal(t, []int{2, 1, 1, 2, 3}, m.Overall)
+
+	var out bytes.Buffer
+	printMissHeatMap(&out, m)
+	require.Equal(t, "\nMisses by target (2 competitors):\n"+
+		"bout   1   2   3   4   5\n"+
+		"1      1   1   1   2   2\n"+
+		"2      1   0   0   0   1\n"+
+		"all    2   1   1   2   3\n", out.String())
+
+	out.Reset()
+	printMissHeatMap(&out, missHeatMap(map[Bib]*Competitor{}, 5))
+	require.Empty(t, out.String())
+}
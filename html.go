@@ -0,0 +1,88 @@
+package biathlon
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"time"
+)
+
+//go:embed templates/report.html
+var htmlReport string
+
+// htmlTemplate is the results page. It is self-contained: the styles and
+// the script sorting the table are inline.
+var htmlTemplate = template.Must(template.New("report").Parse(htmlReport))
+
+// htmlResult is a Result as laid out on the results page, formatted with
+// the report style. The Sort fields are the keys the table sorts the
+// columns by, empty for a competitor without the value.
+type htmlResult struct {
+	Place, Competitor, Status, Total, Range, Course string
+	SortPlace, SortTotal, SortRange, SortCourse     string
+	LapsCompleted, Hits, Shots, Misses              int
+	Laps                                            []htmlLap
+	Penalties                                       []htmlLap
+	RangeTimes                                      []string
+	PhotoFinish                                     bool
+	Reason                                          string
+}
+
+// htmlLap is the 1-based main lap N, with its split from the start, or
+// the penalty laps of visit N.
+type htmlLap struct {
+	N                              int
+	Time, Split, Speed, ClimbSpeed string
+}
+
+// renderHTML writes the results as a standalone HTML page: a results table
+// in ranking order that sorts by any column, followed by the lap splits,
+// penalty loops and shooting of every competitor.
+func renderHTML(w io.Writer, results []Result, style reportStyle) error {
+	page := make([]htmlResult, len(results))
+	for i, r := range results {
+		h := htmlResult{
+			Competitor:    r.Bib.String(),
+			Status:        spokenStatuses[r.Status],
+			Range:         style.clock(r.RangeTime),
+			SortPlace:     strconv.Itoa(i + 1),
+			SortRange:     strconv.FormatInt(r.RangeTime.Milliseconds(), 10),
+			LapsCompleted: r.LapsCompleted,
+			Hits:          r.Hits,
+			Shots:         r.Shots,
+			Misses:        r.Misses,
+			PhotoFinish:   r.PhotoFinish,
+			Reason:        reasonNote(r),
+		}
+		if h.Status == "" {
+			h.Status = string(r.Status)
+		}
+		if r.Place > 0 {
+			h.Place = fmt.Sprint(r.Place)
+		}
+		if r.Status == StatusFinished {
+			h.Total, h.Course = style.clock(r.Total), style.clock(r.CourseTime)
+			h.SortTotal = strconv.FormatInt(r.Total.Milliseconds(), 10)
+			h.SortCourse = strconv.FormatInt(r.CourseTime.Milliseconds(), 10)
+		}
+		var split time.Duration
+		for k, lap := range r.Laps {
+			split += lap.Time
+			l := htmlLap{N: k + 1, Time: style.clock(lap.Time), Split: style.clock(split), Speed: style.number(lap.Speed, 2)}
+			if lap.ClimbSpeed != 0 {
+				l.ClimbSpeed = style.number(lap.ClimbSpeed, 2)
+			}
+			h.Laps = append(h.Laps, l)
+		}
+		for k, lap := range r.Penalties {
+			h.Penalties = append(h.Penalties, htmlLap{N: k + 1, Time: style.clock(lap.Time), Speed: style.number(lap.Speed, 2)})
+		}
+		for _, t := range r.RangeTimes {
+			h.RangeTimes = append(h.RangeTimes, style.clock(t))
+		}
+		page[i] = h
+	}
+	return htmlTemplate.Execute(w, page)
+}
@@ -0,0 +1,117 @@
+package biathlon
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// htmlPage is the text of a parsed results page: the cells of the results
+// table by row, the reasons given and the ids of the detail sections.
+type htmlPage struct {
+	rows    [][]string
+	reasons []string
+	ids     []string
+}
+
+// parseHTMLPage parses an HTML page rendered by renderHTML.
+func parseHTMLPage(t *testing.T, page []byte) htmlPage {
+	t.Helper()
+	d := xml.NewDecoder(bytes.NewReader(page))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	var p htmlPage
+	var inResults, inBody, inReason bool
+	var cell *strings.Builder
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			for _, a := range tok.Attr {
+				switch {
+				case a.Name.Local == "id" && a.Value == "results":
+					inResults = true
+				case a.Name.Local == "id":
+					p.ids = append(p.ids, a.Value)
+				case a.Name.Local == "class" && a.Value == "reason":
+					inReason = true
+					p.reasons = append(p.reasons, "")
+				}
+			}
+			switch {
+			case inResults && tok.Name.Local == "tbody":
+				inBody = true
+			case inBody && tok.Name.Local == "tr":
+				p.rows = append(p.rows, nil)
+			case inBody && tok.Name.Local == "td":
+				cell = &strings.Builder{}
+			}
+		case xml.CharData:
+			if cell != nil {
+				cell.Write(tok)
+			}
+			if inReason {
+				p.reasons[len(p.reasons)-1] += string(tok)
+			}
+		case xml.EndElement:
+			switch tok.Name.Local {
+			case "td":
+				if cell != nil {
+					p.rows[len(p.rows)-1] = append(p.rows[len(p.rows)-1], cell.String())
+					cell = nil
+				}
+			case "table":
+				inResults, inBody = false, false
+			case "p":
+				inReason = false
+			}
+		}
+	}
+	return p
+}
+
+func TestRenderHTML(t *testing.T) {
+	r, err := loadRace("config/config.json", "", "testdata/markdown.events", false)
+	require.NoError(t, err)
+	p := newProcessor(r, nil, io.Discard)
+	require.NoError(t, p.ProcessAll(r.events))
+	var out bytes.Buffer
+	require.NoError(t, Render(&out, p.Results(), "html"))
+	require.NotContains(t, out.String(), "http", "the page must not load anything")
+
+	page := parseHTMLPage(t, out.Bytes())
+	require.Equal(t, [][]string{
+		{"1", "1", "finished", "24:50", "2", "9/10", "1", "00:55.2", "23:54.8"},
+		{"", "2", "did not finish", "", "0", "5/10", "0", "00:25", ""},
+		{"", "3", "did not start", "", "0", "0/10", "0", "00:00", ""},
+	}, page.rows)
+	require.Equal(t, []string{"competitor-1", "competitor-2", "competitor-3"}, page.ids)
+	require.Equal(t, []string{"Lost a ski"}, page.reasons)
+	require.Contains(t, out.String(), `<td class="number">1</td><td class="number">12:40</td><td class="number">12:40</td><td class="number">4.61</td>`)
+	require.Contains(t, out.String(), `<td class="number">2</td><td class="number">12:10</td><td class="number">24:50</td><td class="number">4.79</td>`)
+	require.Contains(t, out.String(), `<td class="number">1</td><td class="number">00:30</td><td class="number">5.00</td>`)
+	require.Contains(t, out.String(), "<p>Shooting 9/10, 1 missed. Range 00:55.2 (00:30.2 / 00:25).</p>")
+}
+
+// TestRenderHTMLEscapesReason checks that the free text of a comment can't
+// inject markup into the page.
+func TestRenderHTMLEscapesReason(t *testing.T) {
+	t.Parallel()
+	reason := `<script>alert("lost")</script> & <b>ski</b>`
+	var out bytes.Buffer
+	require.NoError(t, renderHTML(&out, []Result{{Bib: Bib{Number: 2}, Status: StatusNotFinished, Reason: reason}}, reportStyle{locale: locales["en"]}))
+	require.NotContains(t, out.String(), "<script>alert")
+	require.NotContains(t, out.String(), "<b>ski")
+	require.Contains(t, out.String(), "&lt;script&gt;alert(&#34;lost&#34;)&lt;/script&gt; &amp; &lt;b&gt;ski&lt;/b&gt;")
+	require.Equal(t, []string{reason}, parseHTMLPage(t, out.Bytes()).reasons)
+}
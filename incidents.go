@@ -0,0 +1,128 @@
+package biathlon
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"BiathlonCompetitions/parser"
+)
+
+const WarnUnknownIncidentCompetitor WarningCode = "unknown_incident_competitor"
+
+// incidentObstruction is the incident code the jury may compensate for.
+const incidentObstruction = "obstruction"
+
+var incidentRegex = regexp.MustCompile(`^\[(\d{2}:\d{2}:\d{2}\.\d{3})\] (\d+[A-Za-z]?) (\S+)(?: (.*))?$`)
+
+// Incident is a course marshal's report about a competitor, such as a fall,
+// an obstruction or a missed gate.
+type Incident struct {
+	Time    time.Time
+	RawTime string
+	Bib     Bib
+	Code    string
+	Note    string
+	// Lap is the 1-based lap the incident happened on, set once the
+	// incident is attached to its competitor.
+	Lap int
+}
+
+func (in Incident) description() string {
+	if in.Note == "" {
+		return in.Code
+	}
+	return in.Code + ": " + in.Note
+}
+
+// String formats the incident for the audit output. Obstructions are marked
+// as candidates for a jury compensation.
+func (in Incident) String() string {
+	s := fmt.Sprintf("[%s] Incident for competitor(%s) on lap %d: %s", in.RawTime, in.Bib, in.Lap, in.description())
+	if in.Code == incidentObstruction {
+		s += " (suggested compensation)"
+	}
+	return s
+}
+
+// parseIncident parses an incidents line: [time] competitor code note.
+func parseIncident(line string) (Incident, error) {
+	matches := incidentRegex.FindStringSubmatch(line)
+	if len(matches) == 0 {
+		return Incident{}, fmt.Errorf("%w: %q", ErrInvalidIncidentLine, line)
+	}
+	t, err := time.Parse(timeLayout, matches[1])
+	if err != nil {
+		return Incident{}, fmt.Errorf("%w: %w", ErrInvalidIncidentLine, err)
+	}
+	bib, err := parser.ParseBib(matches[2])
+	if err != nil {
+		return Incident{}, fmt.Errorf("%w: %w", ErrInvalidIncidentLine, err)
+	}
+	return Incident{Time: t, RawTime: matches[1], Bib: bib, Code: matches[3], Note: matches[4]}, nil
+}
+
+// loadIncidents reads the marshals' incidents log at path. An empty path
+// means there is no log.
+func loadIncidents(path string) (incidents []Incident, err error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func(f *os.File) {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+	s := bufio.NewScanner(f)
+	for lineNo := 1; s.Scan(); lineNo++ {
+		in, err := parseIncident(s.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		incidents = append(incidents, in)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return incidents, nil
+}
+
+// attachIncidents adds every incident to its competitor's timeline on the
+// lap it happened on. Incidents for unregistered competitors are warnings.
+func attachIncidents(incidents []Incident, competitors map[Bib]*Competitor) []Warning {
+	var warnings []Warning
+	for _, in := range incidents {
+		comp, ok := competitors[in.Bib]
+		if !ok {
+			warnings = append(warnings, Warning{Code: WarnUnknownIncidentCompetitor, Message: fmt.Sprintf("incident at %s for unknown competitor(%s)", in.RawTime, in.Bib)})
+			continue
+		}
+		in.Lap = 1
+		for _, end := range comp.LapEnds {
+			if end.Before(in.Time) {
+				in.Lap++
+			}
+		}
+		comp.Incidents = append(comp.Incidents, in)
+	}
+	return warnings
+}
+
+// allIncidents returns the incidents of all competitors in time order.
+func allIncidents(competitors map[Bib]*Competitor) []Incident {
+	var incidents []Incident
+	for _, bib := range sortedBibs(competitors) {
+		incidents = append(incidents, competitors[bib].Incidents...)
+	}
+	sort.SliceStable(incidents, func(i, j int) bool {
+		return incidents[i].Time.Before(incidents[j].Time)
+	})
+	return incidents
+}
@@ -0,0 +1,87 @@
+package biathlon
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIncident(t *testing.T) {
+	t.Parallel()
+	in, err := parseIncident("[10:15:00.000] 1 obstruction blocked by a spectator")
+	require.NoError(t, err)
+	require.Equal(t, "10:15:00.000", in.RawTime)
+	require.Equal(t, Bib{Number: 1}, in.Bib)
+	require.Equal(t, "obstruction", in.Code)
+	require.Equal(t, "blocked by a spectator", in.Note)
+
+	in, err = parseIncident("[10:15:00.000] 7b fall")
+	require.NoError(t, err)
+	require.Equal(t, Bib{Number: 7, Suffix: "b"}, in.Bib)
+	require.Empty(t, in.Note)
+
+	_, err = parseIncident("[10:15:00.000] fall")
+	require.ErrorIs(t, err, ErrInvalidIncidentLine)
+}
+
+func TestLoadIncidents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incidents")
+	require.NoError(t, os.WriteFile(path, []byte("[10:15:00.000] 1 fall\nbad line\n"), 0o644))
+	_, err := loadIncidents(path)
+	require.ErrorIs(t, err, ErrInvalidIncidentLine)
+	require.ErrorContains(t, err, path+":2:")
+
+	incidents, err := loadIncidents("")
+	require.NoError(t, err)
+	require.Empty(t, incidents)
+}
+
+func TestAttachIncidents(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[10:00:01.000] 4 1",
+		"[10:10:00.000] 10 1",
+		"[10:20:00.000] 10 1",
+	)
+	p := newProcessor(r, nil, io.Discard)
+	require.NoError(t, p.ProcessAll(r.events))
+	var incidents []Incident
+	for _, line := range []string{
+		"[10:05:00.000] 1 fall downhill 2",
+		"[10:15:00.000] 1 obstruction blocked by a spectator",
+		"[10:16:00.000] 9 missed-gate",
+	} {
+		in, err := parseIncident(line)
+		require.NoError(t, err)
+		incidents = append(incidents, in)
+	}
+
+	warnings := attachIncidents(incidents, p.Competitors())
+	require.Len(t, warnings, 1)
+	require.Equal(t, WarnUnknownIncidentCompetitor, warnings[0].Code)
+	comp := p.Competitors()[Bib{Number: 1}]
+	require.Len(t, comp.Incidents, 2)
+	require.Equal(t, 1, comp.Incidents[0].Lap)
+	require.Equal(t, 2, comp.Incidents[1].Lap)
+
+	var out bytes.Buffer
+	printRaceDevelopment(&out, p.Competitors())
+	require.Equal(t, "\nRace development:\n"+
+		"Lap 1:\n"+
+		"  1. Competitor 1 00:10:00.000, road position 1\n"+
+		"     incident at 10:05:00.000: fall: downhill 2\n"+
+		"Lap 2:\n"+
+		"  1. Competitor 1 00:20:00.000, road position 1\n"+
+		"     incident at 10:15:00.000: obstruction: blocked by a spectator\n", out.String())
+
+	out.Reset()
+	printAudit(&out, nil, p.Competitors())
+	require.Equal(t, "\nAudit:\n"+
+		"[10:05:00.000] Incident for competitor(1) on lap 1: fall: downhill 2\n"+
+		"[10:15:00.000] Incident for competitor(1) on lap 2: obstruction: blocked by a spectator (suggested compensation)\n", out.String())
+}
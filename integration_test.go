@@ -1,113 +1,132 @@
-package main
+package biathlon
 
 import (
+	"bytes"
+	"fmt"
 	_ "io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"BiathlonCompetitions/parser"
 )
 
-func TestParseEvent(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		name           string
-		line           string
-		expectedString string
-	}{
-		{
-			name:           "test_invalid_event_format",
-			line:           "hello bad 1",
-			expectedString: "",
-		},
-		{
-			name:           "test_time_should_be_in_brackets",
-			line:           "09:30:00.000 4 1",
-			expectedString: "",
-		},
-		{
-			name:           "test_time_format",
-			line:           "[09:30:bad] 4 1",
-			expectedString: "",
-		},
-		{
-			name:           "test_success_time",
-			line:           "[09:30:01.005] 4 1",
-			expectedString: "success",
-		},
-	}
+func TestLoadConfig(t *testing.T) {
+	cfg, err := DecodeConfig(strings.NewReader("{" + baseConfigFields + `, "firingLines": 2, "targetsPerLine": 5}`))
+	require.NoError(t, err)
+	require.Equal(t, 2, cfg.Laps)
+	require.Equal(t, "00:01:30", cfg.StartDelta)
+}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			t.Parallel()
-			event, err := parseEvent(test.line)
-			if test.expectedString != "" {
-				require.NoError(t, err)
-				require.Equal(t, event.EventID, 4)
-				require.Equal(t, event.CompetitorID, 1)
-				require.Equal(t, event.RawTime, "09:30:01.005")
-				expectedTime, _ := time.Parse(timeLayout, "09:30:01.005")
-				require.Equal(t, event.Time, expectedTime)
-			} else {
-				require.Error(t, err)
-			}
-		})
+// newTestRace builds a race from the repository config and the given event lines.
+func newTestRace(t *testing.T, lines ...string) race {
+	t.Helper()
+	cfg, err := LoadConfig("config/config.json")
+	require.NoError(t, err)
+	baseStart, err := time.Parse(timeLayout, cfg.Start)
+	require.NoError(t, err)
+	delta, err := parser.ParseDelta(cfg.StartDelta)
+	require.NoError(t, err)
+	startLineTimeout, err := parser.ParseDelta(cfg.StartLineTimeout)
+	require.NoError(t, err)
+	r := race{cfg: cfg, baseStart: baseStart, delta: delta, startLineTimeout: startLineTimeout}
+	for _, line := range lines {
+		e, err := parser.ParseEvent(line)
+		require.NoError(t, err)
+		r.events = append(r.events, e)
 	}
+	return r
 }
 
-func TestLoadConfig(t *testing.T) {
-	_, err := loadConfig("config/config.json")
+// TestMidnightRace processes a race started ten minutes before midnight:
+// the times after midnight follow those before it, so every lap, penalty
+// and total time comes out positive and the events stay in their order.
+func TestMidnightRace(t *testing.T) {
+	r, err := loadRace("testdata/midnight.json", "", "testdata/midnight.events", false)
 	require.NoError(t, err)
-}
+	for i := 1; i < len(r.events); i++ {
+		require.False(t, r.events[i].Time.Before(r.events[i-1].Time), "event %d is out of order", r.events[i].Seq)
+	}
+	require.Equal(t, "[00:01:00.000] 10 1", fmt.Sprintf("[%s] %d %s", r.events[14].RawTime, r.events[14].EventID, r.events[14].Bib()))
 
-func TestParseDelta(t *testing.T) {
-	tests := []struct {
-		name      string
-		input     string
-		expecting time.Duration
-	}{
-		{
-			name:      "test_parse_seconds",
-			input:     "00:00:30",
-			expecting: 30 * time.Second,
-		},
-		{
-			name:      "test_parse_minutes_and_hours",
-			input:     "01:30:00",
-			expecting: time.Hour + 30*time.Minute,
-		},
-		{
-			name:      "test_parse_time",
-			input:     "01:23:45.670",
-			expecting: 1*time.Hour + 23*time.Minute + 45*time.Second + 670*time.Millisecond,
-		},
-		{
-			name:      "test_incorrect_format_time",
-			input:     "30s",
-			expecting: 0,
-		},
-		{
-			name:      "test_time_should_match_regular_schedule",
-			input:     "1:2",
-			expecting: 0,
-		},
+	p := newProcessor(r, nil, &bytes.Buffer{})
+	require.NoError(t, p.ProcessAll(r.events))
+	res := p.Results()
+	require.Len(t, res, 2)
+	require.Equal(t, Bib{Number: 2}, res[0].Bib)
+	require.Equal(t, 22*time.Minute+20*time.Second, res[0].Total)
+	require.Equal(t, 22*time.Minute+30*time.Second, res[1].Total)
+	require.Equal(t, []LapResult{{Time: 11 * time.Minute, Speed: 3500.0 / 660}, {Time: 11*time.Minute + 30*time.Second, Speed: 3500.0 / 690}}, res[1].Laps)
+	require.Equal(t, []PenaltyLapResult{{Time: 69 * time.Second, Speed: 150.0 / 69}}, res[1].Penalties)
+	for _, result := range res {
+		require.Equal(t, StatusFinished, result.Status)
+		require.Positive(t, result.CourseTime)
+		for _, lap := range result.Laps {
+			require.Positive(t, lap.Time)
+		}
 	}
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			d, err := parseDelta(test.input)
-			if test.expecting != 0 {
-				require.NoError(t, err)
-				require.Equal(t, test.expecting, d)
-			} else {
-				require.Error(t, err)
-			}
-		})
+}
+
+func TestAlignStart(t *testing.T) {
+	r := newTestRace(t, "[23:30:00.000] 1 1")
+	r.baseStart = r.baseStart.Add(-10 * time.Hour) // 00:00:00.000
+	require.NoError(t, r.alignStart())
+	require.Equal(t, time.Date(0, 1, 2, 0, 0, 0, 0, time.UTC), r.baseStart, "the start is past midnight from the first event")
+
+	r = newTestRace(t, "[09:30:00.000] 1 1")
+	start := r.baseStart
+	require.NoError(t, r.alignStart())
+	require.Equal(t, start, r.baseStart)
+}
+
+// TestDatedRace processes a training race logged with dates, started
+// from an undated and from a dated config start.
+func TestDatedRace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events")
+	require.NoError(t, os.WriteFile(path, []byte("[2024-03-12 09:30:00.000] 1 1\n[2024-03-12 09:40:00.000] 2 1 10:01:30.000\n"+
+		"[2024-03-12 10:01:30.500] 4 1\n[2024-03-12 10:12:30.000] 10 1\n[2024-03-12 10:24:00.000] 10 1\n"), 0o644))
+	for _, start := range []string{"10:00:00.000", "2024-03-12 10:00:00.000"} {
+		config := writeConfig(t, `{`+strings.Replace(baseConfigFields, `"10:00:00.000"`, `"`+start+`"`, 1)+`}`)
+		r, err := loadRace(config, "", path, false)
+		require.NoError(t, err, start)
+		require.Equal(t, time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC), r.baseStart, start)
+		var out bytes.Buffer
+		p := newProcessor(r, nil, &out)
+		require.NoError(t, p.ProcessAll(r.events))
+		require.Contains(t, out.String(), "[2024-03-12 09:40:00.000] The start time for the competitor(1) was set by a draw to 10:01:30.000 (slot #2)\n")
+		res := p.Results()
+		require.Equal(t, StatusFinished, res[0].Status)
+		require.Equal(t, 22*time.Minute+30*time.Second, res[0].Total)
 	}
+
+	config := writeConfig(t, `{`+strings.Replace(baseConfigFields, `"10:00:00.000"`, `"2024-03-12 10:00:00.000"`, 1)+`}`)
+	_, err := loadRace(config, "", "events", false)
+	require.EqualError(t, err, "events error: config start 2024-03-12 10:00:00.000 has a date, but the events don't")
 }
 
-func TestEventRegexGroups(t *testing.T) {
-	line := "[12:34:56.789] 5 10 extra params"
-	matches := eventRegex.FindStringSubmatch(line)
-	expected := []string{"[12:34:56.789] 5 10 extra params", "12:34:56.789", "5", "10", "extra params"}
-	require.Equal(t, expected, matches)
+// TestDatedRaceInputs runs a dated log with a jury decision, an incident and
+// a bulletin given as clock times, which must fall on the day of the race.
+func TestDatedRaceInputs(t *testing.T) {
+	dir := t.TempDir()
+	events := filepath.Join(dir, "events")
+	require.NoError(t, os.WriteFile(events, []byte("[2024-03-12 09:30:00.000] 1 1\n[2024-03-12 09:40:00.000] 2 1 10:01:30.000\n"+
+		"[2024-03-12 10:01:30.500] 4 1\n[2024-03-12 10:12:30.000] 10 1\n[2024-03-12 10:24:00.000] 10 1\n"), 0o644))
+	decisions := filepath.Join(dir, "decisions.json")
+	require.NoError(t, os.WriteFile(decisions, []byte(`{"startCompensations": [{"from": "10:01:00.000", "to": "10:02:00.000", "correction": "00:00:05"}]}`), 0o644))
+	incidents := filepath.Join(dir, "incidents")
+	require.NoError(t, os.WriteFile(incidents, []byte("[10:15:00.000] 1 fall downhill 2\n"), 0o644))
+	config := writeConfig(t, "{"+baseConfigFields+"}")
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, Run([]string{"-config", config, "-events", events, "-decisions", decisions, "-incidents", incidents,
+		"-bulletin-at", "10:05", "-bulletin-dir", dir}, &stdout, &bytes.Buffer{}))
+	require.Contains(t, stdout.String(), "\nStart compensations:\nCompetitor 1: 00:00:05.000\n")
+	require.Contains(t, stdout.String(), "[10:15:00.000] Incident for competitor(1) on lap 2: fall: downhill 2\n")
+	bulletin, err := os.ReadFile(filepath.Join(dir, "bulletin-01.txt"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(bulletin), "Intermediate bulletin as of 10:05:00.000, 1 competitors on course\n"), string(bulletin))
 }
@@ -0,0 +1,75 @@
+package biathlon
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonResult is the JSON form of a Result. Durations are whole
+// milliseconds and speeds are in m/s.
+type jsonResult struct {
+	Place         int               `json:"place,omitempty"`
+	Competitor    string            `json:"competitor"`
+	Status        Status            `json:"status"`
+	TotalMs       *int64            `json:"totalMs,omitempty"`
+	LapsCompleted int               `json:"lapsCompleted"`
+	Laps          []jsonLapResult   `json:"laps"`
+	Penalties     []jsonLapResult   `json:"penalties"`
+	Hits          int               `json:"hits"`
+	Shots         int               `json:"shots"`
+	Misses        int               `json:"misses"`
+	RangeTimesMs  []int64           `json:"rangeTimesMs"`
+	RangeMs       int64             `json:"rangeMs"`
+	CourseMs      *int64            `json:"courseMs,omitempty"`
+	PhotoFinish   bool              `json:"photoFinish,omitempty"`
+	Reason        string            `json:"reason,omitempty"`
+	ReasonFields  map[string]string `json:"reasonFields,omitempty"`
+}
+
+type jsonLapResult struct {
+	DurationMs int64   `json:"durationMs"`
+	Speed      float64 `json:"speed"`
+	ClimbSpeed float64 `json:"climbSpeed,omitempty"`
+}
+
+// renderJSON writes the results as an indented JSON array.
+func renderJSON(w io.Writer, results []Result, _ reportStyle) error {
+	all := make([]jsonResult, len(results))
+	for i, r := range results {
+		j := jsonResult{
+			Place:         r.Place,
+			Competitor:    r.Bib.String(),
+			Status:        r.Status,
+			LapsCompleted: r.LapsCompleted,
+			Laps:          make([]jsonLapResult, len(r.Laps)),
+			Penalties:     make([]jsonLapResult, len(r.Penalties)),
+			Hits:          r.Hits,
+			Shots:         r.Shots,
+			Misses:        r.Misses,
+			RangeTimesMs:  make([]int64, len(r.RangeTimes)),
+			RangeMs:       r.RangeTime.Milliseconds(),
+			PhotoFinish:   r.PhotoFinish,
+			Reason:        r.Reason,
+			ReasonFields:  r.ReasonFields,
+		}
+		if r.Status == StatusFinished {
+			total := r.Total.Milliseconds()
+			j.TotalMs = &total
+			course := r.CourseTime.Milliseconds()
+			j.CourseMs = &course
+		}
+		for k, t := range r.RangeTimes {
+			j.RangeTimesMs[k] = t.Milliseconds()
+		}
+		for k, lap := range r.Laps {
+			j.Laps[k] = jsonLapResult{DurationMs: lap.Time.Milliseconds(), Speed: lap.Speed, ClimbSpeed: lap.ClimbSpeed}
+		}
+		for k, lap := range r.Penalties {
+			j.Penalties[k] = jsonLapResult{DurationMs: lap.Time.Milliseconds(), Speed: lap.Speed}
+		}
+		all[i] = j
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(all)
+}
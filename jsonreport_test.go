@@ -0,0 +1,65 @@
+package biathlon
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderJSON(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	require.NoError(t, renderJSON(&out, []Result{resultFixture, {Bib: Bib{Number: 3}, Status: StatusNotStarted, Shots: 10}}, reportStyle{locale: locales["en"]}))
+	var got []jsonResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	require.Len(t, got, 2)
+
+	require.Equal(t, "7b", got[0].Competitor)
+	require.Equal(t, StatusFinished, got[0].Status)
+	require.Equal(t, int64(1526047), *got[0].TotalMs)
+	require.Equal(t, []jsonLapResult{{DurationMs: 721000, Speed: 4.85, ClimbSpeed: 5.12}, {DurationMs: 719000, Speed: 4.87, ClimbSpeed: 5.01}}, got[0].Laps)
+	require.Equal(t, []jsonLapResult{{DurationMs: 29000, Speed: 5.17}}, got[0].Penalties)
+	require.Equal(t, 8, got[0].Hits)
+	require.Equal(t, 10, got[0].Shots)
+	require.Equal(t, []int64{31200, 28400}, got[0].RangeTimesMs)
+	require.Equal(t, int64(59600), got[0].RangeMs)
+	require.Equal(t, int64(1466447), *got[0].CourseMs)
+	require.True(t, got[0].PhotoFinish)
+	require.Equal(t, "Lost a ski", got[0].Reason)
+	require.Equal(t, map[string]string{"reason": "Lost a ski", "medic": "yes"}, got[0].ReasonFields)
+
+	require.Nil(t, got[1].TotalMs)
+	require.Nil(t, got[1].CourseMs)
+	require.Equal(t, []jsonLapResult{}, got[1].Laps)
+}
+
+func TestRunFormatJSON(t *testing.T) {
+	dir := t.TempDir()
+	var reports [][]byte
+	for i := 0; i < 2; i++ {
+		path := filepath.Join(dir, "results.json")
+		require.Equal(t, 0, Run([]string{"-format", "json", "-out", path}, &bytes.Buffer{}, &bytes.Buffer{}))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		reports = append(reports, data)
+	}
+	require.Equal(t, string(reports[0]), string(reports[1]))
+
+	var got []jsonResult
+	require.NoError(t, json.Unmarshal(reports[0], &got))
+	require.Len(t, got, 5)
+	for i, r := range got {
+		require.Equal(t, i+1, r.Place)
+	}
+	require.Equal(t, "2", got[0].Competitor)
+	require.Equal(t, int64(1518356), *got[0].TotalMs)
+	require.Equal(t, 2, got[0].LapsCompleted)
+
+	var stdout bytes.Buffer
+	require.Equal(t, 1, Run([]string{"-format", "xml"}, &stdout, &bytes.Buffer{}))
+	require.Contains(t, stdout.String(), `unknown format "xml", expected one of accessible, html, json, markdown, text`)
+}
@@ -0,0 +1,65 @@
+package biathlon
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// The plausible average speed band of a main lap, in m/s, range stops
+// included. Roughly a third to twice the speed of an elite skier.
+const (
+	minLapSpeed = 2.0
+	maxLapSpeed = 12.0
+)
+
+// medianLapTime returns the median of the main lap times in events: from the
+// actual start to the first lap end, and between consecutive lap ends. ok is
+// false when no lap was completed.
+func medianLapTime(events []Event) (median time.Duration, ok bool) {
+	last := make(map[Bib]time.Time)
+	var laps []time.Duration
+	for _, e := range events {
+		switch e.EventID {
+		case isStarted:
+			last[e.Bib()] = e.Time
+		case endedTheMainLap:
+			if from, started := last[e.Bib()]; started {
+				laps = append(laps, e.Time.Sub(from))
+			}
+			last[e.Bib()] = e.Time
+		}
+	}
+	if len(laps) == 0 {
+		return 0, false
+	}
+	sort.Slice(laps, func(i, j int) bool { return laps[i] < laps[j] })
+	if len(laps)%2 == 1 {
+		return laps[len(laps)/2], true
+	}
+	return (laps[len(laps)/2-1] + laps[len(laps)/2]) / 2, true
+}
+
+// lapLenWarning checks lapLen against the median lap time of the field. It
+// returns a warning line when the implied speed is outside the plausible
+// band, with the likely intended lapLen when a factor of 10 brings the speed
+// back into the band.
+func lapLenWarning(events []Event, lapLen int) (line string, ok bool) {
+	median, ok := medianLapTime(events)
+	if !ok || median <= 0 || lapLen <= 0 {
+		return "", false
+	}
+	speed := float64(lapLen) / median.Seconds()
+	if speed >= minLapSpeed && speed <= maxLapSpeed {
+		return "", false
+	}
+	line = fmt.Sprintf("WARNING: lapLen=%d implies %.2f m/s over the median lap time of %s, outside %.0f-%.0f m/s",
+		lapLen, speed, formatDuration(median), minLapSpeed, maxLapSpeed)
+	switch {
+	case speed < minLapSpeed && speed*10 >= minLapSpeed && speed*10 <= maxLapSpeed:
+		line += fmt.Sprintf("; did you mean lapLen=%d?", lapLen*10)
+	case speed > maxLapSpeed && speed/10 >= minLapSpeed && speed/10 <= maxLapSpeed && lapLen%10 == 0:
+		line += fmt.Sprintf("; did you mean lapLen=%d?", lapLen/10)
+	}
+	return line, true
+}
@@ -0,0 +1,44 @@
+package biathlon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLapLenWarning(t *testing.T) {
+	t.Parallel()
+	at := func(s string) time.Time {
+		tm, err := time.Parse(timeLayout, s)
+		require.NoError(t, err)
+		return tm
+	}
+	// Laps of 7:00, 7:30 and 8:00: a median of 7:30.
+	events := []Event{
+		{Time: at("10:00:00.000"), EventID: isStarted, CompetitorID: 1},
+		{Time: at("10:01:00.000"), EventID: isStarted, CompetitorID: 2},
+		{Time: at("10:07:00.000"), EventID: endedTheMainLap, CompetitorID: 1},
+		{Time: at("10:08:30.000"), EventID: endedTheMainLap, CompetitorID: 2},
+		{Time: at("10:15:00.000"), EventID: endedTheMainLap, CompetitorID: 1},
+	}
+
+	median, ok := medianLapTime(events)
+	require.True(t, ok)
+	require.Equal(t, 7*time.Minute+30*time.Second, median)
+
+	_, ok = lapLenWarning(events, 3000)
+	require.False(t, ok)
+
+	line, ok := lapLenWarning(events, 300)
+	require.True(t, ok)
+	require.Equal(t, "WARNING: lapLen=300 implies 0.67 m/s over the median lap time of 00:07:30.000, "+
+		"outside 2-12 m/s; did you mean lapLen=3000?", line)
+
+	line, ok = lapLenWarning(events, 30000)
+	require.True(t, ok)
+	require.Contains(t, line, "did you mean lapLen=3000?")
+
+	_, ok = lapLenWarning(nil, 300)
+	require.False(t, ok)
+}
@@ -0,0 +1,70 @@
+package biathlon
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// locale controls how numbers and durations are written in the text report.
+// Machine-readable outputs always use the dot as the decimal separator.
+type locale struct {
+	decimal   string
+	speedUnit string
+}
+
+var locales = map[string]locale{
+	"en": {decimal: ".", speedUnit: ""},
+	"ru": {decimal: ",", speedUnit: " м/с"},
+}
+
+func lookupLocale(name string) (locale, error) {
+	l, ok := locales[name]
+	if !ok {
+		names := make([]string, 0, len(locales))
+		for n := range locales {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return locale{}, fmt.Errorf("unknown locale %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return l, nil
+}
+
+// number formats f with prec digits after the decimal separator.
+func (l locale) number(f float64, prec int) string {
+	return strings.Replace(strconv.FormatFloat(f, 'f', prec, 64), ".", l.decimal, 1)
+}
+
+// speed formats a speed in m/s with the locale's unit label.
+func (l locale) speed(f float64) string {
+	return l.number(f, 3) + l.speedUnit
+}
+
+// duration formats d like event times, e.g. "00:24:31.200".
+func (l locale) duration(d time.Duration) string {
+	return strings.Replace(formatDuration(d), ".", l.decimal, 1)
+}
+
+// total formats a total race time like a table cell, e.g. "24:31.2".
+func (l locale) total(d time.Duration) string {
+	return l.clock(d)
+}
+
+// clock formats d compactly for table cells: minutes and seconds, the hours
+// only when there are any and the milliseconds without trailing zeros,
+// e.g. "07:05" or "1:02:31.2".
+func (l locale) clock(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	h, m, s := d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second
+	out := fmt.Sprintf("%02d:%02d", m, s)
+	if h > 0 {
+		out = fmt.Sprintf("%d:%s", h, out)
+	}
+	if ms := d % time.Second / time.Millisecond; ms > 0 {
+		out += l.decimal + strings.TrimRight(fmt.Sprintf("%03d", ms), "0")
+	}
+	return out
+}
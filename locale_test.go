@@ -0,0 +1,74 @@
+package biathlon
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocaleFormatting(t *testing.T) {
+	t.Parallel()
+	d := 24*time.Minute + 31*time.Second + 200*time.Millisecond
+	tests := []struct {
+		name             string
+		locale           string
+		expectedDuration string
+		expectedTotal    string
+		expectedSpeed    string
+	}{
+		{
+			name:             "test_en",
+			locale:           "en",
+			expectedDuration: "00:24:31.200",
+			expectedTotal:    "24:31.2",
+			expectedSpeed:    "7.342",
+		},
+		{
+			name:             "test_ru",
+			locale:           "ru",
+			expectedDuration: "00:24:31,200",
+			expectedTotal:    "24:31,2",
+			expectedSpeed:    "7,342 м/с",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			loc, err := lookupLocale(test.locale)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedDuration, loc.duration(d))
+			require.Equal(t, test.expectedTotal, loc.total(d))
+			require.Equal(t, test.expectedSpeed, loc.speed(7.3421))
+		})
+	}
+}
+
+func TestLookupUnknownLocale(t *testing.T) {
+	_, err := lookupLocale("de")
+	require.ErrorContains(t, err, "en, ru")
+}
+
+func TestPrintResultsLocale(t *testing.T) {
+	start, _ := time.Parse(timeLayout, "10:00:00.000")
+	lap := 24*time.Minute + 31*time.Second + 200*time.Millisecond
+	competitors := map[Bib]*Competitor{
+		{Number: 1}: {
+			ID:            1,
+			Started:       true,
+			LapsCompleted: 1,
+			Hits:          5,
+			StartTime:     start,
+			FinishTime:    start.Add(lap),
+			lapTimes:      []time.Duration{lap},
+		},
+	}
+	cfg := Config{Laps: 1, LapLen: 3500, TargetsPerLine: 5}
+
+	var en, ru bytes.Buffer
+	printResults(&en, competitors, cfg, nil, reportStyle{locale: locales["en"]})
+	printResults(&ru, competitors, cfg, nil, reportStyle{locale: locales["ru"]})
+	require.Contains(t, en.String(), "24:31.2 Competitor 1: laps count 1, laps [{00:24:31.200, 2.379}]")
+	require.Contains(t, ru.String(), "24:31,2 Competitor 1: laps count 1, laps [{00:24:31,200, 2,379 м/с}]")
+}
@@ -0,0 +1,133 @@
+package biathlon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// LogLevel is the severity of a commentary record.
+type LogLevel int
+
+const (
+	// LevelInfo is the narration of the events.
+	LevelInfo LogLevel = iota
+	// LevelWarn is a warning or alert about a suspicious event.
+	LevelWarn
+	// LevelError is a problem parsing an event, such as a malformed extra
+	// param or an unknown event id.
+	LevelError
+)
+
+// logLevels are the level names of the -log-level flag.
+var logLevels = map[string]LogLevel{"info": LevelInfo, "warn": LevelWarn, "error": LevelError}
+
+func (l LogLevel) String() string {
+	for name, level := range logLevels {
+		if level == l {
+			return name
+		}
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// parseLogLevel returns the level called name.
+func parseLogLevel(name string) (LogLevel, error) {
+	level, ok := logLevels[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown log level %q, expected info, warn or error", name)
+	}
+	return level, nil
+}
+
+// LogRecord is one line of the race commentary. Time, EventID and
+// Competitor are zero for a record about no event, such as a config
+// warning; an alert has the time it was raised but no event.
+type LogRecord struct {
+	Level      LogLevel
+	Time       time.Time
+	EventID    int
+	Competitor Bib
+	Message    string
+}
+
+// Logger receives the race commentary, one record per line.
+type Logger interface {
+	Log(r LogRecord)
+}
+
+// textLogger writes the records at or above level to w as commentary
+// lines, each headed by its time.
+type textLogger struct {
+	w     io.Writer
+	level LogLevel
+}
+
+func (l textLogger) Log(r LogRecord) {
+	if r.Level < l.level {
+		return
+	}
+	if r.Time.IsZero() {
+		fmt.Fprintln(l.w, r.Message)
+		return
+	}
+	fmt.Fprintf(l.w, "[%s] %s\n", formatStamp(r.Time), r.Message)
+}
+
+// jsonLogger writes the records at or above level to w as one JSON object
+// per line.
+type jsonLogger struct {
+	enc   *json.Encoder
+	level LogLevel
+}
+
+func newJSONLogger(w io.Writer, level LogLevel) jsonLogger {
+	return jsonLogger{enc: json.NewEncoder(w), level: level}
+}
+
+// jsonRecord is the JSON form of a LogRecord; the fields about the event
+// are left out of a record about none.
+type jsonRecord struct {
+	Time         string `json:"time,omitempty"`
+	Level        string `json:"level"`
+	EventID      int    `json:"eventID,omitempty"`
+	CompetitorID string `json:"competitorID,omitempty"`
+	Message      string `json:"message"`
+}
+
+func (l jsonLogger) Log(r LogRecord) {
+	if r.Level < l.level {
+		return
+	}
+	j := jsonRecord{Level: r.Level.String(), EventID: r.EventID, Message: r.Message}
+	if !r.Time.IsZero() {
+		j.Time = formatStamp(r.Time)
+	}
+	if r.Competitor != (Bib{}) {
+		j.CompetitorID = r.Competitor.String()
+	}
+	_ = l.enc.Encode(j)
+}
+
+// eventRecord is a record about event e.
+func eventRecord(level LogLevel, e Event, message string) LogRecord {
+	return LogRecord{Level: level, Time: e.Time, EventID: e.EventID, Competitor: e.Bib(), Message: message}
+}
+
+// narrate logs a commentary line about event e, as made by logf.
+func (p *Processor) narrate(e Event, line LogLine) {
+	p.log.Log(eventRecord(LevelInfo, e, strings.TrimPrefix(string(line), "["+e.RawTime+"] ")))
+}
+
+// warn logs a warning or alert and counts it.
+func (p *Processor) warn(r LogRecord) {
+	p.warnings++
+	p.log.Log(r)
+}
+
+// warnEvent logs warning w about event e at level and counts it.
+func (p *Processor) warnEvent(level LogLevel, e Event, w Warning) {
+	p.warn(eventRecord(level, e, warningMessage(e, w)))
+}
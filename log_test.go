@@ -0,0 +1,97 @@
+package biathlon
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordLogger keeps the records logged to it.
+type recordLogger struct {
+	records []LogRecord
+}
+
+func (l *recordLogger) Log(r LogRecord) {
+	l.records = append(l.records, r)
+}
+
+// TestLogLevels requires the narration to be logged at the info level, the
+// warnings at warn and the events that couldn't be parsed at error.
+func TestLogLevels(t *testing.T) {
+	r := newTestRace(t, "[09:30:00.000] 1 1", "[09:45:00.000] 2 1 10:00:00.000", "[10:00:00.500] 4 1",
+		"[10:05:00.000] 6 1 1", "[10:06:00.000] 42 1", "[10:12:40.000] 10 1")
+	var log recordLogger
+	p := newLoggingProcessor(r, nil, &log)
+	require.NoError(t, p.ProcessAll(r.events))
+
+	at := func(clock string) time.Time {
+		t.Helper()
+		ts, err := time.Parse(timeLayout, clock)
+		require.NoError(t, err)
+		return ts
+	}
+	one := Bib{Number: 1}
+	require.Equal(t, LogRecord{Level: LevelInfo, Time: at("09:30:00.000"), EventID: register, Competitor: one, Message: "The competitor(1) registered"}, log.records[0])
+	require.Contains(t, log.records, LogRecord{Level: LevelWarn, Time: at("10:05:00.000"), EventID: hit, Competitor: one,
+		Message: "Warning for competitor(1): hit_outside_bout: hit outside of a firing range visit"})
+	require.Contains(t, log.records, LogRecord{Level: LevelError, Time: at("10:06:00.000"), EventID: 42, Competitor: one,
+		Message: "Unknown EventId 42. The EventID must be in the range [1, 15]"})
+}
+
+func TestTextLoggerLevel(t *testing.T) {
+	t.Parallel()
+	ts, err := time.Parse(timeLayout, "10:00:00.000")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	log := textLogger{w: &out, level: LevelWarn}
+	log.Log(LogRecord{Level: LevelInfo, Time: ts, Message: "narration"})
+	log.Log(LogRecord{Level: LevelWarn, Time: ts, Message: "warning"})
+	log.Log(LogRecord{Level: LevelError, Message: "no event"})
+	require.Equal(t, "[10:00:00.000] warning\nno event\n", out.String())
+}
+
+func TestJSONLogger(t *testing.T) {
+	t.Parallel()
+	ts, err := time.Parse(timeLayout, "10:08:49.289")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	log := newJSONLogger(&out, LevelInfo)
+	log.Log(LogRecord{Level: LevelInfo, Time: ts, EventID: onTheFiringRange, Competitor: Bib{Number: 7, Suffix: "b"}, Message: "on the range"})
+	log.Log(LogRecord{Level: LevelWarn, Message: "no event"})
+	require.Equal(t, `{"time":"10:08:49.289","level":"info","eventID":5,"competitorID":"7b","message":"on the range"}`+"\n"+
+		`{"level":"warn","message":"no event"}`+"\n", out.String())
+}
+
+func TestParseLogLevel(t *testing.T) {
+	t.Parallel()
+	for _, level := range []LogLevel{LevelInfo, LevelWarn, LevelError} {
+		parsed, err := parseLogLevel(level.String())
+		require.NoError(t, err)
+		require.Equal(t, level, parsed)
+	}
+	_, err := parseLogLevel("debug")
+	require.EqualError(t, err, `unknown log level "debug", expected info, warn or error`)
+}
+
+func TestRunLogJSON(t *testing.T) {
+	var stdout bytes.Buffer
+	require.Equal(t, 0, Run([]string{"-log-json", "-log-level=warn"}, &stdout, &bytes.Buffer{}))
+	commentary, _, found := strings.Cut(stdout.String(), "\nFinal results:\n")
+	require.True(t, found, stdout.String())
+	for _, line := range strings.Split(strings.TrimSuffix(commentary, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record), line)
+		require.NotEqual(t, "info", record["level"], line)
+	}
+
+	stdout.Reset()
+	require.Equal(t, 1, Run([]string{"-log-level=debug"}, &stdout, &bytes.Buffer{}))
+	require.Contains(t, stdout.String(), `unknown log level "debug"`)
+}
@@ -0,0 +1,110 @@
+package biathlon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"BiathlonCompetitions/parser"
+)
+
+// Manifest records how a report was produced, so that it can be
+// reproduced and audited.
+type Manifest struct {
+	Inputs []ManifestInput   `json:"inputs"`
+	Flags  map[string]string `json:"flags"`
+	Config Config            `json:"config"`
+	// Reconstructions are the lap ends synthesized by -reconstruct and the
+	// gaps it left alone.
+	Reconstructions []Reconstruction `json:"reconstructions,omitempty"`
+	// Respaces are the start slot collisions -respace moved a competitor
+	// for or left flagged.
+	Respaces    []Respace `json:"respaces,omitempty"`
+	Build       BuildInfo `json:"build"`
+	ProcessedAt time.Time `json:"processedAt"`
+}
+
+// ManifestInput is an input file with the digest of its content. Events
+// read from stdin have no digest.
+type ManifestInput struct {
+	Role   string `json:"role"`
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// newManifest describes a run over r loaded with the race options o, using
+// the flag values of fs. Every output path builds its manifest here.
+func newManifest(o raceOptions, r race, fs *flag.FlagSet, now time.Time) (Manifest, error) {
+	m := Manifest{
+		Flags:           map[string]string{},
+		Config:          r.cfg,
+		Reconstructions: r.reconstructions,
+		Respaces:        r.respaces,
+		Build:           currentBuild(),
+		ProcessedAt:     now.UTC(),
+	}
+	inputs := [][2]string{{"config", o.configPath}, {"events", o.eventsPath}}
+	if r.cfg.Profile != "" {
+		inputs = append(inputs, [2]string{"profile", profilePath(r.cfg, o.configPath)})
+	}
+	if o.decisions != "" {
+		inputs = append(inputs, [2]string{"decisions", o.decisions})
+	}
+	if o.incidents != "" {
+		inputs = append(inputs, [2]string{"incidents", o.incidents})
+	}
+	for _, in := range inputs {
+		if in[1] == parser.StdinPath {
+			m.Inputs = append(m.Inputs, ManifestInput{Role: in[0], Path: in[1]})
+			continue
+		}
+		sum, err := fileSHA256(in[1])
+		if err != nil {
+			return Manifest{}, err
+		}
+		m.Inputs = append(m.Inputs, ManifestInput{Role: in[0], Path: in[1], SHA256: sum})
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		m.Flags[f.Name] = f.Value.String()
+	})
+	return m, nil
+}
+
+func fileSHA256(path string) (sum string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func(f *os.File) {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeManifest writes m as indented JSON to path.
+func writeManifest(path string, m Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// printManifestFooter summarizes m at the end of the report.
+func printManifestFooter(w io.Writer, m Manifest) {
+	fmt.Fprintf(w, "\nProduced by biathlon %s at %s\n", m.Build, m.ProcessedAt.Format(time.RFC3339))
+	for _, in := range m.Inputs {
+		fmt.Fprintf(w, "%s: %s sha256:%s\n", in.Role, in.Path, in.SHA256)
+	}
+}
@@ -0,0 +1,65 @@
+package biathlon
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewManifest(t *testing.T) {
+	o := raceOptions{configPath: "config/config.json", eventsPath: "events"}
+	r, err := loadRace(o.configPath, o.configFormat, o.eventsPath, false)
+	require.NoError(t, err)
+	fs := flag.NewFlagSet("process", flag.ContinueOnError)
+	fs.String("locale", "en", "")
+	require.NoError(t, fs.Parse([]string{"-locale", "ru"}))
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	m, err := newManifest(o, r, fs, now)
+	require.NoError(t, err)
+	require.Len(t, m.Inputs, 2)
+	require.Equal(t, "config", m.Inputs[0].Role)
+	require.Equal(t, "events", m.Inputs[1].Role)
+	sum, err := fileSHA256("events")
+	require.NoError(t, err)
+	require.Equal(t, sum, m.Inputs[1].SHA256)
+	require.Len(t, m.Inputs[1].SHA256, 64)
+	require.Equal(t, map[string]string{"locale": "ru"}, m.Flags)
+	require.Equal(t, r.cfg, m.Config)
+	require.Equal(t, currentBuild(), m.Build)
+	require.Equal(t, now, m.ProcessedAt)
+
+	again, err := newManifest(o, r, fs, now.Add(time.Hour))
+	require.NoError(t, err)
+	again.ProcessedAt = m.ProcessedAt
+	require.Equal(t, m, again)
+}
+
+func TestNewManifestMissingInput(t *testing.T) {
+	o := raceOptions{configPath: "config/config.json", eventsPath: "events", decisions: filepath.Join(t.TempDir(), "missing.json")}
+	r := newTestRace(t)
+	_, err := newManifest(o, r, flag.NewFlagSet("process", flag.ContinueOnError), time.Now())
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRunWritesManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	var stdout bytes.Buffer
+	require.Equal(t, 0, Run([]string{"-manifest", path}, &stdout, &bytes.Buffer{}))
+	require.Contains(t, stdout.String(), "\nProduced by biathlon "+currentBuild().String())
+	require.Contains(t, stdout.String(), "events: events sha256:")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var m Manifest
+	require.NoError(t, json.Unmarshal(data, &m))
+	require.Equal(t, path, m.Flags["manifest"])
+	require.Equal(t, "en", m.Flags["locale"])
+	require.Len(t, m.Inputs, 2)
+}
@@ -0,0 +1,75 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// markdownStatuses are the status abbreviations of the Markdown table.
+var markdownStatuses = map[Status]string{
+	StatusFinished:     "",
+	StatusLapped:       "LAP",
+	StatusNotFinished:  "DNF",
+	StatusDisqualified: "DSQ",
+	StatusNotStarted:   "DNS",
+}
+
+// markdownNone fills the time cells of a competitor without the time.
+const markdownNone = "—"
+
+// renderMarkdown writes the results as a Markdown table for pasting into a
+// wiki. The laps, penalty loops and range visits of a competitor share a
+// cell each, their times separated by slashes.
+func renderMarkdown(w io.Writer, results []Result, style reportStyle) error {
+	lines := []string{
+		"| Place | Competitor | Status | Total | Laps | Lap times | Lap speeds, m/s (climb adjusted) | Penalty loops | Hits | Misses | Range | Course |",
+		"|---:|---|---|---:|---:|---|---|---|---:|---:|---|---:|",
+	}
+	for _, r := range results {
+		place := ""
+		if r.Place > 0 {
+			place = fmt.Sprint(r.Place)
+		}
+		if r.PhotoFinish {
+			place += " (photo finish)"
+		}
+		status, ok := markdownStatuses[r.Status]
+		if !ok {
+			status = string(r.Status)
+		}
+		if r.Reason != "" {
+			status = strings.TrimSpace(status + " (" + strings.ReplaceAll(reasonNote(r), "|", `\|`) + ")")
+		}
+		total, course := markdownNone, markdownNone
+		if r.Status == StatusFinished {
+			total, course = style.clock(r.Total), style.clock(r.CourseTime)
+		}
+		lapTimes := make([]string, len(r.Laps))
+		lapSpeeds := make([]string, len(r.Laps))
+		for i, lap := range r.Laps {
+			lapTimes[i] = style.clock(lap.Time)
+			lapSpeeds[i] = style.number(lap.Speed, 2)
+			if lap.ClimbSpeed != 0 {
+				lapSpeeds[i] += " (" + style.number(lap.ClimbSpeed, 2) + ")"
+			}
+		}
+		penalties := make([]string, len(r.Penalties))
+		for i, lap := range r.Penalties {
+			penalties[i] = fmt.Sprintf("%s at %s", style.clock(lap.Time), style.number(lap.Speed, 2))
+		}
+		rangeTimes := make([]string, len(r.RangeTimes))
+		for i, t := range r.RangeTimes {
+			rangeTimes[i] = style.clock(t)
+		}
+		rangeCell := style.clock(r.RangeTime)
+		if len(rangeTimes) > 0 {
+			rangeCell += " (" + strings.Join(rangeTimes, " / ") + ")"
+		}
+		cells := []string{place, r.Bib.String(), status, total, fmt.Sprint(r.LapsCompleted), strings.Join(lapTimes, " / "),
+			strings.Join(lapSpeeds, " / "), strings.Join(penalties, " / "), fmt.Sprintf("%d/%d", r.Hits, r.Shots), fmt.Sprint(r.Misses), rangeCell, course}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
@@ -0,0 +1,54 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// mirrorStats summarizes the pairing of a log written by two timing systems.
+type mirrorStats struct {
+	Pairs int
+	// Unpaired are the events only one of the systems recorded.
+	Unpaired []Event
+}
+
+// dedupeMirrored pairs every event with its near-duplicate from the other
+// timing system: same event, competitor and extra params, recorded at most
+// window later. The earlier event of each pair is kept. events must be
+// sorted by time; the result keeps that order and includes unpaired events.
+func dedupeMirrored(events []Event, window time.Duration) ([]Event, mirrorStats) {
+	var stats mirrorStats
+	paired := make([]bool, len(events))
+	var kept []Event
+	for i, e := range events {
+		if paired[i] {
+			continue
+		}
+		kept = append(kept, e)
+		mirrored := false
+		for j := i + 1; j < len(events) && events[j].Time.Sub(e.Time) <= window; j++ {
+			m := events[j]
+			if !paired[j] && m.EventID == e.EventID && m.Bib() == e.Bib() && m.Extra == e.Extra {
+				paired[j] = true
+				mirrored = true
+				break
+			}
+		}
+		if mirrored {
+			stats.Pairs++
+		} else {
+			stats.Unpaired = append(stats.Unpaired, e)
+		}
+	}
+	return kept, stats
+}
+
+func printMirrorStats(w io.Writer, stats mirrorStats) {
+	fmt.Fprintf(w, "Mirrored log: %d paired events, %d unpaired\n", stats.Pairs, len(stats.Unpaired))
+	for _, e := range stats.Unpaired {
+		line := strings.TrimSpace(fmt.Sprintf("[%s] Unpaired event %d for competitor(%s) %s", e.RawTime, e.EventID, e.Bib(), e.Extra))
+		fmt.Fprintln(w, line+eventRef(e.Seq))
+	}
+}
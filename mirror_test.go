@@ -0,0 +1,94 @@
+package biathlon
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"BiathlonCompetitions/parser"
+)
+
+// mirror returns events as recorded by a primary and a backup timing system
+// whose clock runs offset behind, sorted by time.
+func mirror(t *testing.T, lines []string, offset time.Duration) []Event {
+	t.Helper()
+	var events []Event
+	for _, line := range lines {
+		e, err := parser.ParseEvent(line)
+		require.NoError(t, err)
+		backup := e
+		backup.Time = e.Time.Add(offset)
+		backup.RawTime = backup.Time.Format(timeLayout)
+		events = append(events, e, backup)
+	}
+	return events
+}
+
+var mirrorFixture = []string{
+	"[09:31:49.285] 1 1",
+	"[09:55:00.000] 2 1 10:00:00.000",
+	"[10:00:01.744] 4 1",
+	"[10:08:49.289] 5 1 1",
+	"[10:08:50.884] 6 1 1",
+	"[10:08:51.400] 6 1 2",
+	"[10:08:55.658] 7 1",
+}
+
+func TestDedupeMirroredFully(t *testing.T) {
+	events := mirror(t, mirrorFixture, 120*time.Millisecond)
+
+	kept, stats := dedupeMirrored(events, 250*time.Millisecond)
+	require.Equal(t, len(mirrorFixture), stats.Pairs)
+	require.Empty(t, stats.Unpaired)
+	require.Len(t, kept, len(mirrorFixture))
+	for i, e := range kept {
+		require.Equal(t, mirrorFixture[i][1:13], e.RawTime, "the earlier record is kept")
+	}
+}
+
+func TestDedupeMirroredMissingEvent(t *testing.T) {
+	events := mirror(t, mirrorFixture, 120*time.Millisecond)
+	// The backup system missed the hit on target 2.
+	events = append(events[:11], events[12:]...)
+
+	kept, stats := dedupeMirrored(events, 250*time.Millisecond)
+	require.Equal(t, len(mirrorFixture)-1, stats.Pairs)
+	require.Len(t, stats.Unpaired, 1)
+	require.Equal(t, "10:08:51.400", stats.Unpaired[0].RawTime)
+	require.Equal(t, hit, stats.Unpaired[0].EventID)
+	require.Len(t, kept, len(mirrorFixture))
+}
+
+func TestDedupeMirroredOutsideWindow(t *testing.T) {
+	events := mirror(t, mirrorFixture[:1], time.Second)
+
+	kept, stats := dedupeMirrored(events, 250*time.Millisecond)
+	require.Zero(t, stats.Pairs)
+	require.Len(t, stats.Unpaired, 2)
+	require.Len(t, kept, 2)
+}
+
+// TestRunMirroredLapLen runs a mirrored log with two 8 minute laps: the lap
+// length check must see each lap end once, not a near-zero lap between the
+// two records of it.
+func TestRunMirroredLapLen(t *testing.T) {
+	var lines []string
+	for _, e := range mirror(t, []string{"[09:30:00.000] 1 1", "[09:45:00.000] 2 1 10:00:00.000", "[10:00:00.500] 4 1",
+		"[10:08:00.000] 10 1", "[10:16:00.000] 10 1"}, 20*time.Millisecond) {
+		lines = append(lines, strings.TrimSpace(fmt.Sprintf("[%s] %d %s %s", e.RawTime, e.EventID, e.Bib(), e.Extra)))
+	}
+	events := filepath.Join(t.TempDir(), "events")
+	require.NoError(t, os.WriteFile(events, []byte(strings.Join(lines, "\n")+"\n"), 0o644))
+	config := writeConfig(t, "{"+baseConfigFields+"}")
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, Run([]string{"-config", config, "-events", events, "-mirrored"}, &stdout, &bytes.Buffer{}))
+	require.NotContains(t, stdout.String(), "WARNING: lapLen")
+	require.Contains(t, stdout.String(), "1. 16:00 Competitor 1:")
+}
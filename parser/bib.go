@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const WarnNonPositiveCompetitor WarningCode = "non_positive_competitor"
+
+// Bib identifies a competitor by start number and an optional letter suffix.
+// Relay reserves get suffixed bibs such as "7b", which are distinct from the
+// plain "7"; purely numeric bibs have an empty suffix.
+type Bib struct {
+	Number int
+	Suffix string
+}
+
+func (b Bib) String() string {
+	return strconv.Itoa(b.Number) + b.Suffix
+}
+
+// Less orders bibs by number, a plain bib before its suffixed variants.
+func (b Bib) Less(other Bib) bool {
+	if b.Number != other.Number {
+		return b.Number < other.Number
+	}
+	return b.Suffix < other.Suffix
+}
+
+// ParseBib parses the competitor field of an event line, e.g. "007b".
+// Leading zeros are not significant and the suffix is case-insensitive.
+func ParseBib(s string) (Bib, error) {
+	digits := strings.TrimRightFunc(s, func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+	})
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return Bib{}, fmt.Errorf("invalid competitor %q", s)
+	}
+	return Bib{Number: n, Suffix: strings.ToLower(s[len(digits):])}, nil
+}
+
+// Bib returns the key of the competitor the event refers to.
+func (e Event) Bib() Bib {
+	return Bib{Number: e.CompetitorID, Suffix: e.Suffix}
+}
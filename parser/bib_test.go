@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEventBib(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		line        string
+		expectedBib Bib
+	}{
+		{
+			name:        "test_plain_bib",
+			line:        "[10:00:01.744] 4 7",
+			expectedBib: Bib{Number: 7},
+		},
+		{
+			name:        "test_leading_zeros",
+			line:        "[10:00:01.744] 4 007",
+			expectedBib: Bib{Number: 7},
+		},
+		{
+			name:        "test_suffixed_bib",
+			line:        "[10:00:01.744] 4 007b",
+			expectedBib: Bib{Number: 7, Suffix: "b"},
+		},
+		{
+			name:        "test_uppercase_suffix",
+			line:        "[10:00:01.744] 4 7B",
+			expectedBib: Bib{Number: 7, Suffix: "b"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			event, err := ParseEvent(test.line)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedBib, event.Bib())
+			require.Equal(t, test.expectedBib.Number, event.CompetitorID)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidEventLine is returned for an events line that can't be parsed.
+	ErrInvalidEventLine = errors.New("invalid event line")
+	// ErrMixedTimes is returned for an event line dated unlike the first
+	// line of its input: the times of one input all have a date or none.
+	ErrMixedTimes = errors.New("dated and undated event times mixed")
+	// ErrInvalidDelta is returned for a duration not in HH:MM:SS[.sss] format.
+	ErrInvalidDelta = errors.New("invalid delta")
+)
+
+// LineError is a malformed line of an events input.
+type LineError struct {
+	// Line is the 1-based line number.
+	Line int
+	Text string
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %q: %v", e.Line, e.Text, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
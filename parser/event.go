@@ -0,0 +1,303 @@
+// Package parser reads the event lines the timing system writes during a
+// biathlon race into typed events.
+package parser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is one line of the events file.
+type Event struct {
+	Time         time.Time
+	RawTime      string
+	EventID      int
+	CompetitorID int
+	Suffix       string
+	Extra        string
+	Payload      Payload
+	Warnings     []Warning
+	// Synthetic marks an event reconstructed from the surrounding
+	// checkpoints rather than recorded.
+	Synthetic bool
+	// Seq numbers the event within the race, from 1 in processing order. It
+	// is 0 for events that weren't numbered, such as synthetic ones.
+	Seq int
+	// Line is the 1-based line of the event in its file, 0 for an event
+	// that wasn't read from one. Unlike Seq it keeps the order the events
+	// were logged in when they share a time.
+	Line int
+}
+
+// TimeLayout is the format of event times, e.g. 09:30:01.005.
+const TimeLayout = "15:04:05.000"
+
+// DateTimeLayout is the format of event times with their date, e.g.
+// 2024-03-12 09:30:01.005, as multi-day logs write them.
+const DateTimeLayout = "2006-01-02 " + TimeLayout
+
+var eventRegex = regexp.MustCompile(`\[((?:\d{4}-\d{2}-\d{2} )?\d{2}:\d{2}:\d{2}\.\d{3})\] (\d+) (-?\d+[A-Za-z]?)(?: (.*))?`)
+
+// ParseTime parses a time in TimeLayout, or in DateTimeLayout when it
+// starts with a date. A time without a date falls on January 1 of year 0.
+func ParseTime(s string) (time.Time, error) {
+	if len(s) > len(TimeLayout) {
+		return time.Parse(DateTimeLayout, s)
+	}
+	return time.Parse(TimeLayout, s)
+}
+
+// Dated reports whether t was parsed with its date.
+func Dated(t time.Time) bool {
+	return t.Year() != 0
+}
+
+// Incoming event ids.
+const (
+	Undefined = iota
+	Register
+	StartTime
+	StartLine
+	IsStarted
+	OnTheFiringRange
+	Hit
+	LeftTheFiringRange
+	EnteredThePenaltyLaps
+	LeftThePenaltyLaps
+	EndedTheMainLap
+	Comment
+	Shot
+	CourseOpened
+	CourseClosed
+	Heartbeat
+)
+
+// RaceCompetitor is the competitor id of race-level events, which concern
+// the race rather than a competitor.
+const RaceCompetitor = 0
+
+// IsRaceEvent reports whether eventID is a race-level event: the course
+// opening by the forerunners, its closing or a heartbeat of the timing
+// system.
+func IsRaceEvent(eventID int) bool {
+	return eventID == CourseOpened || eventID == CourseClosed || eventID == Heartbeat
+}
+
+// ParseEvent parses a single event line, whose time may start with a date.
+// A malformed extra param doesn't fail the line; it is reported in the
+// event's Warnings instead.
+func ParseEvent(line string) (Event, error) {
+	matches := eventRegex.FindStringSubmatch(line)
+	if len(matches) < 4 {
+		return Event{}, fmt.Errorf("%w: want [HH:MM:SS.sss] eventID competitorID [extraParams]", ErrInvalidEventLine)
+	}
+	t, err := ParseTime(matches[1])
+	if err != nil {
+		return Event{}, fmt.Errorf("%w: %w", ErrInvalidEventLine, err)
+	}
+	eid, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return Event{}, fmt.Errorf("%w: event id: %w", ErrInvalidEventLine, err)
+	}
+	bib, err := ParseBib(matches[3])
+	if err != nil {
+		return Event{}, fmt.Errorf("%w: %w", ErrInvalidEventLine, err)
+	}
+	extra := matches[4]
+	payload, warnings := parsePayload(eid, extra)
+	if draw, ok := payload.(DrawTime); ok && Dated(t) && !Dated(draw.Time) {
+		// A time-only draw of a dated event is on the day of the event.
+		draw.Time = nearest(draw.Time.AddDate(t.Year(), int(t.Month())-1, t.Day()-1), t)
+		payload = draw
+	}
+	if bib.Number <= 0 && !(IsRaceEvent(eid) && bib.Number == RaceCompetitor) {
+		warnings = append(warnings, Warning{WarnNonPositiveCompetitor, fmt.Sprintf("competitor id %d is not positive, the event is ignored", bib.Number)})
+	}
+	return Event{Time: t, RawTime: matches[1], EventID: eid, CompetitorID: bib.Number, Suffix: bib.Suffix, Extra: extra, Payload: payload, Warnings: warnings}, nil
+}
+
+// StdinPath is the events path that reads the events from stdin.
+const StdinPath = "-"
+
+// LoadEvents reads the events file at path, or stdin when path is "-".
+// It fails on the first malformed line.
+func LoadEvents(path string) ([]Event, error) {
+	events, _, err := loadEvents(path, false)
+	return events, err
+}
+
+// LoadEventsLenient reads the events file at path like LoadEvents, but
+// skips the malformed lines and returns them instead of failing.
+func LoadEventsLenient(path string) ([]Event, []LineError, error) {
+	return loadEvents(path, true)
+}
+
+func loadEvents(path string, lenient bool) (events []Event, skipped []LineError, err error) {
+	if path == StdinPath {
+		return readEvents(os.Stdin, "stdin", lenient)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func(f *os.File) {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+	return readEvents(f, path, lenient)
+}
+
+// DecodeEvents parses one event per line from r. A malformed line fails
+// with a *LineError. An empty input has no events.
+func DecodeEvents(r io.Reader) ([]Event, error) {
+	events, _, err := decodeEvents(r, false)
+	return events, err
+}
+
+// ReadEvents parses the events from r like DecodeEvents. name labels errors
+// with the line number they occurred on.
+func ReadEvents(r io.Reader, name string) ([]Event, error) {
+	events, _, err := readEvents(r, name, false)
+	return events, err
+}
+
+func readEvents(r io.Reader, name string, lenient bool) ([]Event, []LineError, error) {
+	events, skipped, err := decodeEvents(r, lenient)
+	var lineErr *LineError
+	switch {
+	case err == nil:
+		return events, skipped, nil
+	case errors.As(err, &lineErr):
+		return nil, nil, fmt.Errorf("%s:%d: %q: %w", name, lineErr.Line, lineErr.Text, lineErr.Err)
+	default:
+		return nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+}
+
+// decodeEvents parses one event per line from r. A malformed line, or one
+// dated unlike the first, fails with a *LineError, or is skipped and
+// returned when lenient is set.
+func decodeEvents(r io.Reader, lenient bool) ([]Event, []LineError, error) {
+	var events []Event
+	var skipped []LineError
+	var clock dayClock
+	s := bufio.NewScanner(r)
+	for lineNo := 1; s.Scan(); lineNo++ {
+		e, err := ParseEvent(s.Text())
+		if err != nil {
+			lineErr := LineError{Line: lineNo, Text: s.Text(), Err: err}
+			if !lenient {
+				return nil, nil, &lineErr
+			}
+			skipped = append(skipped, lineErr)
+			continue
+		}
+		if len(events) > 0 && Dated(e.Time) != Dated(events[0].Time) {
+			lineErr := LineError{Line: lineNo, Text: s.Text(), Err: mixedTimes(e, events[0])}
+			if !lenient {
+				return nil, nil, &lineErr
+			}
+			skipped = append(skipped, lineErr)
+			continue
+		}
+		e.Line = lineNo
+		events = append(events, clock.roll(e))
+	}
+	if err := s.Err(); err != nil {
+		return nil, nil, err
+	}
+	return events, skipped, nil
+}
+
+// MidnightWrap is how much earlier than the previous event an event must be
+// for its time to have wrapped past midnight, rather than being logged out
+// of order.
+const MidnightWrap = 12 * time.Hour
+
+// dayClock carries the day of the events read in order across midnight,
+// since their times have no date.
+type dayClock struct {
+	day  time.Duration
+	prev time.Time
+}
+
+// roll moves e to the day that puts it within MidnightWrap of the previous
+// event, which is the next day when its time is more than MidnightWrap
+// earlier. A draw is moved to the day that puts it within MidnightWrap of e.
+func (c *dayClock) roll(e Event) Event {
+	if Dated(e.Time) {
+		return e
+	}
+	e.Time = e.Time.Add(c.day)
+	switch {
+	case c.prev.IsZero():
+	case c.prev.Sub(e.Time) > MidnightWrap:
+		c.day += 24 * time.Hour
+		e.Time = e.Time.Add(24 * time.Hour)
+	case e.Time.Sub(c.prev) > MidnightWrap:
+		// An event from before midnight logged after the first ones past it.
+		e.Time = e.Time.Add(-24 * time.Hour)
+	}
+	c.prev = e.Time
+	if draw, ok := e.Payload.(DrawTime); ok {
+		draw.Time = nearest(draw.Time.Add(c.day), e.Time)
+		e.Payload = draw
+	}
+	return e
+}
+
+// nearest moves t by a day either way when that puts it within
+// MidnightWrap of ref.
+func nearest(t, ref time.Time) time.Time {
+	switch {
+	case ref.Sub(t) > MidnightWrap:
+		return t.Add(24 * time.Hour)
+	case t.Sub(ref) > MidnightWrap:
+		return t.Add(-24 * time.Hour)
+	}
+	return t
+}
+
+// mixedTimes is the error of event e dated unlike first, the first event
+// of its input.
+func mixedTimes(e, first Event) error {
+	if Dated(e.Time) {
+		return fmt.Errorf("%w: the time has a date, but the first event's %s has none", ErrMixedTimes, first.RawTime)
+	}
+	return fmt.Errorf("%w: the time has no date, but the first event's %s has one", ErrMixedTimes, first.RawTime)
+}
+
+// ParseDelta parses a duration in HH:MM:SS format, the seconds optionally
+// with a fraction (00:00:37.5).
+func ParseDelta(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDelta, s)
+	}
+	h, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q: hours: %w", ErrInvalidDelta, s, err)
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q: minutes: %w", ErrInvalidDelta, s, err)
+	}
+	if _, err := strconv.ParseFloat(parts[2], 64); err != nil {
+		return 0, fmt.Errorf("%w: %q: seconds: %w", ErrInvalidDelta, s, err)
+	}
+	// ParseDuration reads the decimal seconds exactly, where the float
+	// would turn 37.3 into 37.299999.
+	sec, err := time.ParseDuration(parts[2] + "s")
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q: seconds: %w", ErrInvalidDelta, s, err)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + sec, nil
+}
@@ -0,0 +1,294 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEvent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		line           string
+		expectedString string
+	}{
+		{
+			name:           "test_invalid_event_format",
+			line:           "hello bad 1",
+			expectedString: "",
+		},
+		{
+			name:           "test_time_should_be_in_brackets",
+			line:           "09:30:00.000 4 1",
+			expectedString: "",
+		},
+		{
+			name:           "test_time_format",
+			line:           "[09:30:bad] 4 1",
+			expectedString: "",
+		},
+		{
+			name:           "test_success_time",
+			line:           "[09:30:01.005] 4 1",
+			expectedString: "success",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			event, err := ParseEvent(test.line)
+			if test.expectedString != "" {
+				require.NoError(t, err)
+				require.Equal(t, event.EventID, 4)
+				require.Equal(t, event.CompetitorID, 1)
+				require.Equal(t, event.RawTime, "09:30:01.005")
+				expectedTime, _ := time.Parse(TimeLayout, "09:30:01.005")
+				require.Equal(t, event.Time, expectedTime)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestParseDelta(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expecting time.Duration
+	}{
+		{
+			name:      "test_parse_seconds",
+			input:     "00:00:30",
+			expecting: 30 * time.Second,
+		},
+		{
+			name:      "test_parse_minutes_and_hours",
+			input:     "01:30:00",
+			expecting: time.Hour + 30*time.Minute,
+		},
+		{
+			name:      "test_parse_time",
+			input:     "01:23:45.670",
+			expecting: 1*time.Hour + 23*time.Minute + 45*time.Second + 670*time.Millisecond,
+		},
+		{
+			name:      "test_parse_fractional_seconds_exactly",
+			input:     "00:00:37.3",
+			expecting: 37*time.Second + 300*time.Millisecond,
+		},
+		{
+			name:      "test_incorrect_format_time",
+			input:     "30s",
+			expecting: 0,
+		},
+		{
+			name:      "test_time_should_match_regular_schedule",
+			input:     "1:2",
+			expecting: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d, err := ParseDelta(test.input)
+			if test.expecting != 0 {
+				require.NoError(t, err)
+				require.Equal(t, test.expecting, d)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestEventRegexGroups(t *testing.T) {
+	line := "[12:34:56.789] 5 10 extra params"
+	matches := eventRegex.FindStringSubmatch(line)
+	expected := []string{"[12:34:56.789] 5 10 extra params", "12:34:56.789", "5", "10", "extra params"}
+	require.Equal(t, expected, matches)
+}
+
+func TestLoadEventsInvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events")
+	require.NoError(t, os.WriteFile(path, []byte("[09:31:49.285] 1 1\n[09:32:17.531] 1\n"), 0o644))
+
+	_, err := LoadEvents(path)
+	require.True(t, errors.Is(err, ErrInvalidEventLine))
+	require.ErrorContains(t, err, path+":2:")
+}
+
+func TestParseEventInvalidLine(t *testing.T) {
+	_, err := ParseEvent("[09:30:bad] 4 1")
+	require.True(t, errors.Is(err, ErrInvalidEventLine))
+
+	_, err = ParseEvent("[25:30:00.000] 4 1")
+	require.True(t, errors.Is(err, ErrInvalidEventLine))
+
+	_, err = ParseEvent("[09:30:00.000] 4 99999999999999999999")
+	require.True(t, errors.Is(err, ErrInvalidEventLine))
+}
+
+func TestParseDeltaInvalid(t *testing.T) {
+	for _, input := range []string{"30s", "1:2", "aa:00:30", "00:bb:30", "00:00:cc"} {
+		_, err := ParseDelta(input)
+		require.True(t, errors.Is(err, ErrInvalidDelta), input)
+	}
+}
+
+func TestReadEvents(t *testing.T) {
+	events, err := ReadEvents(strings.NewReader(""), "stdin")
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	events, err = ReadEvents(strings.NewReader("[09:31:49.285] 1 1\n[09:32:17.531] 1 2\n"), "stdin")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, 2, events[1].CompetitorID)
+
+	_, err = ReadEvents(strings.NewReader("[09:31:49.285] 1 1\n\n"), "stdin")
+	require.True(t, errors.Is(err, ErrInvalidEventLine))
+	require.ErrorContains(t, err, "stdin:2:")
+}
+
+func TestDecodeEvents(t *testing.T) {
+	events, err := DecodeEvents(strings.NewReader("[09:31:49.285] 1 1\n[09:55:00.000] 2 1 10:00:00.000\n"))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, DrawTime{Time: time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)}, events[1].Payload)
+
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "truncated line", input: "[09:31:49.285] 1 1\n[09:32:17.531] 1\n", err: "line 2: "},
+		{name: "truncated time", input: "[09:31:4", err: "line 1: "},
+		{name: "truncated final line", input: "[09:31:49.285] 1 1\n[09:32:17.531] 1 2\n[09:3", err: "line 3: "},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := DecodeEvents(strings.NewReader(test.input))
+			require.True(t, errors.Is(err, ErrInvalidEventLine))
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+
+	_, err = DecodeEvents(iotest.ErrReader(errors.New("connection reset")))
+	require.EqualError(t, err, "connection reset")
+}
+
+func TestParseRaceEvent(t *testing.T) {
+	e, err := ParseEvent("[09:50:00.000] 13 0 by forerunners")
+	require.NoError(t, err)
+	require.True(t, IsRaceEvent(e.EventID))
+	require.Equal(t, "by forerunners", e.Extra)
+	require.Empty(t, e.Warnings)
+
+	e, err = ParseEvent("[09:50:10.000] 15 0")
+	require.NoError(t, err)
+	require.True(t, IsRaceEvent(e.EventID))
+	require.Empty(t, e.Warnings)
+
+	e, err = ParseEvent("[09:50:00.000] 4 0")
+	require.NoError(t, err)
+	require.False(t, IsRaceEvent(e.EventID))
+	require.Equal(t, WarnNonPositiveCompetitor, e.Warnings[0].Code)
+}
+
+func TestLoadEventsLenient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events")
+	require.NoError(t, os.WriteFile(path, []byte("[09:31:49.285] 1 1\n"+
+		"[09:32:17.531] 1\n"+
+		"[09:32:20.000] 1 2\n"+
+		"[09:33:00.000] 1 3\n"+
+		"[25:00:00.000] 1 4\n"+
+		"garbage\n"+
+		"[09:34:00.000] 1 5\n"), 0o644))
+
+	_, err := LoadEvents(path)
+	require.True(t, errors.Is(err, ErrInvalidEventLine))
+	require.EqualError(t, err, path+`:2: "[09:32:17.531] 1": invalid event line: want [HH:MM:SS.sss] eventID competitorID [extraParams]`)
+
+	events, skipped, err := LoadEventsLenient(path)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	require.Equal(t, 5, events[3].CompetitorID)
+	require.Len(t, skipped, 3)
+	for i, want := range []struct {
+		line int
+		text string
+	}{{2, "[09:32:17.531] 1"}, {5, "[25:00:00.000] 1 4"}, {6, "garbage"}} {
+		require.Equal(t, want.line, skipped[i].Line)
+		require.Equal(t, want.text, skipped[i].Text)
+		require.True(t, errors.Is(&skipped[i], ErrInvalidEventLine))
+	}
+}
+
+func TestDecodeEventsLineError(t *testing.T) {
+	_, err := DecodeEvents(strings.NewReader("[09:31:49.285] 1 1\ngarbage\n"))
+	var lineErr *LineError
+	require.True(t, errors.As(err, &lineErr))
+	require.Equal(t, 2, lineErr.Line)
+	require.Equal(t, "garbage", lineErr.Text)
+	require.EqualError(t, err, `line 2: "garbage": invalid event line: want [HH:MM:SS.sss] eventID competitorID [extraParams]`)
+}
+
+func TestDecodeEventsAcrossMidnight(t *testing.T) {
+	input := "[23:40:00.000] 2 1 00:05:00.000\n[23:59:59.000] 4 2\n[23:59:58.000] 4 3\n" +
+		"[00:00:05.000] 4 1\n[00:01:00.000] 2 4 23:59:00.000\n[23:59:59.500] 3 5\n[11:00:00.000] 10 1\n"
+	events, err := DecodeEvents(strings.NewReader(input))
+	require.NoError(t, err)
+	day := func(d, h, m, s, ms int) time.Time {
+		return time.Date(0, 1, 1+d, h, m, s, ms*int(time.Millisecond), time.UTC)
+	}
+	times := make([]time.Time, len(events))
+	for i, e := range events {
+		times[i] = e.Time
+	}
+	require.Equal(t, []time.Time{day(0, 23, 40, 0, 0), day(0, 23, 59, 59, 0), day(0, 23, 59, 58, 0),
+		day(1, 0, 0, 5, 0), day(1, 0, 1, 0, 0), day(0, 23, 59, 59, 500), day(1, 11, 0, 0, 0)}, times,
+		"a time a second early is out of order, one half a day early is past midnight and one half a day late before it")
+	require.Equal(t, "00:00:05.000", events[3].RawTime)
+	require.Equal(t, DrawTime{Time: day(1, 0, 5, 0, 0)}, events[0].Payload, "the draw is past midnight from its event")
+	require.Equal(t, DrawTime{Time: day(0, 23, 59, 0, 0)}, events[4].Payload, "the draw is before midnight from its event")
+}
+
+func TestParseDatedEvent(t *testing.T) {
+	t.Parallel()
+	e, err := ParseEvent("[2024-03-12 09:30:01.005] 4 1")
+	require.NoError(t, err)
+	require.Equal(t, "2024-03-12 09:30:01.005", e.RawTime)
+	require.Equal(t, time.Date(2024, 3, 12, 9, 30, 1, 5_000_000, time.UTC), e.Time)
+	require.True(t, Dated(e.Time))
+
+	e, err = ParseEvent("[2024-03-12 23:50:00.000] 2 1 00:05:00.000")
+	require.NoError(t, err)
+	require.Equal(t, DrawTime{Time: time.Date(2024, 3, 13, 0, 5, 0, 0, time.UTC)}, e.Payload, "a time-only draw is on the day nearest its event")
+	e, err = ParseEvent("[2024-03-12 09:00:00.000] 2 1 2024-03-14 10:00:00.000")
+	require.NoError(t, err)
+	require.Equal(t, DrawTime{Time: time.Date(2024, 3, 14, 10, 0, 0, 0, time.UTC)}, e.Payload)
+
+	_, err = ParseEvent("[2024-13-12 09:30:01.005] 4 1")
+	require.ErrorIs(t, err, ErrInvalidEventLine)
+}
+
+func TestDecodeDatedEvents(t *testing.T) {
+	t.Parallel()
+	events, err := DecodeEvents(strings.NewReader("[2024-03-12 23:59:50.000] 4 1\n[2024-03-14 00:00:05.000] 4 2\n"))
+	require.NoError(t, err)
+	require.Equal(t, 24*time.Hour+15*time.Second, events[1].Time.Sub(events[0].Time), "dated times aren't rolled")
+
+	_, err = DecodeEvents(strings.NewReader("[2024-03-12 09:30:00.000] 1 1\n[09:30:01.005] 4 1\n"))
+	require.ErrorIs(t, err, ErrMixedTimes)
+	require.EqualError(t, err, `line 2: "[09:30:01.005] 4 1": dated and undated event times mixed: the time has no date, but the first event's 2024-03-12 09:30:00.000 has one`)
+	_, err = DecodeEvents(strings.NewReader("[09:30:00.000] 1 1\n[2024-03-12 09:30:01.005] 4 1\n"))
+	require.EqualError(t, err, `line 2: "[2024-03-12 09:30:01.005] 4 1": dated and undated event times mixed: the time has a date, but the first event's 09:30:00.000 has none`)
+}
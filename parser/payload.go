@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Payload is the typed form of Event.Extra. Which concrete type is stored
+// depends on the EventID; events without extra parameters have a nil Payload.
+type Payload interface {
+	payload()
+}
+
+// DrawTime is the start time assigned by a draw (startTime event).
+type DrawTime struct {
+	Time time.Time
+}
+
+// FiringLine is the firing line number the competitor took (onTheFiringRange
+// event). Range systems that number the bouts send the index after the line
+// ("4 2" is line 4, second shooting); Bout is 0 when it wasn't sent.
+type FiringLine struct {
+	Line int
+	Bout int
+}
+
+// TargetNumber is the target that has been hit (hit event), from 1. Whether
+// it is within the targets of a firing line depends on the race config.
+type TargetNumber struct {
+	Target int
+}
+
+// Reason is the explanation why the competitor can't continue (comment event).
+// Text is the comment as sent. Fields holds its key=value pairs when the
+// whole comment is made of them, and is nil for free text.
+type Reason struct {
+	Text   string
+	Fields map[string]string
+}
+
+// ShotResult tells whether a single shot hit (shot event, Extra "hit" or "miss").
+type ShotResult struct {
+	Hit bool
+}
+
+func (DrawTime) payload()     {}
+func (FiringLine) payload()   {}
+func (TargetNumber) payload() {}
+func (Reason) payload()       {}
+func (ShotResult) payload()   {}
+
+// WarningCode identifies the kind of problem found in an event.
+type WarningCode string
+
+const (
+	WarnInvalidDrawTime     WarningCode = "invalid_draw_time"
+	WarnInvalidFiringLine   WarningCode = "invalid_firing_line"
+	WarnInvalidTargetNumber WarningCode = "invalid_target_number"
+	WarnInvalidShotResult   WarningCode = "invalid_shot_result"
+)
+
+// Warning is a non-fatal problem attached to an event at load time.
+type Warning struct {
+	Code    WarningCode
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Code, w.Message)
+}
+
+// parsePayload interprets extra according to eventID. A malformed extra
+// yields a nil Payload together with a warning describing the problem.
+func parsePayload(eventID int, extra string) (Payload, []Warning) {
+	switch eventID {
+	case StartTime:
+		t, err := ParseTime(extra)
+		if err != nil {
+			return nil, []Warning{{WarnInvalidDrawTime, fmt.Sprintf("start time %q is not in %s format, with an optional date", extra, TimeLayout)}}
+		}
+		return DrawTime{Time: t}, nil
+	case OnTheFiringRange:
+		fields := strings.Fields(extra)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, []Warning{{WarnInvalidFiringLine, fmt.Sprintf("firing line %q is not a line number with an optional bout index", extra)}}
+		}
+		line, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, []Warning{{WarnInvalidFiringLine, fmt.Sprintf("firing line %q is not a number", fields[0])}}
+		}
+		if len(fields) == 1 {
+			return FiringLine{Line: line}, nil
+		}
+		bout, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, []Warning{{WarnInvalidFiringLine, fmt.Sprintf("bout index %q is not a number", fields[1])}}
+		}
+		return FiringLine{Line: line, Bout: bout}, nil
+	case Hit:
+		if extra == "" {
+			return nil, []Warning{{WarnInvalidTargetNumber, "hit without a target number"}}
+		}
+		target, err := strconv.Atoi(extra)
+		if err != nil {
+			return nil, []Warning{{WarnInvalidTargetNumber, fmt.Sprintf("target %q is not a number", extra)}}
+		}
+		if target < 1 {
+			return nil, []Warning{{WarnInvalidTargetNumber, fmt.Sprintf("target %d is not a positive number", target)}}
+		}
+		return TargetNumber{Target: target}, nil
+	case Comment:
+		return Reason{Text: extra, Fields: parseFields(extra)}, nil
+	case Shot:
+		switch extra {
+		case "hit":
+			return ShotResult{Hit: true}, nil
+		case "miss":
+			return ShotResult{Hit: false}, nil
+		}
+		return nil, []Warning{{WarnInvalidShotResult, fmt.Sprintf("shot result %q is neither hit nor miss", extra)}}
+	}
+	return nil, nil
+}
+
+// parseFields splits s into space-separated key=value pairs. Values may be
+// double-quoted to contain spaces. It returns nil unless all of s is pairs.
+func parseFields(s string) map[string]string {
+	fields := map[string]string{}
+	for s = strings.TrimLeft(s, " "); s != ""; s = strings.TrimLeft(s, " ") {
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok || key == "" || strings.Contains(key, " ") {
+			return nil
+		}
+		value := rest
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil
+			}
+			if value, err = strconv.Unquote(quoted); err != nil {
+				return nil
+			}
+			rest = rest[len(quoted):]
+			if rest != "" && rest[0] != ' ' {
+				return nil
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+		}
+		fields[key] = value
+		s = rest
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
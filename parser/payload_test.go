@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePayload(t *testing.T) {
+	t.Parallel()
+	drawTime, _ := time.Parse(TimeLayout, "10:00:00.000")
+	tests := []struct {
+		name            string
+		line            string
+		expectedPayload Payload
+		expectedWarning WarningCode
+	}{
+		{
+			name:            "test_draw_time",
+			line:            "[09:55:00.000] 2 1 10:00:00.000",
+			expectedPayload: DrawTime{Time: drawTime},
+		},
+		{
+			name:            "test_malformed_draw_time",
+			line:            "[09:55:00.000] 2 1 10:00",
+			expectedWarning: WarnInvalidDrawTime,
+		},
+		{
+			name:            "test_firing_line",
+			line:            "[10:08:49.289] 5 1 2",
+			expectedPayload: FiringLine{Line: 2},
+		},
+		{
+			name:            "test_firing_line_with_bout_index",
+			line:            "[10:08:49.289] 5 1 4 2",
+			expectedPayload: FiringLine{Line: 4, Bout: 2},
+		},
+		{
+			name:            "test_malformed_bout_index",
+			line:            "[10:08:49.289] 5 1 4 second",
+			expectedWarning: WarnInvalidFiringLine,
+		},
+		{
+			name:            "test_malformed_firing_line",
+			line:            "[10:08:49.289] 5 1 second",
+			expectedWarning: WarnInvalidFiringLine,
+		},
+		{
+			name:            "test_target_number",
+			line:            "[10:08:50.884] 6 1 4",
+			expectedPayload: TargetNumber{Target: 4},
+		},
+		{
+			name:            "test_missing_target_number",
+			line:            "[10:08:50.884] 6 1",
+			expectedWarning: WarnInvalidTargetNumber,
+		},
+		{
+			name:            "test_garbage_target_number",
+			line:            "[10:08:50.884] 6 1 x3",
+			expectedWarning: WarnInvalidTargetNumber,
+		},
+		{
+			name:            "test_zero_target_number",
+			line:            "[10:08:50.884] 6 1 0",
+			expectedWarning: WarnInvalidTargetNumber,
+		},
+		{
+			name:            "test_reason",
+			line:            "[10:30:00.000] 11 1 Lost in the forest",
+			expectedPayload: Reason{Text: "Lost in the forest"},
+		},
+		{
+			name:            "test_reason_fields",
+			line:            "[10:30:00.000] 11 1 reason=fall location=downhill-2 medic=yes",
+			expectedPayload: Reason{Text: "reason=fall location=downhill-2 medic=yes", Fields: map[string]string{"reason": "fall", "location": "downhill-2", "medic": "yes"}},
+		},
+		{
+			name:            "test_reason_quoted_field",
+			line:            `[10:30:00.000] 11 1 reason="broken pole" location=km-3`,
+			expectedPayload: Reason{Text: `reason="broken pole" location=km-3`, Fields: map[string]string{"reason": "broken pole", "location": "km-3"}},
+		},
+		{
+			name:            "test_reason_mixed_text",
+			line:            "[10:30:00.000] 11 1 fell at km 3, medic=yes",
+			expectedPayload: Reason{Text: "fell at km 3, medic=yes"},
+		},
+		{
+			name:            "test_shot_hit",
+			line:            "[10:08:50.884] 12 1 hit",
+			expectedPayload: ShotResult{Hit: true},
+		},
+		{
+			name:            "test_shot_miss",
+			line:            "[10:08:50.884] 12 1 miss",
+			expectedPayload: ShotResult{Hit: false},
+		},
+		{
+			name:            "test_malformed_shot",
+			line:            "[10:08:50.884] 12 1 bullseye",
+			expectedWarning: WarnInvalidShotResult,
+		},
+		{
+			name: "test_event_without_extra",
+			line: "[09:59:45.000] 3 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			event, err := ParseEvent(test.line)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedPayload, event.Payload)
+			if test.expectedWarning != "" {
+				require.Len(t, event.Warnings, 1)
+				require.Equal(t, test.expectedWarning, event.Warnings[0].Code)
+			} else {
+				require.Empty(t, event.Warnings)
+			}
+		})
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		text     string
+		expected map[string]string
+	}{
+		{name: "pairs", text: "reason=fall medic=yes", expected: map[string]string{"reason": "fall", "medic": "yes"}},
+		{name: "quoted value", text: `reason="broken pole"`, expected: map[string]string{"reason": "broken pole"}},
+		{name: "escaped quote", text: `reason="said \"stop\""`, expected: map[string]string{"reason": `said "stop"`}},
+		{name: "extra spaces", text: " reason=fall   medic=yes ", expected: map[string]string{"reason": "fall", "medic": "yes"}},
+		{name: "empty value", text: "reason=", expected: map[string]string{"reason": ""}},
+		{name: "free text", text: "Lost in the forest"},
+		{name: "empty", text: ""},
+		{name: "missing key", text: "=fall"},
+		{name: "unterminated quote", text: `reason="broken pole`},
+		{name: "text after quote", text: `reason="broken"pole`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.expected, parseFields(test.text))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package biathlon
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+// Payout is the prize money of one finisher, in cents.
+type Payout struct {
+	Place int
+	Bib   Bib
+	Cents int64
+}
+
+// toCents converts a payout table amount to whole cents.
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// formatCents formats cents as an amount with two decimals.
+func formatCents(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// payouts allocates the payout table, place to amount, over the results in
+// ranking order. Finishers sharing a place split the amounts of the places
+// they cover evenly, the cents left over going to the one with the most
+// hits, the better bib on equal hits. Non-finishers and finishers paid
+// nothing are left out.
+func payouts(results []Result, table map[int]float64) []Payout {
+	var sheet []Payout
+	for i := 0; i < len(results) && results[i].Status == StatusFinished; {
+		j := i + 1
+		for j < len(results) && results[j].Status == StatusFinished && results[j].Place == results[i].Place {
+			j++
+		}
+		tied := results[i:j]
+		i = j
+
+		var pot int64
+		for place := tied[0].Place; place < tied[0].Place+len(tied); place++ {
+			pot += toCents(table[place])
+		}
+		if pot == 0 {
+			continue
+		}
+		share := pot / int64(len(tied))
+		best := 0
+		for k, r := range tied {
+			if r.Hits > tied[best].Hits {
+				best = k
+			}
+		}
+		for k, r := range tied {
+			p := Payout{Place: r.Place, Bib: r.Bib, Cents: share}
+			if k == best {
+				p.Cents += pot - share*int64(len(tied))
+			}
+			sheet = append(sheet, p)
+		}
+	}
+	return sheet
+}
+
+// printPayouts prints the payout sheet if anyone is paid.
+func printPayouts(w io.Writer, sheet []Payout) {
+	if len(sheet) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nPayouts:")
+	for _, p := range sheet {
+		fmt.Fprintf(w, "%d. competitor(%s) %s\n", p.Place, p.Bib, formatCents(p.Cents))
+	}
+}
+
+// writePayoutsCSV writes the payout sheet to path as CSV with a header row.
+func writePayoutsCSV(path string, sheet []Payout) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func(f *os.File) {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+	cw := csv.NewWriter(f)
+	if err := cw.Write([]string{"place", "competitor", "amount"}); err != nil {
+		return err
+	}
+	for _, p := range sheet {
+		if err := cw.Write([]string{strconv.Itoa(p.Place), p.Bib.String(), formatCents(p.Cents)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,90 @@
+package biathlon
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayouts(t *testing.T) {
+	t.Parallel()
+	finisher := func(place, number, hits int) Result {
+		return Result{Place: place, Bib: Bib{Number: number}, Status: StatusFinished, Hits: hits}
+	}
+	table := map[int]float64{1: 500.01, 2: 300, 3: 100}
+	tests := []struct {
+		name    string
+		results []Result
+		want    []Payout
+	}{
+		{
+			name:    "no ties",
+			results: []Result{finisher(1, 4, 9), finisher(2, 2, 8), finisher(3, 7, 10), finisher(4, 1, 10)},
+			want:    []Payout{{1, Bib{Number: 4}, 50001}, {2, Bib{Number: 2}, 30000}, {3, Bib{Number: 7}, 10000}},
+		},
+		{
+			name:    "two-way tie for 1st splits 1st and 2nd",
+			results: []Result{finisher(1, 2, 8), finisher(1, 5, 9), finisher(3, 3, 10)},
+			want:    []Payout{{1, Bib{Number: 2}, 40000}, {1, Bib{Number: 5}, 40001}, {3, Bib{Number: 3}, 10000}},
+		},
+		{
+			name:    "equal hits leave the remainder to the better bib",
+			results: []Result{finisher(1, 2, 8), finisher(1, 5, 8)},
+			want:    []Payout{{1, Bib{Number: 2}, 40001}, {1, Bib{Number: 5}, 40000}},
+		},
+		{
+			name: "three-way tie reaching past the table",
+			results: []Result{finisher(1, 1, 10), finisher(2, 2, 7), finisher(2, 3, 9), finisher(2, 4, 8),
+				{Bib: Bib{Number: 5}, Status: StatusNotFinished, Hits: 10}},
+			want: []Payout{{1, Bib{Number: 1}, 50001}, {2, Bib{Number: 2}, 13333}, {2, Bib{Number: 3}, 13334}, {2, Bib{Number: 4}, 13333}},
+		},
+		{
+			name:    "non-finishers get nothing",
+			results: []Result{{Bib: Bib{Number: 1}, Status: StatusDisqualified}, {Bib: Bib{Number: 2}, Status: StatusNotStarted}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.want, payouts(test.results, table))
+		})
+	}
+}
+
+func TestPayoutSheet(t *testing.T) {
+	t.Parallel()
+	sheet := []Payout{{1, Bib{Number: 2}, 40000}, {1, Bib{Number: 5, Suffix: "b"}, 40001}, {3, Bib{Number: 3}, 5}}
+
+	var out bytes.Buffer
+	printPayouts(&out, sheet)
+	require.Equal(t, "\nPayouts:\n1. competitor(2) 400.00\n1. competitor(5b) 400.01\n3. competitor(3) 0.05\n", out.String())
+
+	out.Reset()
+	printPayouts(&out, nil)
+	require.Empty(t, out.String())
+
+	path := filepath.Join(t.TempDir(), "payouts.csv")
+	require.NoError(t, writePayoutsCSV(path, sheet))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "place,competitor,amount\n1,2,400.00\n1,5b,400.01\n3,3,0.05\n", string(data))
+}
+
+func TestLoadConfigPayouts(t *testing.T) {
+	t.Parallel()
+	cfg, err := LoadConfig(writeConfig(t, "{"+baseConfigFields+`, "payouts": {"1": 500, "2": 250.5}}`))
+	require.NoError(t, err)
+	require.Equal(t, map[int]float64{1: 500, 2: 250.5}, cfg.Payouts)
+
+	var out bytes.Buffer
+	printConfig(&out, cfg)
+	require.Contains(t, out.String(), "  payouts: 1=500.00,2=250.50\n")
+
+	_, err = LoadConfig(writeConfig(t, "{"+baseConfigFields+`, "payouts": {"0": 500}}`))
+	require.ErrorContains(t, err, "payouts must map places from 1 to non-negative amounts, got 0: 500")
+	_, err = LoadConfig(writeConfig(t, "{"+baseConfigFields+`, "payouts": {"1": -5}}`))
+	require.ErrorContains(t, err, "got 1: -5")
+}
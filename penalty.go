@@ -0,0 +1,121 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const (
+	WarnPenaltyUnserved WarningCode = "penalty_unserved"
+	WarnUnobservedBout  WarningCode = "unobserved_bout"
+)
+
+// PenaltyVisit is a visit to the penalty laps credited to the bouts whose
+// misses it serves: the competitor skis the loops of every bout owing them
+// in one visit. Bouts is empty when no bout had unserved misses.
+type PenaltyVisit struct {
+	Span
+	Bouts []int
+}
+
+// served reports whether a penalty visit was credited to bout index.
+func (c *Competitor) served(index int) bool {
+	for _, p := range c.Penalties {
+		if slices.Contains(p.Bouts, index) {
+			return true
+		}
+	}
+	return false
+}
+
+// unservedBouts returns the indexes of the completed bouts with misses that
+// no penalty visit was credited to yet, earliest first. Misses may thus be
+// served after a later bout when the jury allows it.
+func (c *Competitor) unservedBouts(targets int) []int {
+	var unserved []int
+	for i := range c.Bouts {
+		b := &c.Bouts[i]
+		if !b.open() && b.misses(targets) > 0 && !c.served(b.Index) {
+			unserved = append(unserved, b.Index)
+		}
+	}
+	return unserved
+}
+
+// shotThisLap reports whether the competitor has been to the firing range
+// since the start or the last lap end.
+func (c *Competitor) shotThisLap() bool {
+	if len(c.Bouts) == 0 {
+		return false
+	}
+	lapStart := c.ActualStart
+	if n := len(c.LapEnds); n > 0 {
+		lapStart = c.LapEnds[n-1]
+	}
+	return !c.Bouts[len(c.Bouts)-1].Start.Before(lapStart)
+}
+
+// auditUnobservedBouts flags the bouts inferred from a visit to the penalty
+// laps because the range system lost them.
+func auditUnobservedBouts(competitors map[Bib]*Competitor) []Warning {
+	var warnings []Warning
+	for _, bib := range sortedBibs(competitors) {
+		for _, b := range competitors[bib].Bouts {
+			if b.Unobserved {
+				warnings = append(warnings, Warning{Code: WarnUnobservedBout, Message: fmt.Sprintf(
+					"competitor(%s) shooting %d wasn't reported by the range system, inferred from the penalty laps at %s with unknown hits",
+					bib, b.Index, b.Start.Format(timeLayout))})
+			}
+		}
+	}
+	return warnings
+}
+
+// auditUnservedPenalties flags the finishers with bouts whose misses no
+// penalty visit was credited to, for a disqualification review.
+func auditUnservedPenalties(competitors map[Bib]*Competitor, cfg Config) []Warning {
+	var warnings []Warning
+	for _, bib := range sortedBibs(competitors) {
+		comp := competitors[bib]
+		if comp.FinishTime.IsZero() || comp.LapsCompleted != cfg.Laps {
+			continue
+		}
+		for i := range comp.Bouts {
+			b := &comp.Bouts[i]
+			if n := b.misses(cfg.TargetsPerLine); n > 0 && !comp.served(b.Index) {
+				warnings = append(warnings, Warning{Code: WarnPenaltyUnserved, Message: fmt.Sprintf(
+					"competitor(%s) finished with %d unserved misses from shooting %d, review for disqualification", bib, n, b.Index)})
+			}
+		}
+	}
+	return warnings
+}
+
+// printPenaltyCredits prints which bouts every penalty visit was credited to.
+// Nothing is printed when no competitor went into the penalty laps.
+func printPenaltyCredits(w io.Writer, competitors map[Bib]*Competitor) {
+	header := false
+	for _, bib := range sortedBibs(competitors) {
+		for _, p := range competitors[bib].Penalties {
+			if !header {
+				fmt.Fprintln(w, "\nPenalty laps:")
+				header = true
+			}
+			bouts := make([]string, len(p.Bouts))
+			for i, b := range p.Bouts {
+				bouts[i] = fmt.Sprint(b)
+			}
+			credit := "no unserved misses"
+			switch len(bouts) {
+			case 0:
+			case 1:
+				credit = "shooting " + bouts[0]
+			default:
+				credit = "shootings " + strings.Join(bouts, ", ")
+			}
+			fmt.Fprintf(w, "Competitor %s: penalty laps at %s credited to %s\n", bib, p.Start.Format(timeLayout), credit)
+		}
+	}
+}
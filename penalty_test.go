@@ -0,0 +1,133 @@
+package biathlon
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPenaltyCarryover(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:31:50.285] 1 2",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[09:55:01.000] 2 2 10:01:30.000",
+		"[10:00:00.000] 4 1",
+		"[10:01:30.000] 4 2",
+		// Competitor 1 misses twice on shooting 1 and serves them after shooting 2.
+		"[10:08:00.000] 5 1 1",
+		"[10:08:01.000] 6 1 1",
+		"[10:08:02.000] 6 1 2",
+		"[10:08:03.000] 6 1 3",
+		"[10:08:10.000] 7 1",
+		"[10:12:00.000] 10 1",
+		"[10:20:00.000] 5 1 2",
+		"[10:20:01.000] 6 1 1",
+		"[10:20:02.000] 6 1 2",
+		"[10:20:03.000] 6 1 3",
+		"[10:20:04.000] 6 1 4",
+		"[10:20:05.000] 6 1 5",
+		"[10:20:10.000] 7 1",
+		"[10:20:20.000] 8 1",
+		"[10:21:40.000] 9 1",
+		"[10:25:00.000] 10 1",
+		// Competitor 2 never serves the miss of shooting 1.
+		"[10:09:30.000] 5 2 1",
+		"[10:09:31.000] 6 2 1",
+		"[10:09:32.000] 6 2 2",
+		"[10:09:33.000] 6 2 3",
+		"[10:09:34.000] 6 2 4",
+		"[10:09:40.000] 7 2",
+		"[10:14:00.000] 10 2",
+		"[10:27:00.000] 10 2",
+	)
+	p := newProcessor(r, nil, io.Discard)
+	require.NoError(t, p.ProcessAll(r.events))
+	competitors := p.Competitors()
+
+	first := competitors[Bib{Number: 1}]
+	require.Len(t, first.Penalties, 1)
+	require.Equal(t, []int{1}, first.Penalties[0].Bouts)
+	require.Empty(t, first.unservedBouts(5))
+
+	warnings := auditUnservedPenalties(competitors, r.cfg)
+	require.Equal(t, []Warning{{Code: WarnPenaltyUnserved, Message: "competitor(2) finished with 1 unserved misses from shooting 1, review for disqualification"}}, warnings)
+
+	var out bytes.Buffer
+	printPenaltyCredits(&out, competitors)
+	require.Equal(t, "\nPenalty laps:\nCompetitor 1: penalty laps at 10:20:20.000 credited to shooting 1\n", out.String())
+}
+
+// TestPenaltyVisitServesEveryBout serves the misses of shootings 1 and 2 in
+// one visit to the penalty laps after shooting 2.
+func TestPenaltyVisitServesEveryBout(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[10:00:00.000] 4 1",
+		"[10:08:00.000] 5 1 1",
+		"[10:08:01.000] 6 1 1",
+		"[10:08:02.000] 6 1 2",
+		"[10:08:03.000] 6 1 3",
+		"[10:08:04.000] 6 1 4",
+		"[10:08:10.000] 7 1",
+		"[10:12:00.000] 10 1",
+		"[10:20:00.000] 5 1 2",
+		"[10:20:01.000] 6 1 1",
+		"[10:20:02.000] 6 1 2",
+		"[10:20:03.000] 6 1 3",
+		"[10:20:10.000] 7 1",
+		"[10:20:20.000] 8 1",
+		"[10:22:10.000] 9 1",
+		"[10:25:00.000] 10 1",
+	)
+	p := newProcessor(r, nil, io.Discard)
+	require.NoError(t, p.ProcessAll(r.events))
+	c := p.Competitors()[Bib{Number: 1}]
+	require.Len(t, c.Penalties, 1)
+	require.Equal(t, []int{1, 2}, c.Penalties[0].Bouts)
+	require.Empty(t, c.unservedBouts(5))
+	require.Empty(t, auditUnservedPenalties(p.Competitors(), r.cfg))
+
+	var out bytes.Buffer
+	printPenaltyCredits(&out, p.Competitors())
+	require.Equal(t, "\nPenalty laps:\nCompetitor 1: penalty laps at 10:20:20.000 credited to shootings 1, 2\n", out.String())
+}
+
+func TestUnobservedBout(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[10:00:00.000] 4 1",
+		"[10:08:00.000] 5 1 1",
+		"[10:08:01.000] 6 1 1",
+		"[10:08:02.000] 6 1 2",
+		"[10:08:03.000] 6 1 3",
+		"[10:08:04.000] 6 1 4",
+		"[10:08:05.000] 6 1 5",
+		"[10:08:10.000] 7 1",
+		"[10:12:00.000] 10 1",
+		// The range system loses shooting 2, the penalty laps still arrive.
+		"[10:20:20.000] 8 1",
+		"[10:21:10.000] 9 1",
+		"[10:25:00.000] 10 1",
+	)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, io.Discard)
+	require.NoError(t, p.ProcessAll(r.events))
+	c := p.Competitors()[Bib{Number: 1}]
+	require.Len(t, c.Bouts, 2)
+	require.True(t, c.Bouts[1].Unobserved)
+	require.Equal(t, 2, c.Bouts[1].Index)
+	require.Equal(t, []int{2}, c.Penalties[0].Bouts)
+	require.Equal(t, 0, c.misses(r.cfg))
+
+	require.Empty(t, auditPenaltyLoops(p.Competitors(), r.cfg))
+	require.Empty(t, auditUnservedPenalties(p.Competitors(), r.cfg))
+	printReport(&out, p, r, reportStyle{locale: locales["en"]})
+	require.Contains(t, out.String(), "Competitor 1: penalty laps at 10:20:20.000 credited to shooting 2\n")
+	require.Contains(t, out.String(), "unobserved_bout: competitor(1) shooting 2 wasn't reported by the range system, "+
+		"inferred from the penalty laps at 10:20:20.000 with unknown hits\n")
+}
@@ -0,0 +1,71 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+)
+
+// PositionAccuracy is the shooting accuracy over the completed and observed
+// bouts shot in one position.
+type PositionAccuracy struct {
+	Position string
+	Hits     int
+	Targets  int
+}
+
+func (a PositionAccuracy) String() string {
+	return fmt.Sprintf("%s %.0f%% (%d/%d)", a.Position, float64(a.Hits)*100/float64(a.Targets), a.Hits, a.Targets)
+}
+
+// positionAccuracy adds the completed bouts of c to the accuracy by
+// position in acc, keeping the positions in the order they were first shot.
+func positionAccuracy(acc []PositionAccuracy, c *Competitor, targets int) []PositionAccuracy {
+	for i := range c.Bouts {
+		b := &c.Bouts[i]
+		if b.open() || b.Unobserved {
+			continue
+		}
+		j := 0
+		for j < len(acc) && acc[j].Position != b.position() {
+			j++
+		}
+		if j == len(acc) {
+			acc = append(acc, PositionAccuracy{Position: b.position()})
+		}
+		acc[j].Hits += b.Hits
+		acc[j].Targets += targets
+	}
+	return acc
+}
+
+// printPositionAccuracy prints the shooting accuracy by position of the
+// field and of every competitor. Without a firing order in the config the
+// bouts are told apart by number. Nothing is printed when no bout was
+// completed.
+func printPositionAccuracy(w io.Writer, competitors map[Bib]*Competitor, targets int) {
+	var field []PositionAccuracy
+	for _, bib := range sortedBibs(competitors) {
+		field = positionAccuracy(field, competitors[bib], targets)
+	}
+	if len(field) == 0 || targets <= 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nShooting by position:")
+	fmt.Fprintf(w, "Field: %s\n", joinAccuracy(field))
+	for _, bib := range sortedBibs(competitors) {
+		if acc := positionAccuracy(nil, competitors[bib], targets); len(acc) > 0 {
+			fmt.Fprintf(w, "  competitor(%s) %s\n", bib, joinAccuracy(acc))
+		}
+	}
+}
+
+func joinAccuracy(acc []PositionAccuracy) string {
+	s := ""
+	for i, a := range acc {
+		if i > 0 {
+			s += ", "
+		}
+		s += a.String()
+	}
+	return s
+}
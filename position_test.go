@@ -0,0 +1,54 @@
+package biathlon
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionAccuracySprint(t *testing.T) {
+	t.Parallel()
+	r := newTestRace(t,
+		"[09:30:00.000] 1 1",
+		"[09:30:01.000] 1 2",
+		"[10:08:00.000] 5 1 1",
+		"[10:08:01.000] 6 1 1",
+		"[10:08:02.000] 6 1 2",
+		"[10:08:03.000] 6 1 3",
+		"[10:08:04.000] 6 1 4",
+		"[10:08:05.000] 6 1 5",
+		"[10:08:30.000] 7 1",
+		"[10:09:00.000] 5 2 2",
+		"[10:09:01.000] 6 2 1",
+		"[10:09:30.000] 7 2",
+		"[10:20:00.000] 5 1 1",
+		"[10:20:01.000] 6 1 1",
+		"[10:20:02.000] 6 1 2",
+		"[10:20:30.000] 7 1",
+	)
+	r.cfg.FiringOrder = []string{PositionProne, PositionStanding}
+	var log bytes.Buffer
+	p := newProcessor(r, nil, &log)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Equal(t, PositionStanding, p.Competitors()[Bib{Number: 1}].Bouts[1].Position)
+
+	var out bytes.Buffer
+	printPositionAccuracy(&out, p.Competitors(), r.cfg.TargetsPerLine)
+	require.Equal(t, "\nShooting by position:\n"+
+		"Field: prone 60% (6/10), standing 40% (2/5)\n"+
+		"  competitor(1) prone 100% (5/5), standing 40% (2/5)\n"+
+		"  competitor(2) prone 20% (1/5)\n", out.String())
+}
+
+func TestPositionAccuracyWithoutOrder(t *testing.T) {
+	t.Parallel()
+	end := time.Date(0, 1, 1, 10, 8, 30, 0, time.UTC)
+	c := &Competitor{ID: 1, Bouts: []Bout{
+		{Index: 1, End: end, Hits: 4},
+		{Index: 2, End: end.Add(12 * time.Minute), Hits: 5},
+		{Index: 3, Hits: 1},
+	}}
+	require.Equal(t, []PositionAccuracy{{"bout 1", 4, 5}, {"bout 2", 5, 5}}, positionAccuracy(nil, c, 5))
+}
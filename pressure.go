@@ -0,0 +1,84 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+)
+
+// Pressure compares the accuracy on the final bout with the average
+// accuracy on the earlier bouts. Accuracies are fractions of targets hit.
+type Pressure struct {
+	Bib     Bib
+	Final   float64
+	Earlier float64
+}
+
+// Index is the final accuracy relative to the earlier one. ok is false when
+// the earlier accuracy is zero and only the difference is meaningful.
+func (p Pressure) Index() (index float64, ok bool) {
+	if p.Earlier == 0 {
+		return 0, false
+	}
+	return p.Final / p.Earlier, true
+}
+
+func (p Pressure) String() string {
+	if index, ok := p.Index(); ok {
+		return fmt.Sprintf("final %.0f%%, earlier %.0f%%, index %.2f", p.Final*100, p.Earlier*100, index)
+	}
+	return fmt.Sprintf("final %.0f%%, earlier %.0f%%, difference %+.0f%%", p.Final*100, p.Earlier*100, (p.Final-p.Earlier)*100)
+}
+
+// pressureOf computes the pressure metric of c over its completed and
+// observed bouts. ok is false with fewer than two of them.
+func pressureOf(c *Competitor, targets int) (p Pressure, ok bool) {
+	var bouts []*Bout
+	for i := range c.Bouts {
+		if !c.Bouts[i].open() && !c.Bouts[i].Unobserved {
+			bouts = append(bouts, &c.Bouts[i])
+		}
+	}
+	if len(bouts) < 2 || targets <= 0 {
+		return Pressure{}, false
+	}
+	earlier := 0
+	for _, b := range bouts[:len(bouts)-1] {
+		earlier += b.Hits
+	}
+	return Pressure{
+		Bib:     c.Bib(),
+		Final:   float64(bouts[len(bouts)-1].Hits) / float64(targets),
+		Earlier: float64(earlier) / float64((len(bouts)-1)*targets),
+	}, true
+}
+
+// fieldPressure computes the metric of every competitor with at least two
+// bouts and the field average of their final and earlier accuracies.
+func fieldPressure(competitors map[Bib]*Competitor, targets int) (all []Pressure, field Pressure) {
+	for _, bib := range sortedBibs(competitors) {
+		if p, ok := pressureOf(competitors[bib], targets); ok {
+			all = append(all, p)
+			field.Final += p.Final
+			field.Earlier += p.Earlier
+		}
+	}
+	if len(all) > 0 {
+		field.Final /= float64(len(all))
+		field.Earlier /= float64(len(all))
+	}
+	return all, field
+}
+
+// printPressure prints the shooting under pressure metric of the field and
+// of every competitor with at least two bouts.
+func printPressure(w io.Writer, competitors map[Bib]*Competitor, targets int) {
+	all, field := fieldPressure(competitors, targets)
+	if len(all) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nShooting under pressure:")
+	fmt.Fprintf(w, "Field: %s\n", field)
+	for _, p := range all {
+		fmt.Fprintf(w, "  competitor(%s) %s\n", p.Bib, p)
+	}
+}
@@ -0,0 +1,46 @@
+package biathlon
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPressure(t *testing.T) {
+	t.Parallel()
+	end := time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)
+	competitor := func(id int, hits ...int) *Competitor {
+		c := &Competitor{ID: id}
+		for i, h := range hits {
+			c.Bouts = append(c.Bouts, Bout{Index: i + 1, End: end, Hits: h})
+		}
+		return c
+	}
+	competitors := map[Bib]*Competitor{
+		// Cracks under pressure: 5 and 5, then 2.
+		{Number: 1}: competitor(1, 5, 5, 2),
+		// Clean only when it matters.
+		{Number: 2}: competitor(2, 0, 4),
+		// A single bout isn't enough.
+		{Number: 3}: competitor(3, 5),
+	}
+
+	all, field := fieldPressure(competitors, 5)
+	require.Len(t, all, 2)
+	index, ok := all[0].Index()
+	require.True(t, ok)
+	require.InDelta(t, 0.4, index, 1e-9)
+	_, ok = all[1].Index()
+	require.False(t, ok)
+	require.InDelta(t, 0.6, field.Final, 1e-9)
+	require.InDelta(t, 0.5, field.Earlier, 1e-9)
+
+	var out bytes.Buffer
+	printPressure(&out, competitors, 5)
+	require.Equal(t, "\nShooting under pressure:\n"+
+		"Field: final 60%, earlier 50%, index 1.20\n"+
+		"  competitor(1) final 40%, earlier 100%, index 0.40\n"+
+		"  competitor(2) final 80%, earlier 0%, difference +80%\n", out.String())
+}
@@ -0,0 +1,282 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// LogLine is one line of the race commentary.
+type LogLine string
+
+// handler applies one kind of event to competitor c and returns the
+// commentary lines and warnings it produced. c is nil when the event's
+// competitor isn't known yet.
+type handler func(p *Processor, c *Competitor, e Event) ([]LogLine, []Warning, error)
+
+// defaultHandlers maps every incoming EventID to its handler.
+var defaultHandlers = map[int]handler{
+	register:              handleRegister,
+	startTime:             handleStartTime,
+	startLine:             handleStartLine,
+	isStarted:             handleIsStarted,
+	onTheFiringRange:      handleOnTheFiringRange,
+	hit:                   handleHit,
+	leftTheFiringRange:    handleLeftTheFiringRange,
+	enteredThePenaltyLaps: handleEnteredThePenaltyLaps,
+	leftThePenaltyLaps:    handleLeftThePenaltyLaps,
+	endedTheMainLap:       handleEndedTheMainLap,
+	comment:               handleComment,
+	shot:                  handleShot,
+}
+
+// Processor applies race events in order, logs the commentary to log and
+// keeps the state of every competitor.
+type Processor struct {
+	cfg       Config
+	profile   *CourseProfile
+	baseStart time.Time
+	delta     time.Duration
+	decisions Decisions
+	rules     []Rule
+	cutoffs   map[int]time.Duration
+	feed      *checkpointFeed
+	log       Logger
+
+	// enforceEntryRules turns entry rule warnings into errors.
+	enforceEntryRules bool
+	// registerOrphans creates a competitor for the events of one who never
+	// registered instead of skipping them.
+	registerOrphans bool
+	// strictTargets turns hits without a valid target number into errors.
+	strictTargets bool
+	// resumeWindow is how long after leaving the range a return resumes
+	// the bout, 0 when resumes aren't detected.
+	resumeWindow time.Duration
+
+	handlers map[int]handler
+	quality  dataQuality
+	// warnings counts the warnings and alerts written to log and the
+	// skipped orphan events.
+	warnings     int
+	competitors  map[Bib]*Competitor
+	slots        slotMap
+	lapCrossings map[int]int
+	startLines   *startLineWatch
+	// courseOpened and courseClosed bound the on-course events, each
+	// unchecked while zero.
+	courseOpened time.Time
+	courseClosed time.Time
+	timeline     []TimelineEntry
+	// heartbeats tracks the heartbeats of the timing system, and knownGaps
+	// are the telemetry gaps found in the events ahead of processing.
+	heartbeats *heartbeatWatch
+	knownGaps  []TelemetryGap
+
+	// source is the race p was made for, and applied the events given to
+	// Process, which CompetitorCommentary replays, less the droppedEvents
+	// oldest over the limit; finished marks the end of the events.
+	// retained is the commentary kept for Commentary.
+	source        race
+	applied       []Event
+	droppedEvents int
+	finished      bool
+	retained      *commentaryBuffer
+}
+
+// newProcessor returns a Processor for the race writing the whole
+// commentary to out as text. Checkpoint crossings are written to feed unless
+// it is nil.
+func newProcessor(r race, feed *checkpointFeed, out io.Writer) *Processor {
+	return newLoggingProcessor(r, feed, textLogger{w: out})
+}
+
+// newLoggingProcessor returns a Processor for the race logging the
+// commentary to log.
+func newLoggingProcessor(r race, feed *checkpointFeed, log Logger) *Processor {
+	retained := &commentaryBuffer{}
+	p := &Processor{
+		cfg:          r.cfg,
+		profile:      r.profile,
+		baseStart:    r.baseStart,
+		delta:        r.delta,
+		decisions:    r.decisions,
+		rules:        r.rules,
+		cutoffs:      r.cutoffs,
+		resumeWindow: r.resumeWindow,
+		feed:         feed,
+		log:          teeLogger{log, retained},
+		handlers:     make(map[int]handler, len(defaultHandlers)),
+		competitors:  make(map[Bib]*Competitor),
+		slots:        make(slotMap),
+		lapCrossings: make(map[int]int),
+		startLines:   newStartLineWatch(r.startLineTimeout),
+		heartbeats:   &heartbeatWatch{timeout: r.heartbeatTimeout},
+		source:       r,
+		retained:     retained,
+	}
+	for id, h := range defaultHandlers {
+		p.handlers[id] = h
+	}
+	return p
+}
+
+// NewProcessor returns a Processor for a race run by cfg, writing the
+// commentary to out.
+func NewProcessor(cfg Config, out io.Writer) (*Processor, error) {
+	r, err := newRace(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newProcessor(r, nil, out), nil
+}
+
+// NewLoggingProcessor returns a Processor for a race run by cfg, logging
+// the commentary to log.
+func NewLoggingProcessor(cfg Config, log Logger) (*Processor, error) {
+	r, err := newRace(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newLoggingProcessor(r, nil, log), nil
+}
+
+// registerHandler makes p handle eventID with h, replacing any handler
+// registered before.
+func (p *Processor) registerHandler(eventID int, h handler) {
+	p.handlers[eventID] = h
+}
+
+// Tick advances the race clock to now and alerts about the competitors
+// whose start line timeout expired by then, and about the heartbeats of the
+// timing system stopping. Process ticks to the time of every event; a live
+// feed may tick between events too.
+func (p *Processor) Tick(now time.Time) {
+	for _, a := range p.startLines.expire(now) {
+		p.warn(alertRecord(a, p.startLines.timeout))
+	}
+	if r, ok := p.heartbeats.expire(now); ok {
+		p.warn(r)
+	}
+}
+
+// Process applies a single event. Race-level events go to the timeline.
+// Events for non-positive competitor ids are counted and ignored, on-course
+// events outside the course window and the events of a competitor pulled
+// for a cutoff are ignored with a warning, and those for a competitor who
+// never registered are skipped into the data quality summary unless
+// registerOrphans is set.
+func (p *Processor) Process(e Event) error {
+	p.keep(e)
+	p.Tick(e.Time)
+	comp := p.competitors[e.Bib()]
+	// The warnings of the parser are about malformed events.
+	for _, w := range e.Warnings {
+		p.warnEvent(LevelError, e, w)
+	}
+	if e.EventID == heartbeat {
+		p.heartbeats.beat(e.Time)
+		return nil
+	}
+	if h, ok := raceHandlers[e.EventID]; ok {
+		p.processRace(h, e)
+		return nil
+	}
+	if e.CompetitorID <= 0 {
+		p.quality.NonPositiveCompetitors++
+		return nil
+	}
+	h, ok := p.handlers[e.EventID]
+	if !ok {
+		p.log.Log(eventRecord(LevelError, e, fmt.Sprintf("Unknown EventId %d. The EventID must be in the range [1, 15]", e.EventID)))
+		return nil
+	}
+	if w, outside := outsideCourse(e, p.courseOpened, p.courseClosed); outside {
+		p.warnEvent(LevelWarn, e, w)
+		return nil
+	}
+	// Custom events are left to their handler, which may not need a
+	// competitor.
+	if _, builtin := defaultHandlers[e.EventID]; builtin && comp == nil && e.EventID != register {
+		w := Warning{Code: WarnUnregisteredCompetitor, Message: fmt.Sprintf("event %d for a competitor who never registered", e.EventID)}
+		if !p.registerOrphans {
+			p.quality.Orphans = append(p.quality.Orphans, warningLine(e, w))
+			p.warnings++
+			return nil
+		}
+		comp = &Competitor{ID: e.CompetitorID, Suffix: e.Suffix}
+		p.competitors[e.Bib()] = comp
+		p.warnEvent(LevelWarn, e, w)
+	}
+	if comp != nil && comp.pulled != 0 {
+		p.warnEvent(LevelWarn, e, Warning{Code: WarnPulledCompetitor, Message: fmt.Sprintf("event %d of a competitor pulled after lap %d is ignored", e.EventID, comp.pulled)})
+		return nil
+	}
+	lines, warnings, err := h(p, comp, e)
+	if err != nil {
+		return err
+	}
+	gap := p.duringTelemetryGap(e.Time)
+	for _, line := range lines {
+		if gap {
+			line += " (during telemetry gap)"
+		}
+		p.narrate(e, line)
+	}
+	for _, w := range warnings {
+		p.warnEvent(LevelWarn, e, w)
+	}
+	if err := p.feed.record(e, comp, p.cfg); err != nil {
+		return fmt.Errorf("checkpoint feed error: %w", err)
+	}
+	return nil
+}
+
+// Competitors returns the state of every registered competitor.
+func (p *Processor) Competitors() map[Bib]*Competitor {
+	return p.competitors
+}
+
+// Results returns the results of the events applied so far in ranking
+// order.
+func (p *Processor) Results() []Result {
+	return results(p.competitors, p.cfg, p.profile)
+}
+
+// ProcessAll applies events in order, stopping at the first error. The
+// competitors still on the start line after the last event are alerted
+// about, since no start can follow any more.
+func (p *Processor) ProcessAll(events []Event) error {
+	return p.ProcessWithBulletins(events, nil, nil)
+}
+
+// finish alerts about the competitors still on the start line once all
+// the events are applied, and records the telemetry gaps.
+func (p *Processor) finish() {
+	for _, a := range p.startLines.close() {
+		p.warn(alertRecord(a, p.startLines.timeout))
+	}
+	p.finished = true
+	p.heartbeats.close()
+	p.quality.TelemetryGaps = p.heartbeats.gaps
+	for _, c := range p.competitors {
+		if c.Started {
+			c.lateStart = c.startedLate(p.delta)
+		}
+	}
+}
+
+// process applies all the race events, printing the commentary to stdout.
+func process(r race, feed *checkpointFeed) (map[Bib]*Competitor, error) {
+	p := newProcessor(r, feed, os.Stdout)
+	if err := p.ProcessAll(r.events); err != nil {
+		return nil, err
+	}
+	return p.Competitors(), nil
+}
+
+// logf formats a commentary line about event e, prefixed with its time.
+func logf(e Event, format string, args ...any) LogLine {
+	return LogLine(fmt.Sprintf("[%s] ", e.RawTime) + fmt.Sprintf(format, args...))
+}
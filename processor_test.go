@@ -0,0 +1,433 @@
+package biathlon
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"BiathlonCompetitions/parser"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestProcessorGolden(t *testing.T) {
+	r, err := loadRace("config/config.json", "", "events", false)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	printReport(&out, p, r, reportStyle{locale: locales["en"]})
+	got := out.String()
+
+	golden := "testdata/events.golden"
+	if *update {
+		require.NoError(t, os.WriteFile(golden, []byte(got), 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	require.Equal(t, string(want), got)
+}
+
+// TestRunGoldenTwice runs the whole pipeline with every report section
+// twice and requires byte-identical output matching the golden file.
+func TestRunGoldenTwice(t *testing.T) {
+	var outputs []string
+	for i := 0; i < 2; i++ {
+		var out bytes.Buffer
+		require.Equal(t, 0, Run([]string{"-verbose", "-whatif"}, &out, &bytes.Buffer{}))
+		outputs = append(outputs, out.String())
+	}
+	require.Equal(t, outputs[0], outputs[1])
+
+	golden := "testdata/run.golden"
+	if *update {
+		require.NoError(t, os.WriteFile(golden, []byte(outputs[0]), 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	require.Equal(t, string(want), outputs[0])
+}
+
+// TestRunShuffledEvents requires the same output whatever the order of the
+// lines in the events file, including events at the same time.
+func TestRunShuffledEvents(t *testing.T) {
+	data, err := os.ReadFile("events")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	lines = append(lines,
+		"[09:40:00.000] 1 7",
+		"[09:40:00.000] 1 6",
+		"[10:40:00.000] 11 5 Lost a pole",
+		"[10:40:00.000] 11 4 Broken ski",
+	)
+	output := func(lines []string) string {
+		path := filepath.Join(t.TempDir(), "events")
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644))
+		var out bytes.Buffer
+		require.Equal(t, 0, Run([]string{"-events", path, "-verbose", "-whatif"}, &out, &bytes.Buffer{}))
+		return out.String()
+	}
+
+	want := output(lines)
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 5; i++ {
+		shuffled := append([]string(nil), lines...)
+		rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		require.Equal(t, want, output(shuffled))
+	}
+}
+
+// TestEventSeqSurvivesMerging requires every event to keep its sequence
+// number whatever the order two merged logs are concatenated in.
+func TestEventSeqSurvivesMerging(t *testing.T) {
+	t.Parallel()
+	first := "[09:31:49.285] 1 1\n[09:55:00.000] 2 1 10:00:00.000\n[10:00:01.744] 4 1\n[10:08:49.289] 5 1 1\n"
+	second := "[09:32:17.531] 1 2\n[09:55:00.000] 2 2 10:01:30.000\n[10:01:31.000] 4 2\n[10:08:49.289] 5 2 1\n"
+	numbers := func(logs ...string) map[string]int {
+		path := filepath.Join(t.TempDir(), "events")
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(logs, "")), 0o644))
+		r, err := loadRace("config/config.json", "", path, false)
+		require.NoError(t, err)
+		seqs := make(map[string]int, len(r.events))
+		for i, e := range r.events {
+			require.Equal(t, i+1, e.Seq)
+			seqs[fmt.Sprintf("[%s] %d %s %s", e.RawTime, e.EventID, e.Bib(), e.Extra)] = e.Seq
+		}
+		return seqs
+	}
+
+	want := numbers(first, second)
+	require.Len(t, want, 8)
+	require.Equal(t, 1, want["[09:31:49.285] 1 1 "])
+	require.Equal(t, 7, want["[10:08:49.289] 5 1 1"])
+	require.Equal(t, want, numbers(second, first))
+}
+
+func TestEventSeqInMessages(t *testing.T) {
+	t.Parallel()
+	events, err := parser.DecodeEvents(strings.NewReader("[10:00:01.744] 4 1\n[10:00:01.800] 4 1\n[10:00:02.000] 13 0\n"))
+	require.NoError(t, err)
+	numberEvents(events)
+	kept, stats := dedupeMirrored(events, 250*time.Millisecond)
+	require.Equal(t, []int{1, 3}, []int{kept[0].Seq, kept[1].Seq}, "the kept events keep their numbers")
+
+	require.Equal(t, "[10:00:01.744] Warning for competitor(1) (event #1): late_start: late", warningLine(events[0], Warning{Code: "late_start", Message: "late"}))
+	var out bytes.Buffer
+	printMirrorStats(&out, stats)
+	require.Equal(t, "Mirrored log: 1 paired events, 1 unpaired\n[10:00:02.000] Unpaired event 13 for competitor(0) (event #3)\n", out.String())
+	out.Reset()
+	printTimeline(&out, []TimelineEntry{{Time: events[2].Time, EventID: courseOpened, Note: "by forerunners", Seq: 3}})
+	require.Equal(t, "\nRace timeline:\n[10:00:02.000] course opened (event #3): by forerunners\n", out.String())
+
+	events[0].Seq = 0
+	require.Equal(t, "[10:00:01.744] Warning for competitor(1): late_start: late", warningLine(events[0], Warning{Code: "late_start", Message: "late"}))
+}
+
+func TestProcessorUnknownEvent(t *testing.T) {
+	r := newTestRace(t)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	e, err := parser.ParseEvent("[10:00:00.000] 42 1")
+	require.NoError(t, err)
+	require.NoError(t, p.Process(e))
+	require.Equal(t, "[10:00:00.000] Unknown EventId 42. The EventID must be in the range [1, 15]\n", out.String())
+}
+
+// TestQuietCommentary requires a commentary logged from the warn level to
+// leave out the narration of every event but keep the warnings about them.
+func TestQuietCommentary(t *testing.T) {
+	r := newTestRace(t, "[09:30:00.000] 1 1", "[09:45:00.000] 2 1 10:00:00.000", "[10:00:00.500] 4 1",
+		"[10:05:00.000] 6 1 1", "[10:06:00.000] 42 1", "[10:12:40.000] 10 1")
+	var out bytes.Buffer
+	p := newLoggingProcessor(r, nil, textLogger{w: &out, level: LevelWarn})
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Equal(t, "[10:05:00.000] Warning for competitor(1): hit_outside_bout: hit outside of a firing range visit\n"+
+		"[10:06:00.000] Unknown EventId 42. The EventID must be in the range [1, 15]\n", out.String())
+	require.Equal(t, 1, p.Competitors()[Bib{Number: 1}].LapsCompleted, "the events are still applied")
+}
+
+func TestRunQuiet(t *testing.T) {
+	var stdout bytes.Buffer
+	require.Equal(t, 0, Run([]string{"-quiet"}, &stdout, &bytes.Buffer{}))
+	require.NotContains(t, stdout.String(), "The competitor(1) registered")
+	require.True(t, strings.HasPrefix(stdout.String(), "\nFinal results:\n"), stdout.String())
+}
+
+func TestProcessorRegisterHandler(t *testing.T) {
+	r := newTestRace(t)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	p.registerHandler(42, func(_ *Processor, _ *Competitor, e Event) ([]LogLine, []Warning, error) {
+		return []LogLine{logf(e, "custom event")}, nil, nil
+	})
+	e, err := parser.ParseEvent("[10:00:00.000] 42 1")
+	require.NoError(t, err)
+	require.NoError(t, p.Process(e))
+	require.Equal(t, "[10:00:00.000] custom event\n", out.String())
+}
+
+// runHandler parses line and applies it to c with h.
+func runHandler(t *testing.T, p *Processor, h handler, c *Competitor, line string) ([]LogLine, []Warning) {
+	t.Helper()
+	e, err := parser.ParseEvent(line)
+	require.NoError(t, err)
+	lines, warnings, err := h(p, c, e)
+	require.NoError(t, err)
+	return lines, warnings
+}
+
+func TestHandleRegister(t *testing.T) {
+	p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+	lines, _ := runHandler(t, p, handleRegister, nil, "[09:31:49.285] 1 7b")
+	require.Equal(t, []LogLine{"[09:31:49.285] The competitor(7b) registered"}, lines)
+	require.Equal(t, &Competitor{ID: 7, Suffix: "b"}, p.competitors[Bib{Number: 7, Suffix: "b"}])
+}
+
+func TestHandleStartTime(t *testing.T) {
+	p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+	c := &Competitor{ID: 1}
+	lines, warnings := runHandler(t, p, handleStartTime, c, "[09:55:00.000] 2 1 10:01:30.000")
+	require.Equal(t, []LogLine{"[09:55:00.000] The start time for the competitor(1) was set by a draw to 10:01:30.000 (slot #2)"}, lines)
+	require.Empty(t, warnings)
+	require.Equal(t, "10:01:30.000", c.StartTime.Format(timeLayout))
+
+	_, warnings = runHandler(t, p, handleStartTime, &Competitor{ID: 2}, "[09:56:00.000] 2 2 10:01:30.000")
+	require.Len(t, warnings, 1)
+	require.Equal(t, WarnSlotCollision, warnings[0].Code)
+}
+
+func TestHandleStartLine(t *testing.T) {
+	p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+	lines, _ := runHandler(t, p, handleStartLine, &Competitor{ID: 1}, "[09:59:45.000] 3 1")
+	require.Equal(t, []LogLine{"[09:59:45.000] The competitor is on the start line"}, lines)
+}
+
+func TestHandleIsStarted(t *testing.T) {
+	p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+	start, _ := time.Parse(timeLayout, "10:00:00.000")
+
+	onTime := &Competitor{ID: 1, StartTime: start}
+	lines, _ := runHandler(t, p, handleIsStarted, onTime, "[10:00:01.744] 4 1")
+	require.Equal(t, []LogLine{"[10:00:01.744] The competitor(1) has started"}, lines)
+	require.True(t, onTime.Started)
+	require.False(t, onTime.lateStart)
+
+	late := &Competitor{ID: 2, StartTime: start}
+	lines, _ = runHandler(t, p, handleIsStarted, late, "[10:01:30.001] 4 2")
+	require.Equal(t, []LogLine{
+		"[10:01:30.001] The competitor(2) is disqualified for late start",
+		"[10:01:30.001] The competitor(2) has started",
+	}, lines)
+	require.True(t, late.lateStart)
+}
+
+func TestHandleFiringRange(t *testing.T) {
+	p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+	c := &Competitor{ID: 1}
+	lines, _ := runHandler(t, p, handleOnTheFiringRange, c, "[10:08:49.289] 5 1 2")
+	require.Equal(t, []LogLine{"[10:08:49.289] The competitor(1) is on the firing range (shooting 1, line 2)"}, lines)
+	require.Len(t, c.Bouts, 1)
+	require.Equal(t, 2, c.Bouts[0].Line)
+	require.Equal(t, 1, c.Bouts[0].Index)
+
+	lines, _ = runHandler(t, p, handleHit, c, "[10:08:50.884] 6 1 3")
+	require.Equal(t, []LogLine{"[10:08:50.884] The target has been hit (3) by competitor(1)"}, lines)
+	require.Equal(t, 1, c.Hits)
+
+	lines, _ = runHandler(t, p, handleLeftTheFiringRange, c, "[10:08:55.658] 7 1")
+	require.Equal(t, []LogLine{"[10:08:55.658] The competitor(1) left the firing range (0)"}, lines)
+	require.Equal(t, "10:08:55.658", c.Bouts[0].End.Format(timeLayout))
+	require.Equal(t, []time.Duration{6369 * time.Millisecond}, c.RangeTimes)
+}
+
+func TestHandlePenaltyLaps(t *testing.T) {
+	p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+	c := &Competitor{ID: 1}
+	lines, _ := runHandler(t, p, handleEnteredThePenaltyLaps, c, "[10:09:03.232] 8 1")
+	require.Equal(t, []LogLine{"[10:09:03.232] The competitor(1) entered the penalty laps"}, lines)
+	lines, _ = runHandler(t, p, handleLeftThePenaltyLaps, c, "[10:10:43.232] 9 1")
+	require.Equal(t, []LogLine{"[10:10:43.232] The competitor(1) left the penalty laps"}, lines)
+	require.Equal(t, []time.Duration{100 * time.Second}, c.PenaltyTimes)
+}
+
+func TestHandleEndedTheMainLap(t *testing.T) {
+	p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+	c := &Competitor{ID: 1}
+	lines, _ := runHandler(t, p, handleEndedTheMainLap, c, "[10:12:35.380] 10 1")
+	require.Equal(t, []LogLine{"[10:12:35.380] The competitor(1) ended the main lap"}, lines)
+	require.Equal(t, 1, c.LapsCompleted)
+	require.Equal(t, []int{1}, c.RoadPositions)
+
+	runHandler(t, p, handleEndedTheMainLap, &Competitor{ID: 2}, "[10:14:09.746] 10 2")
+	require.Equal(t, 2, p.lapCrossings[1])
+}
+
+func TestHandleComment(t *testing.T) {
+	p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+	c := &Competitor{ID: 1}
+	lines, _ := runHandler(t, p, handleComment, c, "[10:30:00.000] 11 1 Lost in the forest")
+	require.Equal(t, []LogLine{"[10:30:00.000] The competitor(1) can`t continue: Lost in the forest"}, lines)
+	require.True(t, c.retired)
+}
+
+func TestHandleHitOutsideBout(t *testing.T) {
+	p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+	c := &Competitor{ID: 1}
+	lines, warnings := runHandler(t, p, handleHit, c, "[10:08:50.000] 6 1 1")
+	require.Equal(t, []LogLine{"[10:08:50.000] The target has been hit (1) by competitor(1)"}, lines)
+	require.Equal(t, []Warning{{Code: WarnHitOutsideBout, Message: "hit outside of a firing range visit"}}, warnings)
+	require.Equal(t, 1, c.Hits)
+
+	runHandler(t, p, handleOnTheFiringRange, c, "[10:08:49.289] 5 1 1")
+	_, warnings = runHandler(t, p, handleHit, c, "[10:08:51.000] 6 1 2")
+	require.Empty(t, warnings)
+	require.Equal(t, 1, c.Bouts[0].Hits)
+}
+
+func TestHitTargetValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		hit     string
+		hits    int
+		warning string
+		err     string
+	}{
+		{name: "valid", hit: "[10:08:50.000] 6 1 5", hits: 1},
+		{
+			name:    "out of range",
+			hit:     "[10:08:50.000] 6 1 6",
+			warning: "[10:08:50.000] Warning for competitor(1): target_out_of_range: target 6 is outside 1..5, the hit is ignored\n",
+			err:     "invalid target number: [10:08:50.000] competitor(1): target 6 is outside 1..5, the hit is ignored",
+		},
+		{
+			name:    "garbage",
+			hit:     "[10:08:50.000] 6 1 five",
+			warning: "[10:08:50.000] Warning for competitor(1): invalid_target_number: target \"five\" is not a number\n",
+			err:     "invalid target number: [10:08:50.000] competitor(1): target \"five\" is not a number",
+		},
+		{
+			name:    "empty",
+			hit:     "[10:08:50.000] 6 1",
+			warning: "[10:08:50.000] Warning for competitor(1): invalid_target_number: hit without a target number\n",
+			err:     "invalid target number: [10:08:50.000] competitor(1): hit without a target number",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r := newTestRace(t, "[09:31:49.285] 1 1", "[10:08:49.289] 5 1 1", test.hit)
+			var out bytes.Buffer
+			p := newProcessor(r, nil, &out)
+			require.NoError(t, p.ProcessAll(r.events))
+			c := p.Competitors()[Bib{Number: 1}]
+			require.Equal(t, test.hits, c.Hits)
+			require.Equal(t, test.hits, c.Bouts[0].Hits)
+			if test.warning == "" {
+				require.NotContains(t, out.String(), "Warning")
+				return
+			}
+			require.Contains(t, out.String(), test.warning)
+			require.NotContains(t, out.String(), "The target has been hit")
+
+			p = newProcessor(r, nil, &bytes.Buffer{})
+			p.strictTargets = true
+			err := p.ProcessAll(r.events)
+			require.ErrorIs(t, err, ErrInvalidTarget)
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestDuplicateHit(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[10:08:49.289] 5 1 1",
+		"[10:08:50.000] 6 1 1",
+		"[10:08:51.000] 6 1 2",
+		"[10:08:52.000] 6 1 3",
+		"[10:08:52.100] 6 1 3",
+		"[10:08:53.000] 6 1 4",
+		"[10:08:54.000] 6 1 5",
+		"[10:08:55.658] 7 1",
+		// The same target may be hit again in the next bout.
+		"[10:21:34.847] 5 1 2",
+		"[10:21:36.000] 6 1 3",
+		"[10:21:41.449] 7 1",
+	)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+
+	c := p.Competitors()[Bib{Number: 1}]
+	require.Equal(t, 6, c.Hits)
+	require.Equal(t, 5, c.Bouts[0].Hits)
+	require.Equal(t, []int{1, 2, 3, 4, 5}, c.Bouts[0].HitTargets)
+	require.Equal(t, 1, c.Bouts[1].Hits)
+	require.Contains(t, out.String(), "[10:08:52.100] Warning for competitor(1): duplicate_hit: competitor(1) hit target 3 again in bout 1, the hit is ignored\n")
+	require.Equal(t, 1, strings.Count(out.String(), "duplicate_hit"))
+}
+
+func TestRangeExitWithoutArrival(t *testing.T) {
+	p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+	c := &Competitor{ID: 1}
+	lines, warnings := runHandler(t, p, handleLeftTheFiringRange, c, "[10:08:55.658] 7 1")
+	require.Equal(t, []LogLine{"[10:08:55.658] The competitor(1) left the firing range (0)"}, lines)
+	require.Equal(t, []Warning{{Code: WarnExitWithoutArrival, Message: "left the firing range without arriving on it, no range time"}}, warnings)
+	require.Empty(t, c.RangeTimes)
+}
+
+func TestHandleShot(t *testing.T) {
+	p := newProcessor(newTestRace(t), nil, &bytes.Buffer{})
+	c := &Competitor{ID: 1}
+	lines, warnings := runHandler(t, p, handleShot, c, "[10:08:50.000] 12 1 hit")
+	require.Empty(t, lines)
+	require.Equal(t, []Warning{{Code: WarnShotOutsideBout, Message: "shot outside of a firing range visit"}}, warnings)
+
+	runHandler(t, p, handleOnTheFiringRange, c, "[10:08:49.289] 5 1 1")
+	lines, warnings = runHandler(t, p, handleShot, c, "[10:08:51.000] 12 1 miss")
+	require.Equal(t, []LogLine{"[10:08:51.000] The competitor(1) fired a shot (miss)"}, lines)
+	require.Empty(t, warnings)
+	require.Equal(t, []Shot{{Time: c.Bouts[0].Start.Add(1711 * time.Millisecond), Hit: false}}, c.Bouts[0].Shots)
+}
+
+func TestLapSplits(t *testing.T) {
+	r := newTestRace(t,
+		"[09:30:00.000] 1 1",
+		"[09:30:01.000] 1 2",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[09:55:01.000] 2 2 10:01:30.000",
+		"[10:00:02.000] 4 1",
+		"[10:01:31.000] 4 2",
+		"[10:10:00.000] 10 1",
+		"[10:20:30.500] 10 1",
+		"[10:29:45.250] 10 1",
+		"[10:12:00.000] 10 2",
+		"[10:15:00.000] 11 2 Broken ski",
+	)
+	r.cfg.Laps = 3
+	p := newProcessor(r, nil, &bytes.Buffer{})
+	require.NoError(t, p.ProcessAll(r.events))
+
+	finisher := p.Competitors()[Bib{Number: 1}]
+	require.Equal(t, []time.Duration{
+		10 * time.Minute,
+		10*time.Minute + 30*time.Second + 500*time.Millisecond,
+		9*time.Minute + 14*time.Second + 750*time.Millisecond,
+	}, finisher.lapTimes)
+	require.Equal(t, finisher.totalTime(), finisher.lapTimes[0]+finisher.lapTimes[1]+finisher.lapTimes[2])
+
+	// The lap the competitor couldn't finish is measured from the last lap end.
+	require.Equal(t, []time.Duration{10*time.Minute + 30*time.Second, 3 * time.Minute}, p.Competitors()[Bib{Number: 2}].lapTimes)
+
+	res := results(p.Competitors(), r.cfg, nil)
+	require.InDelta(t, float64(r.cfg.LapLen)/630.5, res[0].Laps[1].Speed, 1e-9)
+}
@@ -0,0 +1,71 @@
+package biathlon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// climbEquivalent is how many flat meters one meter of climbing is worth.
+	climbEquivalent = 8
+	// descentEquivalent is how many flat meters one meter of descent saves.
+	descentEquivalent = 2
+)
+
+// LapProfile is the elevation change over one main lap, in meters.
+type LapProfile struct {
+	Climb   float64 `json:"climb"`
+	Descent float64 `json:"descent"`
+}
+
+// CourseProfile describes the elevation of every main lap of the course.
+type CourseProfile struct {
+	Laps []LapProfile `json:"laps"`
+}
+
+// profilePath resolves the profile file of cfg: relative paths are taken
+// from the directory of the config file.
+func profilePath(cfg Config, configPath string) string {
+	if filepath.IsAbs(cfg.Profile) {
+		return cfg.Profile
+	}
+	return filepath.Join(filepath.Dir(configPath), cfg.Profile)
+}
+
+// loadProfile reads the course profile referenced by cfg.Profile. Relative
+// paths are resolved against the directory of the config file. A config
+// without a profile yields a nil profile.
+func loadProfile(cfg Config, configPath string) (profile *CourseProfile, err error) {
+	if cfg.Profile == "" {
+		return nil, nil
+	}
+	path := profilePath(cfg, configPath)
+	f, err := openConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func(f *os.File) {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+	profile = &CourseProfile{}
+	if err := json.NewDecoder(f).Decode(profile); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(profile.Laps) != cfg.Laps {
+		return nil, fmt.Errorf("%s: course profile describes %d laps, config has %d", path, len(profile.Laps), cfg.Laps)
+	}
+	return profile, nil
+}
+
+// climbAdjustedSpeed returns the speed over a lap of lapLen meters as if the
+// climbing and descent were flat distance: each meter of climb adds
+// climbEquivalent meters and each meter of descent removes descentEquivalent.
+func climbAdjustedSpeed(lapLen float64, lap LapProfile, d time.Duration) float64 {
+	distance := lapLen + climbEquivalent*lap.Climb - descentEquivalent*lap.Descent
+	return distance / d.Seconds()
+}
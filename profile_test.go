@@ -0,0 +1,63 @@
+package biathlon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClimbAdjustedSpeed(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		lap       LapProfile
+		expecting float64
+	}{
+		{
+			name:      "test_flat_lap",
+			lap:       LapProfile{},
+			expecting: 5,
+		},
+		{
+			name:      "test_climb_only",
+			lap:       LapProfile{Climb: 100},
+			expecting: 5.8,
+		},
+		{
+			name:      "test_climb_and_descent",
+			lap:       LapProfile{Climb: 100, Descent: 100},
+			expecting: 5.6,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			speed := climbAdjustedSpeed(5000, test.lap, 1000*time.Second)
+			require.InDelta(t, test.expecting, speed, 1e-9)
+		})
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	err := os.WriteFile(filepath.Join(dir, "profile.json"), []byte(`{"laps": [{"climb": 120, "descent": 110}, {"climb": 95, "descent": 100}]}`), 0o644)
+	require.NoError(t, err)
+
+	profile, err := loadProfile(Config{Laps: 2}, configPath)
+	require.NoError(t, err)
+	require.Nil(t, profile)
+
+	profile, err = loadProfile(Config{Laps: 2, Profile: "profile.json"}, configPath)
+	require.NoError(t, err)
+	require.Equal(t, []LapProfile{{Climb: 120, Descent: 110}, {Climb: 95, Descent: 100}}, profile.Laps)
+
+	_, err = loadProfile(Config{Laps: 3, Profile: "profile.json"}, configPath)
+	require.Error(t, err)
+
+	_, err = loadProfile(Config{Laps: 2, Profile: "missing.json"}, configPath)
+	require.Error(t, err)
+}
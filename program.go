@@ -0,0 +1,308 @@
+package biathlon
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"BiathlonCompetitions/parser"
+)
+
+// Program is the daily program exported by the timing office: the race,
+// its scheduled start and the entry list, one entry per CSV row.
+type Program struct {
+	Race       string
+	Discipline string
+	// Start is the scheduled start, zero when the program has none.
+	Start   time.Time
+	Entries []ProgramEntry
+	// Unmapped describes what couldn't be mapped: unknown columns, rows
+	// without a valid bib and race fields with conflicting values.
+	Unmapped []string
+}
+
+// ProgramEntry is a competitor of the program. StartTime is the drawn
+// start, zero when the program doesn't carry the draw.
+type ProgramEntry struct {
+	Bib       Bib
+	Name      string
+	Nation    string
+	StartTime time.Time
+}
+
+// Program fields, by the column names the office exports use for them.
+const (
+	programRace       = "race"
+	programDiscipline = "discipline"
+	programStart      = "start"
+	programBib        = "bib"
+	programName       = "name"
+	programNation     = "nation"
+	programStartTime  = "startTime"
+)
+
+var programColumns = map[string]string{
+	"race":           programRace,
+	"racename":       programRace,
+	"event":          programRace,
+	"discipline":     programDiscipline,
+	"format":         programDiscipline,
+	"start":          programStart,
+	"scheduledstart": programStart,
+	"bib":            programBib,
+	"startnumber":    programBib,
+	"name":           programName,
+	"athlete":        programName,
+	"nation":         programNation,
+	"country":        programNation,
+	"noc":            programNation,
+	"starttime":      programStartTime,
+	"draw":           programStartTime,
+}
+
+// ErrInvalidProgram is returned for a daily program without an entry list.
+var ErrInvalidProgram = errors.New("invalid daily program")
+
+// Lead times of the imported events before the scheduled start.
+const (
+	programRegisterLead = 30 * time.Minute
+	programDrawLead     = 15 * time.Minute
+)
+
+// readProgram reads a daily program from r as CSV with a header row.
+// Column names match case-insensitively, ignoring spaces and underscores.
+// Only the bib column is required.
+func readProgram(r io.Reader) (Program, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return Program{}, fmt.Errorf("%w: %w", ErrInvalidProgram, err)
+	}
+	if len(rows) == 0 {
+		return Program{}, fmt.Errorf("%w: no header row", ErrInvalidProgram)
+	}
+	var p Program
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		field, ok := programColumns[normalizeColumn(name)]
+		if !ok {
+			p.Unmapped = append(p.Unmapped, fmt.Sprintf("column %q is not a program field", name))
+			continue
+		}
+		columns[field] = i
+	}
+	if _, ok := columns[programBib]; !ok {
+		return Program{}, fmt.Errorf("%w: no bib column", ErrInvalidProgram)
+	}
+
+	for n, row := range rows[1:] {
+		line := n + 2
+		value := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+		p.setRaceField(&p.Race, programRace, value(programRace), line)
+		p.setRaceField(&p.Discipline, programDiscipline, value(programDiscipline), line)
+		if start := value(programStart); start != "" {
+			t, err := parseClock(start)
+			switch {
+			case err != nil:
+				p.Unmapped = append(p.Unmapped, fmt.Sprintf("row %d: start %q is not a time", line, start))
+			case p.Start.IsZero():
+				p.Start = t
+			case !p.Start.Equal(t):
+				p.Unmapped = append(p.Unmapped, fmt.Sprintf("row %d: start %q conflicts with %s", line, start, p.Start.Format(timeLayout)))
+			}
+		}
+
+		bib, err := parser.ParseBib(value(programBib))
+		if err != nil || bib.Number <= 0 {
+			p.Unmapped = append(p.Unmapped, fmt.Sprintf("row %d: bib %q is not a start number, the entry is skipped", line, value(programBib)))
+			continue
+		}
+		entry := ProgramEntry{Bib: bib, Name: value(programName), Nation: value(programNation)}
+		if drawn := value(programStartTime); drawn != "" {
+			if entry.StartTime, err = parseClock(drawn); err != nil {
+				p.Unmapped = append(p.Unmapped, fmt.Sprintf("row %d: start time %q is not a time, competitor(%s) isn't drawn", line, drawn, bib))
+			}
+		}
+		p.Entries = append(p.Entries, entry)
+	}
+	return p, nil
+}
+
+// setRaceField sets a race-level field from the first row carrying it and
+// reports the rows conflicting with it.
+func (p *Program) setRaceField(dst *string, field, value string, line int) {
+	switch {
+	case value == "":
+	case *dst == "":
+		*dst = value
+	case *dst != value:
+		p.Unmapped = append(p.Unmapped, fmt.Sprintf("row %d: %s %q conflicts with %q", line, field, value, *dst))
+	}
+}
+
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.TrimSpace(name)))
+}
+
+// parseClock parses a time of day in the event time format, with or
+// without the milliseconds.
+func parseClock(s string) (time.Time, error) {
+	if t, err := time.Parse(timeLayout, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("15:04:05", s)
+}
+
+// config returns base with the race metadata of the program.
+func (p Program) config(base Config) Config {
+	cfg := base
+	if p.Race != "" {
+		cfg.RaceID = p.Race
+	}
+	if p.Discipline != "" {
+		cfg.Discipline = p.Discipline
+	}
+	if !p.Start.IsZero() {
+		cfg.Start = p.Start.Format(timeLayout)
+	}
+	return cfg
+}
+
+// events returns the event lines registering every entry, in program
+// order, followed by the draw when withDraw is set. They are timed ahead
+// of the scheduled start.
+func (p Program) events(start time.Time, withDraw bool) []string {
+	var lines []string
+	for i, e := range p.Entries {
+		at := start.Add(-programRegisterLead + time.Duration(i)*time.Second)
+		lines = append(lines, fmt.Sprintf("[%s] %d %s", at.Format(timeLayout), register, e.Bib))
+	}
+	if !withDraw {
+		return lines
+	}
+	for i, e := range p.Entries {
+		if e.StartTime.IsZero() {
+			continue
+		}
+		at := start.Add(-programDrawLead + time.Duration(i)*time.Second)
+		lines = append(lines, fmt.Sprintf("[%s] %d %s %s", at.Format(timeLayout), startTime, e.Bib, e.StartTime.Format(timeLayout)))
+	}
+	return lines
+}
+
+// writeStartList writes the entries to path as CSV with a header row.
+func writeStartList(path string, entries []ProgramEntry) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func(f *os.File) {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}(f)
+	cw := csv.NewWriter(f)
+	if err := cw.Write([]string{"competitor", "name", "nation", "startTime"}); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		drawn := ""
+		if !e.StartTime.IsZero() {
+			drawn = e.StartTime.Format(timeLayout)
+		}
+		if err := cw.Write([]string{e.Bib.String(), e.Name, e.Nation, drawn}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// importOptions are the flags of the import-program command.
+type importOptions struct {
+	program    string
+	baseConfig string
+	configOut  string
+	eventsOut  string
+	startList  string
+	draw       bool
+}
+
+func setupImportProgram(fs *flag.FlagSet, stdout io.Writer, s *exitSummary) func() int {
+	var o importOptions
+	fs.StringVar(&o.program, "program", "", "read the daily program from this CSV file")
+	fs.StringVar(&o.baseConfig, "config", "config/config.json", "take the course and shooting settings from this JSON or YAML config")
+	fs.StringVar(&o.configOut, "out-config", "race.json", "write the race config to this file")
+	fs.StringVar(&o.eventsOut, "out-events", "race.log", "write the registrations and the draw to this events file")
+	fs.StringVar(&o.startList, "start-list", "", "write the start list with names and nations as CSV to this file")
+	fs.BoolVar(&o.draw, "draw", true, "import the drawn start times of the program")
+	return func() int { return runImportProgram(o, stdout, s) }
+}
+
+// runImportProgram is the import-program command: it builds the race
+// config, the registrations and the draw from a daily program.
+func runImportProgram(o importOptions, w io.Writer, s *exitSummary) int {
+	if o.program == "" {
+		return s.fail(w, "Program error: -program is required")
+	}
+	base, err := LoadConfig(o.baseConfig)
+	if err != nil {
+		return s.fail(w, "config error:", err)
+	}
+	f, err := os.Open(o.program)
+	if err != nil {
+		return s.fail(w, "Program error:", err)
+	}
+	p, err := readProgram(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return s.fail(w, "Program error:", err)
+	}
+	cfg := p.config(base)
+	start, err := time.Parse(timeLayout, cfg.Start)
+	if err != nil {
+		return s.fail(w, "Program error: invalid start time:", err)
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "    ")
+	if err != nil {
+		return s.fail(w, "Program error:", err)
+	}
+	if err := os.WriteFile(o.configOut, append(data, '\n'), 0o644); err != nil {
+		return s.fail(w, "Program error:", err)
+	}
+	lines := p.events(start, o.draw)
+	if err := os.WriteFile(o.eventsOut, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		return s.fail(w, "Program error:", err)
+	}
+	if o.startList != "" {
+		if err := writeStartList(o.startList, p.Entries); err != nil {
+			return s.fail(w, "Program error:", err)
+		}
+	}
+
+	fmt.Fprintf(w, "Imported %d entries of %s into %s and %s\n", len(p.Entries), cfg.RaceID, o.configOut, o.eventsOut)
+	for _, line := range p.Unmapped {
+		fmt.Fprintln(w, "Program warning:", line)
+	}
+	s.update(func(s *exitSummary) {
+		s.RaceID = cfg.RaceID
+		s.Warnings = len(p.Unmapped)
+	})
+	return 0
+}
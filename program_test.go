@@ -0,0 +1,120 @@
+package biathlon
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProgram(t *testing.T) {
+	f, err := os.Open("testdata/program.csv")
+	require.NoError(t, err)
+	defer f.Close()
+	p, err := readProgram(f)
+	require.NoError(t, err)
+
+	require.Equal(t, "sprint-men-7", p.Race)
+	require.Equal(t, "sprint", p.Discipline)
+	require.Equal(t, "10:00:00.000", p.Start.Format(timeLayout))
+	require.Len(t, p.Entries, 3)
+	require.Equal(t, ProgramEntry{Bib: Bib{Number: 2}, Name: "Ole Hansen", Nation: "NOR", StartTime: p.Start.Add(90 * time.Second)}, p.Entries[1])
+	require.True(t, p.Entries[2].StartTime.IsZero())
+	require.Equal(t, []string{
+		`column "Club" is not a program field`,
+		`row 5: bib "A" is not a start number, the entry is skipped`,
+	}, p.Unmapped)
+}
+
+func TestReadProgramTolerance(t *testing.T) {
+	tests := []struct {
+		name     string
+		program  string
+		entries  int
+		unmapped []string
+		err      bool
+	}{
+		{name: "bibs only", program: "bib\n1\n2\n", entries: 2},
+		{name: "aliases", program: "start_number,COUNTRY,draw\n1,NOR,10:00:00\n", entries: 1},
+		{
+			name:     "conflicting race",
+			program:  "race,bib\nsprint,1\npursuit,2\n",
+			entries:  2,
+			unmapped: []string{`row 3: race "pursuit" conflicts with "sprint"`},
+		},
+		{
+			name:     "bad start time",
+			program:  "bib,start time\n1,ten\n",
+			entries:  1,
+			unmapped: []string{`row 2: start time "ten" is not a time, competitor(1) isn't drawn`},
+		},
+		{name: "no bib column", program: "name\nIvan\n", err: true},
+		{name: "empty", program: "", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			p, err := readProgram(strings.NewReader(test.program))
+			if test.err {
+				require.True(t, errors.Is(err, ErrInvalidProgram))
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, p.Entries, test.entries)
+			require.Equal(t, test.unmapped, p.Unmapped)
+		})
+	}
+}
+
+func TestImportProgramRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "race.json")
+	eventsPath := filepath.Join(dir, "race.log")
+	startListPath := filepath.Join(dir, "start-list.csv")
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"import-program", "-program", "testdata/program.csv", "-config", "config/config.json",
+		"-out-config", configPath, "-out-events", eventsPath, "-start-list", startListPath}, &stdout, &stderr)
+	require.Equal(t, 0, code, stdout.String())
+	require.Contains(t, stdout.String(), "Imported 3 entries of sprint-men-7")
+	require.Contains(t, stdout.String(), `Program warning: column "Club" is not a program field`)
+	require.Contains(t, stderr.String(), `"raceId":"sprint-men-7","finishers":0,"warnings":2`)
+
+	startList, err := os.ReadFile(startListPath)
+	require.NoError(t, err)
+	require.Equal(t, "competitor,name,nation,startTime\n"+
+		"1,Ivan Petrov,RUS,10:00:00.000\n"+
+		"2,Ole Hansen,NOR,10:01:30.000\n"+
+		"3,Martin Fourcade,FRA,\n", string(startList))
+
+	// Race the imported start list: 1 finishes, 2 doesn't start and 3 was
+	// never drawn.
+	f, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString("[09:59:00.000] 3 1\n" +
+		"[10:00:01.000] 4 1\n" +
+		"[10:20:00.000] 10 1\n" +
+		"[10:40:00.000] 10 1\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	r, err := loadRace(configPath, "", eventsPath, false)
+	require.NoError(t, err)
+	require.Equal(t, "sprint-men-7", r.cfg.RaceID)
+	require.Equal(t, "sprint", r.cfg.Discipline)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	results := p.Results()
+	require.Len(t, results, 3)
+	statuses := make(map[int]Status, len(results))
+	for _, res := range results {
+		statuses[res.Bib.Number] = res.Status
+	}
+	require.Equal(t, map[int]Status{1: StatusFinished, 2: StatusNotStarted, 3: StatusNotStarted}, statuses)
+}
@@ -0,0 +1,44 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+)
+
+const WarnUnregisteredCompetitor WarningCode = "unregistered_competitor"
+
+// dataQuality counts input problems that were tolerated during processing.
+type dataQuality struct {
+	// NonPositiveCompetitors is the number of events ignored because their
+	// competitor id was zero or negative.
+	NonPositiveCompetitors int
+	// Orphans are the warning lines of the events skipped because their
+	// competitor never registered.
+	Orphans []string
+	// TelemetryGaps are the windows without heartbeats from the timing
+	// system longer than the heartbeat timeout.
+	TelemetryGaps []TelemetryGap
+}
+
+// printDataQuality prints the data quality summary if there is anything to report.
+func printDataQuality(w io.Writer, q dataQuality) {
+	if q.NonPositiveCompetitors == 0 && len(q.Orphans) == 0 && len(q.TelemetryGaps) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nData quality:")
+	if q.NonPositiveCompetitors > 0 {
+		fmt.Fprintf(w, "%d events with a non-positive competitor id ignored\n", q.NonPositiveCompetitors)
+	}
+	if len(q.Orphans) > 0 {
+		fmt.Fprintf(w, "%d events for unregistered competitors skipped:\n", len(q.Orphans))
+		for _, line := range q.Orphans {
+			fmt.Fprintln(w, line)
+		}
+	}
+	if len(q.TelemetryGaps) > 0 {
+		fmt.Fprintf(w, "%d telemetry gaps without heartbeats, their events are suspect:\n", len(q.TelemetryGaps))
+		for _, g := range q.TelemetryGaps {
+			fmt.Fprintln(w, g)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package biathlon
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"BiathlonCompetitions/parser"
+)
+
+func TestNonPositiveCompetitorIDs(t *testing.T) {
+	for _, line := range []string{"[09:31:49.285] 1 0", "[09:31:49.285] 1 -3"} {
+		e, err := parser.ParseEvent(line)
+		require.NoError(t, err, line)
+		require.Len(t, e.Warnings, 1, line)
+		require.Equal(t, parser.WarnNonPositiveCompetitor, e.Warnings[0].Code, line)
+	}
+
+	r := newTestRace(t,
+		"[09:31:49.285] 1 0",
+		"[09:31:50.000] 1 -3",
+		"[09:32:17.531] 1 2",
+		"[09:55:00.000] 2 0 10:00:00.000",
+	)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Len(t, p.Competitors(), 1)
+	require.Contains(t, p.Competitors(), Bib{Number: 2})
+	require.Equal(t, 3, p.quality.NonPositiveCompetitors)
+	require.Contains(t, out.String(), "[09:31:50.000] Warning for competitor(-3): non_positive_competitor: competitor id -3 is not positive, the event is ignored")
+
+	out.Reset()
+	printDataQuality(&out, p.quality)
+	require.Equal(t, "\nData quality:\n3 events with a non-positive competitor id ignored\n", out.String())
+}
+
+func TestOrphanEvents(t *testing.T) {
+	lines := []string{
+		"[09:31:49.285] 1 1",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[10:00:01.000] 4 1",
+		// Competitor 9 never registered.
+		"[10:08:49.289] 5 9 1",
+		"[10:08:50.884] 6 9 1",
+		"[10:12:35.380] 10 9",
+	}
+	tests := []struct {
+		name     string
+		register bool
+		bibs     int
+		inline   int
+		orphans  int
+	}{
+		{name: "skip", register: false, bibs: 1, inline: 0, orphans: 3},
+		{name: "register", register: true, bibs: 2, inline: 1, orphans: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := newTestRace(t, lines...)
+			var out bytes.Buffer
+			p := newProcessor(r, nil, &out)
+			p.registerOrphans = test.register
+			require.NotPanics(t, func() { require.NoError(t, p.ProcessAll(r.events)) })
+			require.Len(t, p.Competitors(), test.bibs)
+			require.Len(t, p.quality.Orphans, test.orphans)
+			require.Equal(t, test.inline, strings.Count(out.String(), "unregistered_competitor"))
+
+			out.Reset()
+			printReport(&out, p, r, reportStyle{locale: locales["en"]})
+			if test.register {
+				require.Contains(t, out.String(), "- [NotStarted] Competitor 9: laps count 1")
+				require.NotContains(t, out.String(), "Data quality:")
+				return
+			}
+			require.Contains(t, out.String(), "\nData quality:\n3 events for unregistered competitors skipped:\n"+
+				"[10:08:49.289] Warning for competitor(9): unregistered_competitor: event 5 for a competitor who never registered\n")
+		})
+	}
+}
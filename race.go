@@ -0,0 +1,352 @@
+package biathlon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"BiathlonCompetitions/parser"
+)
+
+type Competitor struct {
+	ID            int
+	Suffix        string
+	Started       bool
+	LapsCompleted int
+	Hits          int
+	Bouts         []Bout
+	// RangeTimes are the times spent on the firing range, one per bout
+	// the competitor left.
+	RangeTimes []time.Duration
+	// Status is settled once all the events are applied.
+	Status       Status
+	lateStart    bool
+	retired      bool
+	disqualified bool
+	// pulled is the lap after which the competitor was pulled for a
+	// cutoff, 0 if they weren't.
+	pulled       int
+	StartTime    time.Time
+	ActualStart  time.Time
+	Compensation time.Duration
+	FinishTime   time.Time
+	// finishLine is the line of the events file FinishTime is from.
+	finishLine   int
+	StartPenalty time.Time
+	lapTimes     []time.Duration
+	PenaltyTimes []time.Duration
+	// Penalties are the visits to the penalty laps; End is zero while
+	// the competitor is still in them.
+	Penalties []PenaltyVisit
+	// Reason is why the competitor couldn't continue, if they sent a comment.
+	Reason *Reason
+	// Incidents are the marshals' reports about the competitor.
+	Incidents []Incident
+	// RuleHits are the custom rules triggered for the competitor, and
+	// RulePenalty the time their penalty actions add.
+	RuleHits    []RuleHit
+	RulePenalty time.Duration
+	// ExchangePenalty is the time the jury added for an early relay
+	// exchange.
+	ExchangePenalty time.Duration
+	// SyntheticLaps are the laps whose end was reconstructed.
+	SyntheticLaps []int
+	// outsideEntryRules marks a registration beyond the field cap or
+	// outside the bib range; it is kept out of the start grid validation.
+	outsideEntryRules bool
+	// LapEnds are the times the competitor crossed the lap line, and
+	// RoadPositions the order in which they physically crossed it on each
+	// lap, regardless of start offsets.
+	LapEnds       []time.Time
+	RoadPositions []int
+}
+
+// lapStart is when the competitor's current lap began: the last lap end, or
+// the scheduled start on the first lap so that the splits add up to the
+// total time.
+func (c *Competitor) lapStart() time.Time {
+	if n := len(c.LapEnds); n > 0 {
+		return c.LapEnds[n-1]
+	}
+	return c.StartTime
+}
+
+// totalTime is the time from the scheduled start to the finish, less any
+// start compensation granted by the jury, plus any penalty from the custom
+// rules or for an early exchange.
+func (c *Competitor) totalTime() time.Duration {
+	return c.FinishTime.Sub(c.StartTime) - c.Compensation + c.RulePenalty + c.ExchangePenalty
+}
+
+// crossing is the finish time with the same adjustments as the total time,
+// by which the disciplines ranked by crossing order the finishers.
+func (c *Competitor) crossing() time.Time {
+	return c.StartTime.Add(c.totalTime())
+}
+
+// formatDuration formats d the same way event times are formatted.
+func formatDuration(d time.Duration) string {
+	return time.Time{}.Add(d).Format(timeLayout)
+}
+
+func printResults(w io.Writer, competitors map[Bib]*Competitor, cfg Config, profile *CourseProfile, style reportStyle) {
+	fmt.Fprintln(w, "\nFinal results:")
+	_ = renderText(w, results(competitors, cfg, profile), style)
+}
+
+// race is everything loaded and validated before processing starts.
+type race struct {
+	cfg       Config
+	profile   *CourseProfile
+	baseStart time.Time
+	delta     time.Duration
+	// startLineTimeout is the parsed cfg.StartLineTimeout.
+	startLineTimeout time.Duration
+	// rules are the compiled cfg.Rules.
+	rules []Rule
+	// cutoffs are the parsed cfg.Cutoffs by lap.
+	cutoffs   map[int]time.Duration
+	events    []Event
+	decisions Decisions
+	// reconstructions are the results of the -reconstruct pass.
+	reconstructions []Reconstruction
+	// respaces are the start slot collisions looked at by -respace.
+	respaces []Respace
+	// resumeWindow is the parsed cfg.ResumeWindow, 0 without one.
+	resumeWindow time.Duration
+	// exchangeTolerance is the parsed cfg.ExchangeTolerance.
+	exchangeTolerance time.Duration
+	// heartbeatTimeout is the parsed cfg.HeartbeatTimeout, 0 without one.
+	heartbeatTimeout time.Duration
+	// skipped are the malformed event lines skipped in lenient mode.
+	skipped []parser.LineError
+}
+
+func loadEvents(path string, lenient bool) ([]Event, []parser.LineError, error) {
+	if lenient {
+		return parser.LoadEventsLenient(path)
+	}
+	events, err := parser.LoadEvents(path)
+	return events, nil, err
+}
+
+// skippedLine summarizes the event lines skipped in lenient mode.
+func skippedLine(skipped []parser.LineError) string {
+	numbers := make([]string, len(skipped))
+	for i, l := range skipped {
+		numbers[i] = strconv.Itoa(l.Line)
+	}
+	return fmt.Sprintf("%d lines skipped: %s", len(skipped), strings.Join(numbers, ", "))
+}
+
+// loadRace loads the config, in configFormat or detected from its
+// extension when empty, and the events of a race. With lenient the
+// malformed event lines are skipped into the race instead of failing.
+func loadRace(configPath, configFormat, eventsPath string, lenient bool) (race, error) {
+	cfg, err := readConfig(configPath, configFormat, false)
+	if err != nil {
+		return race{}, fmt.Errorf("config error: %w", err)
+	}
+	r, err := newRace(cfg)
+	if err != nil {
+		return race{}, err
+	}
+	if r.profile, err = loadProfile(cfg, configPath); err != nil {
+		return race{}, fmt.Errorf("course profile error: %w", err)
+	}
+	if r.events, r.skipped, err = loadEvents(eventsPath, lenient); err != nil {
+		return race{}, fmt.Errorf("events error: %w", err)
+	}
+	sortEvents(r.events)
+	numberEvents(r.events)
+	if err := r.alignStart(); err != nil {
+		return race{}, fmt.Errorf("events error: %w", err)
+	}
+	return r, nil
+}
+
+// ProcessRace runs a race from readers, for callers without files: the
+// JSON config from config and the event lines from events. It returns the
+// final results as the process command ranks them with no flags. The
+// config can't have a course profile, which is a file.
+func ProcessRace(config, events io.Reader) ([]Result, error) {
+	cfg, err := DecodeConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("config error: %w", err)
+	}
+	if cfg.Profile != "" {
+		return nil, errors.New("config error: a course profile needs a file")
+	}
+	r, err := newRace(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if r.events, err = parser.DecodeEvents(events); err != nil {
+		return nil, fmt.Errorf("events error: %w", err)
+	}
+	sortEvents(r.events)
+	numberEvents(r.events)
+	if err := r.alignStart(); err != nil {
+		return nil, fmt.Errorf("events error: %w", err)
+	}
+	p := newProcessor(r, nil, io.Discard)
+	if err := p.ProcessAll(r.events); err != nil {
+		return nil, err
+	}
+	return p.Results(), nil
+}
+
+// newRace resolves the times and rules of cfg into a race without events.
+func newRace(cfg Config) (race, error) {
+	baseStart, err := parser.ParseTime(cfg.Start)
+	if err != nil {
+		return race{}, fmt.Errorf("invalid start time in config: %w", err)
+	}
+	delta, err := parser.ParseDelta(cfg.StartDelta)
+	if err != nil {
+		return race{}, fmt.Errorf("invalid startDelta in config: %w", err)
+	}
+	startLineTimeout, err := parser.ParseDelta(cfg.StartLineTimeout)
+	if err != nil {
+		return race{}, fmt.Errorf("invalid startLineTimeout in config: %w", err)
+	}
+	rules, err := compileRules(cfg.Rules)
+	if err != nil {
+		return race{}, fmt.Errorf("invalid rules in config: %w", err)
+	}
+	cutoffs, err := parseCutoffs(cfg.Cutoffs, cfg.Laps)
+	if err != nil {
+		return race{}, fmt.Errorf("invalid cutoffs in config: %w", err)
+	}
+	var resumeWindow time.Duration
+	if cfg.ResumeWindow != "" {
+		if resumeWindow, err = parser.ParseDelta(cfg.ResumeWindow); err != nil {
+			return race{}, fmt.Errorf("invalid resumeWindow in config: %w", err)
+		}
+	}
+	var exchangeTolerance time.Duration
+	if cfg.ExchangeTolerance != "" {
+		if exchangeTolerance, err = parser.ParseDelta(cfg.ExchangeTolerance); err != nil {
+			return race{}, fmt.Errorf("invalid exchangeTolerance in config: %w", err)
+		}
+	}
+	var heartbeatTimeout time.Duration
+	if cfg.HeartbeatTimeout != "" {
+		if heartbeatTimeout, err = parser.ParseDelta(cfg.HeartbeatTimeout); err != nil {
+			return race{}, fmt.Errorf("invalid heartbeatTimeout in config: %w", err)
+		}
+	}
+	return race{cfg: cfg, baseStart: baseStart, delta: delta, startLineTimeout: startLineTimeout, rules: rules, cutoffs: cutoffs,
+		resumeWindow: resumeWindow, exchangeTolerance: exchangeTolerance, heartbeatTimeout: heartbeatTimeout}, nil
+}
+
+// alignStart puts the planned start on the day of the events: a start
+// without a date on the date of the first event, and on the next day when
+// it is past midnight from the first event, like the event times after it.
+// A dated start can't be aligned with undated events.
+func (r *race) alignStart() error {
+	if len(r.events) == 0 {
+		return nil
+	}
+	first := r.events[0].Time
+	switch {
+	case parser.Dated(r.baseStart) && !parser.Dated(first):
+		return fmt.Errorf("config start %s has a date, but the events don't", r.cfg.Start)
+	case parser.Dated(first) && !parser.Dated(r.baseStart):
+		r.baseStart = r.baseStart.AddDate(first.Year(), int(first.Month())-1, first.Day()-1)
+	}
+	if first.Sub(r.baseStart) > parser.MidnightWrap {
+		r.baseStart = r.baseStart.Add(24 * time.Hour)
+	}
+	return nil
+}
+
+// onRaceDay places the clock time t of an input without dates, such as a
+// jury decision, on the day of the start as alignStart places the start on
+// the day of the events: on the same date, or the next day when t is more
+// than MidnightWrap before the start.
+func (r race) onRaceDay(t time.Time) time.Time {
+	t = t.AddDate(r.baseStart.Year()-t.Year(), int(r.baseStart.Month()-t.Month()), r.baseStart.Day()-t.Day())
+	if r.baseStart.Sub(t) > parser.MidnightWrap {
+		t = t.Add(24 * time.Hour)
+	}
+	return t
+}
+
+// sortEvents sorts events by time. Events at the same time are ordered by
+// competitor, event id and extra params, so the order never depends on the
+// order of the lines in the file, e.g. after merging logs.
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		switch {
+		case !a.Time.Equal(b.Time):
+			return a.Time.Before(b.Time)
+		case a.Bib() != b.Bib():
+			return a.Bib().Less(b.Bib())
+		case a.EventID != b.EventID:
+			return a.EventID < b.EventID
+		}
+		return a.Extra < b.Extra
+	})
+}
+
+// numberEvents gives the sorted events their sequence numbers. Since the
+// sort doesn't depend on the order of the lines, neither do the numbers:
+// an event keeps its number however the merged logs were concatenated, and
+// through the later dropping of mirrored duplicates.
+func numberEvents(events []Event) {
+	for i := range events {
+		events[i].Seq = i + 1
+	}
+}
+
+// eventRef refers to the event numbered seq in a message, or is empty for
+// an unnumbered event, which only its time identifies.
+func eventRef(seq int) string {
+	if seq == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (event #%d)", seq)
+}
+
+// warningLine formats a warning about event e for the commentary.
+func warningLine(e Event, w Warning) string {
+	return fmt.Sprintf("[%s] %s", e.RawTime, warningMessage(e, w))
+}
+
+// warningMessage is the message of a warning about event e, without the
+// time.
+func warningMessage(e Event, w Warning) string {
+	return fmt.Sprintf("Warning for competitor(%s)%s: %s", e.Bib(), eventRef(e.Seq), w)
+}
+
+// printReport prints the final results followed by every report section
+// that has something to show.
+func printReport(w io.Writer, p *Processor, r race, style reportStyle) {
+	competitors := p.Competitors()
+	printResults(w, competitors, r.cfg, r.profile, style)
+	printPayouts(w, payouts(results(competitors, r.cfg, r.profile), r.cfg.Payouts))
+	printEntries(w, competitors, r.cfg)
+	printCompensations(w, competitors)
+	printExchangePenalties(w, competitors)
+	printRaceDevelopment(w, competitors)
+	printRhythm(w, competitors)
+	printBoutAccuracy(w, competitors, r.cfg.TargetsPerLine)
+	printReasons(w, competitors)
+	printRuleHits(w, competitors)
+	printReconstructions(w, r.reconstructions)
+	printPenaltyCredits(w, competitors)
+	audit := auditPenaltyLoops(competitors, r.cfg)
+	audit = append(audit, auditMissingPenaltyLaps(competitors, r.cfg)...)
+	audit = append(audit, auditUnservedPenalties(competitors, r.cfg)...)
+	audit = append(audit, auditUnobservedBouts(competitors)...)
+	audit = append(audit, auditRelayExchanges(competitors, r.cfg.Relays, r.cfg.Laps, r.exchangeTolerance)...)
+	printAudit(w, audit, competitors)
+	printTimeline(w, p.Timeline())
+	printDataQuality(w, p.quality)
+}
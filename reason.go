@@ -0,0 +1,67 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// reportedReasonKeys are the structured comment keys shown in the report,
+// in order. Other keys are kept on the Reason but not printed.
+var reportedReasonKeys = []string{"reason", "location"}
+
+// reasonSummary describes r for the report: the reported keys of a
+// structured comment, or its text.
+func reasonSummary(r Reason) string {
+	if r.Fields == nil {
+		return r.Text
+	}
+	var parts []string
+	for _, key := range reportedReasonKeys {
+		if v, ok := r.Fields[key]; ok {
+			parts = append(parts, key+" "+v)
+		}
+	}
+	if len(parts) == 0 {
+		return r.Text
+	}
+	return strings.Join(parts, ", ")
+}
+
+// reasonNote is the Reason of r followed by the keys of its comment the
+// summary leaves out, e.g. "reason fall, location downhill-2 (medic=yes)".
+// A summary that fell back to the text of the comment already holds them.
+func reasonNote(r Result) string {
+	if !slices.ContainsFunc(reportedReasonKeys, func(key string) bool { _, ok := r.ReasonFields[key]; return ok }) {
+		return r.Reason
+	}
+	var extras []string
+	for _, key := range slices.Sorted(maps.Keys(r.ReasonFields)) {
+		if !slices.Contains(reportedReasonKeys, key) {
+			extras = append(extras, key+"="+r.ReasonFields[key])
+		}
+	}
+	if len(extras) == 0 {
+		return r.Reason
+	}
+	return r.Reason + " (" + strings.Join(extras, ", ") + ")"
+}
+
+// printReasons lists why the competitors that couldn't continue stopped.
+// Nothing is printed when no competitor sent a comment.
+func printReasons(w io.Writer, competitors map[Bib]*Competitor) {
+	header := false
+	for _, bib := range sortedBibs(competitors) {
+		r := competitors[bib].Reason
+		if r == nil {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "\nCan`t continue:")
+			header = true
+		}
+		fmt.Fprintf(w, "Competitor %s: %s\n", bib, reasonSummary(*r))
+	}
+}
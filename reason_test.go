@@ -0,0 +1,34 @@
+package biathlon
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintReasons(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:31:50.285] 1 2",
+		"[09:31:51.285] 1 3",
+		"[10:30:00.000] 11 1 medic=yes location=downhill-2 reason=fall",
+		"[10:31:00.000] 11 2 Lost in the forest",
+		"[10:32:00.000] 11 3 medic=yes",
+	)
+	p := newProcessor(r, nil, io.Discard)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Equal(t, "yes", p.Competitors()[Bib{Number: 1}].Reason.Fields["medic"])
+
+	var out bytes.Buffer
+	printReasons(&out, p.Competitors())
+	require.Equal(t, "\nCan`t continue:\n"+
+		"Competitor 1: reason fall, location downhill-2\n"+
+		"Competitor 2: Lost in the forest\n"+
+		"Competitor 3: medic=yes\n", out.String())
+
+	out.Reset()
+	printReasons(&out, map[Bib]*Competitor{Bib{Number: 1}: {ID: 1}})
+	require.Empty(t, out.String())
+}
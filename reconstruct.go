@@ -0,0 +1,128 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Reconstruction describes a lap end synthesized from the surrounding
+// checkpoints, or, with Lap 0, a competitor whose missing lap ends couldn't
+// be reconstructed.
+type Reconstruction struct {
+	Bib  Bib    `json:"bib"`
+	Lap  int    `json:"lap,omitempty"`
+	Time string `json:"time,omitempty"`
+	Note string `json:"note"`
+}
+
+func (r Reconstruction) String() string {
+	if r.Lap == 0 {
+		return fmt.Sprintf("competitor(%s): %s", r.Bib, r.Note)
+	}
+	return fmt.Sprintf("competitor(%s): lap %d end synthesized at %s, %s", r.Bib, r.Lap, r.Time, r.Note)
+}
+
+// checkpoints are the times a competitor crossed the checkpoints relevant
+// to lap reconstruction.
+type checkpoints struct {
+	arrivals []time.Time // onTheFiringRange
+	exits    []time.Time // leftTheFiringRange and leftThePenaltyLaps
+	lapEnds  []time.Time
+	retired  bool
+}
+
+// reconstructLaps looks for competitors who shot every bout (one per lap)
+// and crossed the finish but miss one lap end: the lap mat didn't register
+// them. The missing lap end is synthesized halfway between the last exit
+// from the range or penalty laps and the arrival at the next range, and
+// the events are returned in time order. Competitors missing more than one
+// lap end are left alone with a note.
+func reconstructLaps(events []Event, cfg Config) ([]Event, []Reconstruction) {
+	byBib := map[Bib]*checkpoints{}
+	var bibs []Bib
+	for _, e := range events {
+		c, ok := byBib[e.Bib()]
+		if !ok {
+			c = &checkpoints{}
+			byBib[e.Bib()] = c
+			bibs = append(bibs, e.Bib())
+		}
+		switch e.EventID {
+		case onTheFiringRange:
+			c.arrivals = append(c.arrivals, e.Time)
+		case leftTheFiringRange, leftThePenaltyLaps:
+			c.exits = append(c.exits, e.Time)
+		case endedTheMainLap:
+			c.lapEnds = append(c.lapEnds, e.Time)
+		case comment:
+			c.retired = true
+		}
+	}
+	sort.Slice(bibs, func(i, j int) bool { return bibs[i].Less(bibs[j]) })
+
+	var reconstructions []Reconstruction
+	for _, bib := range bibs {
+		c := byBib[bib]
+		if c.retired || len(c.lapEnds) == 0 || len(c.lapEnds) >= cfg.Laps || len(c.arrivals) != cfg.Laps {
+			continue
+		}
+		last := c.arrivals[len(c.arrivals)-1]
+		if !c.lapEnds[len(c.lapEnds)-1].After(last) {
+			continue
+		}
+		// Lap k ends between the k-th and the (k+1)-th range arrival.
+		var missing []int
+		for k := 1; k < cfg.Laps; k++ {
+			if countBetween(c.lapEnds, c.arrivals[k-1], c.arrivals[k]) == 0 {
+				missing = append(missing, k)
+			}
+		}
+		if len(missing) != 1 {
+			reconstructions = append(reconstructions, Reconstruction{Bib: bib, Note: fmt.Sprintf("%d lap ends missing, not reconstructed", cfg.Laps-len(c.lapEnds))})
+			continue
+		}
+		k := missing[0]
+		from := c.arrivals[k-1]
+		for _, t := range c.exits {
+			if t.After(from) && t.Before(c.arrivals[k]) {
+				from = t
+			}
+		}
+		at := from.Add(c.arrivals[k].Sub(from) / 2).Truncate(time.Millisecond)
+		events = append(events, Event{
+			Time:         at,
+			RawTime:      at.Format(timeLayout),
+			EventID:      endedTheMainLap,
+			CompetitorID: bib.Number,
+			Suffix:       bib.Suffix,
+			Synthetic:    true,
+		})
+		reconstructions = append(reconstructions, Reconstruction{Bib: bib, Lap: k, Time: at.Format(timeLayout),
+			Note: fmt.Sprintf("between %s and %s", from.Format(timeLayout), c.arrivals[k].Format(timeLayout))})
+	}
+	sortEvents(events)
+	return events, reconstructions
+}
+
+func countBetween(times []time.Time, from, to time.Time) int {
+	n := 0
+	for _, t := range times {
+		if t.After(from) && t.Before(to) {
+			n++
+		}
+	}
+	return n
+}
+
+// printReconstructions lists the synthetic lap ends and the gaps left alone.
+func printReconstructions(w io.Writer, reconstructions []Reconstruction) {
+	if len(reconstructions) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nReconstructed laps:")
+	for _, r := range reconstructions {
+		fmt.Fprintln(w, r)
+	}
+}
@@ -0,0 +1,74 @@
+package biathlon
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconstructMissingMiddleLap(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[10:00:01.000] 4 1",
+		"[10:08:00.000] 5 1 1",
+		"[10:08:30.000] 7 1",
+		"[10:08:40.000] 8 1",
+		"[10:09:40.000] 9 1",
+		// The lap 1 end at about 10:13 was not registered.
+		"[10:20:00.000] 5 1 2",
+		"[10:20:30.000] 7 1",
+		"[10:25:00.000] 10 1",
+	)
+	events, reconstructions := reconstructLaps(r.events, r.cfg)
+	require.Equal(t, []Reconstruction{{Bib: Bib{Number: 1}, Lap: 1, Time: "10:14:50.000", Note: "between 10:09:40.000 and 10:20:00.000"}}, reconstructions)
+	require.Len(t, events, len(r.events)+1)
+	require.True(t, events[7].Synthetic)
+	require.Equal(t, endedTheMainLap, events[7].EventID)
+
+	r.events, r.reconstructions = events, reconstructions
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Contains(t, out.String(), "[10:14:50.000] The competitor(1) ended the main lap (synthetic)\n")
+	comp := p.Competitors()[Bib{Number: 1}]
+	require.True(t, comp.finished(r.cfg))
+	require.Equal(t, []int{1}, comp.SyntheticLaps)
+
+	out.Reset()
+	printReconstructions(&out, r.reconstructions)
+	require.Equal(t, "\nReconstructed laps:\ncompetitor(1): lap 1 end synthesized at 10:14:50.000, between 10:09:40.000 and 10:20:00.000\n", out.String())
+}
+
+func TestReconstructLeavesTwoMissingLaps(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[10:00:01.000] 4 1",
+		"[10:08:00.000] 5 1 1",
+		"[10:08:30.000] 7 1",
+		"[10:20:00.000] 5 1 2",
+		"[10:20:30.000] 7 1",
+		"[10:32:00.000] 5 1 3",
+		"[10:32:30.000] 7 1",
+		"[10:37:00.000] 10 1",
+	)
+	r.cfg.Laps = 3
+	events, reconstructions := reconstructLaps(r.events, r.cfg)
+	require.Equal(t, r.events, events)
+	require.Equal(t, []Reconstruction{{Bib: Bib{Number: 1}, Note: "2 lap ends missing, not reconstructed"}}, reconstructions)
+
+	p := newProcessor(r, nil, io.Discard)
+	require.NoError(t, p.ProcessAll(events))
+	require.False(t, p.Competitors()[Bib{Number: 1}].finished(r.cfg))
+}
+
+func TestReconstructIgnoresCompleteRace(t *testing.T) {
+	r, err := loadRace("config/config.json", "", "events", false)
+	require.NoError(t, err)
+	events, reconstructions := reconstructLaps(r.events, r.cfg)
+	require.Equal(t, r.events, events)
+	require.Empty(t, reconstructions)
+}
@@ -0,0 +1,139 @@
+package biathlon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"BiathlonCompetitions/parser"
+)
+
+const (
+	WarnEarlyExchange      WarningCode = "early_exchange"
+	WarnIncompleteExchange WarningCode = "incomplete_exchange"
+)
+
+// RelayTeam is a relay team and the bibs of its legs in running order.
+// Every leg but the first takes over from the one before it in the
+// exchange zone.
+type RelayTeam struct {
+	Team string   `json:"team"`
+	Legs []string `json:"legs"`
+}
+
+// parseRelays checks that every team is named once and has at least two
+// legs with bibs no other leg uses.
+func parseRelays(relays []RelayTeam) error {
+	teams := make(map[string]bool, len(relays))
+	legs := make(map[Bib]string)
+	for _, r := range relays {
+		if r.Team == "" {
+			return errors.New("relay team without a name")
+		}
+		if teams[r.Team] {
+			return fmt.Errorf("relay team %s is listed twice", r.Team)
+		}
+		teams[r.Team] = true
+		if len(r.Legs) < 2 {
+			return fmt.Errorf("relay team %s must have at least two legs, got %d", r.Team, len(r.Legs))
+		}
+		for _, leg := range r.Legs {
+			bib, err := parser.ParseBib(leg)
+			if err != nil {
+				return fmt.Errorf("relay team %s: %w", r.Team, err)
+			}
+			if team, dup := legs[bib]; dup {
+				return fmt.Errorf("relay team %s: competitor(%s) already runs for %s", r.Team, bib, team)
+			}
+			legs[bib] = r.Team
+		}
+	}
+	return nil
+}
+
+// ExchangePenalty is a jury decision to add Penalty to the total time of
+// the outgoing leg of an early exchange.
+type ExchangePenalty struct {
+	Competitor Bib
+	Penalty    time.Duration
+}
+
+func (p *ExchangePenalty) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Competitor string `json:"competitor"`
+		Penalty    string `json:"penalty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	bib, err := parser.ParseBib(raw.Competitor)
+	if err != nil {
+		return fmt.Errorf("exchange penalty competitor: %w", err)
+	}
+	penalty, err := parser.ParseDelta(raw.Penalty)
+	if err != nil {
+		return fmt.Errorf("exchange penalty for competitor(%s): %w", bib, err)
+	}
+	*p = ExchangePenalty{Competitor: bib, Penalty: penalty}
+	return nil
+}
+
+// applyExchangePenalties adds the exchange penalties of the jury to the
+// competitors' total times.
+func applyExchangePenalties(competitors map[Bib]*Competitor, d Decisions) {
+	for _, p := range d.ExchangePenalties {
+		if c, ok := competitors[p.Competitor]; ok {
+			c.ExchangePenalty += p.Penalty
+		}
+	}
+}
+
+// auditRelayExchanges flags the exchanges where the outgoing leg started
+// more than tolerance before the incoming leg ended their last of laps, and
+// those where the incoming leg never ended it. An exchange is only checked
+// once the outgoing leg has started.
+func auditRelayExchanges(competitors map[Bib]*Competitor, relays []RelayTeam, laps int, tolerance time.Duration) []Warning {
+	var warnings []Warning
+	for _, r := range relays {
+		for i := 1; i < len(r.Legs); i++ {
+			// The legs were validated with the config.
+			inBib, _ := parser.ParseBib(r.Legs[i-1])
+			outBib, _ := parser.ParseBib(r.Legs[i])
+			in, out := competitors[inBib], competitors[outBib]
+			if in == nil || out == nil || out.ActualStart.IsZero() {
+				continue
+			}
+			if len(in.LapEnds) < laps {
+				warnings = append(warnings, Warning{Code: WarnIncompleteExchange, Message: fmt.Sprintf(
+					"team %s: competitor(%s) took over at %s with competitor(%s) on %d of %d laps",
+					r.Team, outBib, out.ActualStart.Format(timeLayout), inBib, len(in.LapEnds), laps)})
+				continue
+			}
+			finish := in.LapEnds[laps-1]
+			if early := finish.Sub(out.ActualStart); early > tolerance {
+				warnings = append(warnings, Warning{Code: WarnEarlyExchange, Message: fmt.Sprintf(
+					"team %s: competitor(%s) took over %s before competitor(%s) ended the main lap at %s, beyond the %s tolerance",
+					r.Team, outBib, formatDuration(early), inBib, finish.Format(timeLayout), formatDuration(tolerance))})
+			}
+		}
+	}
+	return warnings
+}
+
+// printExchangePenalties lists the competitors the jury penalized for an
+// early exchange.
+func printExchangePenalties(w io.Writer, competitors map[Bib]*Competitor) {
+	header := false
+	for _, bib := range sortedBibs(competitors) {
+		if competitors[bib].ExchangePenalty == 0 {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "\nExchange penalties:")
+			header = true
+		}
+		fmt.Fprintf(w, "Competitor %s: %s\n", bib, formatDuration(competitors[bib].ExchangePenalty))
+	}
+}
@@ -0,0 +1,105 @@
+package biathlon
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRelayExchanges(t *testing.T) {
+	t.Parallel()
+	finish, _ := time.Parse(timeLayout, "10:24:00.000")
+	relays := []RelayTeam{{Team: "NOR", Legs: []string{"1a", "1b", "1c"}}}
+	tests := []struct {
+		name     string
+		takeover time.Duration
+		laps     int
+		expected []Warning
+	}{
+		{name: "test_legal_exchange", takeover: 500 * time.Millisecond, laps: 2},
+		{name: "test_takeover_within_tolerance", takeover: -time.Second, laps: 2},
+		{
+			name:     "test_early_takeover",
+			takeover: -3 * time.Second,
+			laps:     2,
+			expected: []Warning{{Code: WarnEarlyExchange, Message: "team NOR: competitor(1b) took over 00:00:03.000 before competitor(1a) " +
+				"ended the main lap at 10:24:00.000, beyond the 00:00:01.000 tolerance"}},
+		},
+		{
+			name:     "test_takeover_before_the_last_lap",
+			takeover: -12 * time.Minute,
+			laps:     3,
+			expected: []Warning{{Code: WarnIncompleteExchange, Message: "team NOR: competitor(1b) took over at 10:12:00.000 with competitor(1a) on 2 of 3 laps"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			competitors := map[Bib]*Competitor{
+				{Number: 1, Suffix: "a"}: {ID: 1, Suffix: "a", LapEnds: []time.Time{finish.Add(-12 * time.Minute), finish}},
+				{Number: 1, Suffix: "b"}: {ID: 1, Suffix: "b", ActualStart: finish.Add(test.takeover)},
+				// The third leg hasn't taken over yet.
+				{Number: 1, Suffix: "c"}: {ID: 1, Suffix: "c"},
+			}
+			require.Equal(t, test.expected, auditRelayExchanges(competitors, relays, test.laps, time.Second))
+		})
+	}
+}
+
+func TestExchangePenaltyDecision(t *testing.T) {
+	r := newTestRace(t,
+		"[09:30:00.000] 1 1a",
+		"[09:30:01.000] 1 1b",
+		"[09:45:00.000] 2 1a 10:00:00.000",
+		"[09:45:01.000] 2 1b 10:24:00.000",
+		"[10:00:00.500] 4 1a",
+		"[10:12:00.000] 10 1a",
+		"[10:23:57.000] 4 1b",
+		"[10:24:00.000] 10 1a",
+	)
+	r.cfg.Relays = []RelayTeam{{Team: "NOR", Legs: []string{"1a", "1b"}}}
+	path := filepath.Join(t.TempDir(), "decisions.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"exchangePenalties": [{"competitor": "1B", "penalty": "00:01:00"}]}`), 0o644))
+	var err error
+	r.decisions, err = loadDecisions(path)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Equal(t, time.Minute, p.Competitors()[Bib{Number: 1, Suffix: "b"}].ExchangePenalty)
+
+	out.Reset()
+	printReport(&out, p, r, reportStyle{locale: locales["en"]})
+	require.Contains(t, out.String(), "\nExchange penalties:\nCompetitor 1b: 00:01:00.000\n")
+	require.Contains(t, out.String(), "early_exchange: team NOR: competitor(1b) took over 00:00:03.000 before competitor(1a) ended the main lap")
+}
+
+func TestLoadConfigRelays(t *testing.T) {
+	t.Parallel()
+	cfg, err := DecodeConfig(strings.NewReader("{" + baseConfigFields + `, "relays": [{"team": "NOR", "legs": ["1a", "1b"]}], "exchangeTolerance": "00:00:01"}`))
+	require.NoError(t, err)
+	r, err := newRace(cfg)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, r.exchangeTolerance)
+
+	tests := []struct {
+		relays string
+		err    string
+	}{
+		{relays: `[{"legs": ["1a", "1b"]}]`, err: "relay team without a name"},
+		{relays: `[{"team": "NOR", "legs": ["1a"]}]`, err: "relay team NOR must have at least two legs, got 1"},
+		{relays: `[{"team": "NOR", "legs": ["1a", "x"]}]`, err: `relay team NOR: invalid competitor "x"`},
+		{relays: `[{"team": "NOR", "legs": ["1a", "1b"]}, {"team": "NOR", "legs": ["2a", "2b"]}]`, err: "relay team NOR is listed twice"},
+		{relays: `[{"team": "NOR", "legs": ["1a", "1b"]}, {"team": "SWE", "legs": ["1b", "2b"]}]`, err: "relay team SWE: competitor(1b) already runs for NOR"},
+	}
+	for _, test := range tests {
+		_, err := DecodeConfig(strings.NewReader("{" + baseConfigFields + `, "relays": ` + test.relays + "}"))
+		require.ErrorContains(t, err, test.err)
+	}
+}
@@ -0,0 +1,260 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Result is the final result of one competitor, as rendered by every output
+// format.
+type Result struct {
+	// Place is the 1-based place of a finisher, 0 for the others.
+	Place  int
+	Bib    Bib
+	Status Status
+	// Total is set when Status is StatusFinished.
+	Total         time.Duration
+	LapsCompleted int
+	Laps          []LapResult
+	Penalties     []PenaltyLapResult
+	Hits          int
+	Shots         int
+	// Misses are the targets missed in the observed bouts, one penalty
+	// loop each.
+	Misses int
+	// RangeTimes are the times spent on the firing range per bout and
+	// RangeTime their sum. CourseTime is Total less RangeTime, set along
+	// with Total.
+	RangeTimes []time.Duration
+	RangeTime  time.Duration
+	CourseTime time.Duration
+	// PhotoFinish marks a place decided by the order of the finish events
+	// sharing a time, pending confirmation by the photo.
+	PhotoFinish bool
+	// Reason is why a competitor couldn't continue, summarized from their
+	// comment; empty without one.
+	Reason string
+	// ReasonFields are the key=value pairs of a structured comment, nil for
+	// free text. The keys Reason leaves out follow it in the report.
+	ReasonFields map[string]string
+}
+
+// LapResult is the time and average speed, in m/s, over a main lap.
+type LapResult struct {
+	Time  time.Duration
+	Speed float64
+	// ClimbSpeed is the climb adjusted speed over a main lap, 0 without a
+	// course profile.
+	ClimbSpeed float64
+}
+
+// PenaltyLapResult is the time and average speed, in m/s, over the penalty
+// laps of one visit.
+type PenaltyLapResult struct {
+	Time  time.Duration
+	Speed float64
+}
+
+// crossingDisciplines are the disciplines where the finishers rank in the
+// order they crossed the line, by their normalized names.
+var crossingDisciplines = map[string]bool{"massstart": true, "pursuit": true}
+
+// ranksByCrossing reports whether the discipline ranks the finishers in the
+// order they crossed the line.
+func (cfg Config) ranksByCrossing() bool {
+	return crossingDisciplines[normalizeColumn(cfg.Discipline)]
+}
+
+// results builds the final result of every competitor in ranking order:
+// the finishers by total time, sharing the place on the same millisecond,
+// then the others by status and bib. In the disciplines ranked by crossing
+// the finishers go by their crossing instead, and a tie on the millisecond
+// is a photo finish: the file order of the finish events decides the
+// places until the photo confirms them.
+func results(competitors map[Bib]*Competitor, cfg Config, profile *CourseProfile) []Result {
+	var all []Result
+	for _, bib := range sortedBibs(competitors) {
+		comp := competitors[bib]
+		r := Result{Bib: bib, Status: comp.Status, LapsCompleted: comp.LapsCompleted, Hits: comp.Hits, Shots: cfg.Laps * cfg.TargetsPerLine, Misses: comp.misses(cfg)}
+		if r.Status == "" {
+			// A bulletin ranks the field before the statuses settle.
+			r.Status = comp.status(cfg)
+		}
+		r.RangeTimes = comp.RangeTimes
+		if comp.Reason != nil {
+			r.Reason = reasonSummary(*comp.Reason)
+			r.ReasonFields = comp.Reason.Fields
+		}
+		for _, t := range comp.RangeTimes {
+			r.RangeTime += t
+		}
+		if r.Status == StatusFinished {
+			r.Total = comp.totalTime()
+			r.CourseTime = r.Total - r.RangeTime
+		}
+		for i, lap := range comp.lapTimes {
+			l := LapResult{Time: lap, Speed: float64(cfg.LapLen) / lap.Seconds()}
+			if profile != nil && i < len(profile.Laps) {
+				l.ClimbSpeed = climbAdjustedSpeed(float64(cfg.LapLen), profile.Laps[i], lap)
+			}
+			r.Laps = append(r.Laps, l)
+		}
+		for _, lap := range comp.PenaltyTimes {
+			r.Penalties = append(r.Penalties, PenaltyLapResult{Time: lap, Speed: float64(cfg.PenaltyLen) / lap.Seconds()})
+		}
+		all = append(all, r)
+	}
+	crossing := cfg.ranksByCrossing()
+	tied := func(a, b Result) bool { return a.Total.Milliseconds() == b.Total.Milliseconds() }
+	if crossing {
+		tied = func(a, b Result) bool {
+			return competitors[a.Bib].crossing().Equal(competitors[b.Bib].crossing())
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].Status != all[j].Status {
+			return statusOrder[all[i].Status] < statusOrder[all[j].Status]
+		}
+		switch {
+		case all[i].Status != StatusFinished:
+			return false
+		case !tied(all[i], all[j]) && crossing:
+			return competitors[all[i].Bib].crossing().Before(competitors[all[j].Bib].crossing())
+		case !tied(all[i], all[j]):
+			return all[i].Total < all[j].Total
+		case crossing:
+			return competitors[all[i].Bib].finishLine < competitors[all[j].Bib].finishLine
+		}
+		return false
+	})
+	for i := range all {
+		switch {
+		case all[i].Status != StatusFinished:
+		case i > 0 && all[i-1].Status == StatusFinished && tied(all[i-1], all[i]) && crossing:
+			all[i].Place = i + 1
+			all[i-1].PhotoFinish, all[i].PhotoFinish = true, true
+		case i > 0 && all[i-1].Status == StatusFinished && tied(all[i-1], all[i]):
+			all[i].Place = all[i-1].Place
+		default:
+			all[i].Place = i + 1
+		}
+	}
+	return all
+}
+
+// renderer is an output format of the final results. fields declares the
+// Result fields the format represents, as dotted paths into nested structs;
+// the renderer conformance test holds every format to covering all of them.
+type renderer struct {
+	render func(w io.Writer, results []Result, style reportStyle) error
+	fields []string
+}
+
+// renderers are the registered output formats by name.
+var renderers = map[string]renderer{
+	"text": {
+		render: renderText,
+		fields: []string{"Place", "Bib", "Status", "Total", "LapsCompleted", "Laps.Time", "Laps.Speed", "Laps.ClimbSpeed",
+			"Penalties.Time", "Penalties.Speed", "Hits", "Shots", "Misses", "RangeTimes", "RangeTime", "CourseTime", "PhotoFinish", "Reason", "ReasonFields"},
+	},
+	"accessible": {
+		render: renderAccessible,
+		fields: []string{"Place", "Bib", "Status", "Total", "LapsCompleted", "Laps.Time", "Laps.Speed", "Laps.ClimbSpeed",
+			"Penalties.Time", "Penalties.Speed", "Hits", "Shots", "Misses", "RangeTimes", "RangeTime", "CourseTime", "PhotoFinish", "Reason", "ReasonFields"},
+	},
+	"markdown": {
+		render: renderMarkdown,
+		fields: []string{"Place", "Bib", "Status", "Total", "LapsCompleted", "Laps.Time", "Laps.Speed", "Laps.ClimbSpeed",
+			"Penalties.Time", "Penalties.Speed", "Hits", "Shots", "Misses", "RangeTimes", "RangeTime", "CourseTime", "PhotoFinish", "Reason", "ReasonFields"},
+	},
+	"json": {
+		render: renderJSON,
+		fields: []string{"Place", "Bib", "Status", "Total", "LapsCompleted", "Laps.Time", "Laps.Speed", "Laps.ClimbSpeed",
+			"Penalties.Time", "Penalties.Speed", "Hits", "Shots", "Misses", "RangeTimes", "RangeTime", "CourseTime", "PhotoFinish", "Reason", "ReasonFields"},
+	},
+	"html": {
+		render: renderHTML,
+		fields: []string{"Place", "Bib", "Status", "Total", "LapsCompleted", "Laps.Time", "Laps.Speed", "Laps.ClimbSpeed",
+			"Penalties.Time", "Penalties.Speed", "Hits", "Shots", "Misses", "RangeTimes", "RangeTime", "CourseTime", "PhotoFinish", "Reason", "ReasonFields"},
+	},
+}
+
+// lookupRenderer returns the output format called name.
+func lookupRenderer(name string) (renderer, error) {
+	r, ok := renderers[name]
+	if !ok {
+		names := make([]string, 0, len(renderers))
+		for n := range renderers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return renderer{}, fmt.Errorf("unknown format %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return r, nil
+}
+
+// Render writes the results in the output format called format, such as
+// text, json, markdown or html, with the en locale and no sparklines.
+func Render(w io.Writer, results []Result, format string) error {
+	r, err := lookupRenderer(format)
+	if err != nil {
+		return err
+	}
+	return r.render(w, results, reportStyle{locale: locales["en"]})
+}
+
+// renderText writes the results as the text report lines, each headed by
+// the place or "-" without one.
+func renderText(w io.Writer, results []Result, style reportStyle) error {
+	lines := sparklines(results, style)
+	for _, r := range results {
+		place := "-"
+		if r.Place > 0 {
+			place = fmt.Sprintf("%d.", r.Place)
+		}
+		status := "[" + string(r.Status) + "]"
+		if r.Status == StatusFinished {
+			status = style.total(r.Total)
+		}
+		laps := make([]string, len(r.Laps))
+		for i, lap := range r.Laps {
+			if lap.ClimbSpeed != 0 {
+				laps[i] = fmt.Sprintf("{%s, %s, %s}", style.duration(lap.Time), style.speed(lap.Speed), style.speed(lap.ClimbSpeed))
+			} else {
+				laps[i] = fmt.Sprintf("{%s, %s}", style.duration(lap.Time), style.speed(lap.Speed))
+			}
+		}
+		penalties := make([]string, len(r.Penalties))
+		for i, lap := range r.Penalties {
+			penalties[i] = fmt.Sprintf("{%s, %s}", style.duration(lap.Time), style.speed(lap.Speed))
+		}
+		spark := ""
+		if line, ok := lines[r.Bib]; ok {
+			spark = " " + line
+		}
+		rangeTimes := make([]string, len(r.RangeTimes))
+		for i, t := range r.RangeTimes {
+			rangeTimes[i] = style.duration(t)
+		}
+		course := ""
+		if r.Status == StatusFinished {
+			course = ", Course " + style.duration(r.CourseTime)
+		}
+		notes := ""
+		if r.PhotoFinish {
+			notes += ", Photo finish pending confirmation"
+		}
+		if r.Reason != "" {
+			notes += ", Reason " + reasonNote(r)
+		}
+		if _, err := fmt.Fprintf(w, "%s %s Competitor %s: laps count %d, laps [%s]%s, Penalty [%s], Hits %d/%d, Misses %d, Range %s [%s]%s%s\n",
+			place, status, r.Bib, r.LapsCompleted, strings.Join(laps, ", "), spark, strings.Join(penalties, ", "), r.Hits, r.Shots, r.Misses,
+			style.duration(r.RangeTime), strings.Join(rangeTimes, ", "), course, notes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
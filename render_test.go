@@ -0,0 +1,242 @@
+package biathlon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// resultFixture populates every Result field with a distinct value.
+var resultFixture = Result{
+	Place:         1,
+	Bib:           Bib{Number: 7, Suffix: "b"},
+	Status:        StatusFinished,
+	Total:         25*time.Minute + 26*time.Second + 47*time.Millisecond,
+	LapsCompleted: 2,
+	Laps: []LapResult{
+		{Time: 12*time.Minute + 1*time.Second, Speed: 4.85, ClimbSpeed: 5.12},
+		{Time: 11*time.Minute + 59*time.Second, Speed: 4.87, ClimbSpeed: 5.01},
+	},
+	Penalties:    []PenaltyLapResult{{Time: 29 * time.Second, Speed: 5.17}},
+	Hits:         8,
+	Shots:        10,
+	Misses:       2,
+	RangeTimes:   []time.Duration{31*time.Second + 200*time.Millisecond, 28*time.Second + 400*time.Millisecond},
+	RangeTime:    59*time.Second + 600*time.Millisecond,
+	CourseTime:   24*time.Minute + 26*time.Second + 447*time.Millisecond,
+	PhotoFinish:  true,
+	Reason:       "Lost a ski",
+	ReasonFields: map[string]string{"reason": "Lost a ski", "medic": "yes"},
+}
+
+// resultFields returns the dotted paths of the leaf fields of t, descending
+// into structs and slices of structs other than Bib and time.Duration.
+func resultFields(t reflect.Type, prefix string) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ft := f.Type
+		if ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(Bib{}) {
+			fields = append(fields, resultFields(ft, prefix+f.Name+".")...)
+			continue
+		}
+		fields = append(fields, prefix+f.Name)
+	}
+	return fields
+}
+
+// zeroField returns a copy of r with the field at path zeroed, in every
+// element of a slice on the way.
+func zeroField(r Result, path string) Result {
+	r.Laps = append([]LapResult(nil), r.Laps...)
+	r.Penalties = append([]PenaltyLapResult(nil), r.Penalties...)
+	var zero func(v reflect.Value, names []string)
+	zero = func(v reflect.Value, names []string) {
+		f := v.FieldByName(names[0])
+		switch {
+		case len(names) == 1:
+			f.Set(reflect.Zero(f.Type()))
+		case f.Kind() == reflect.Slice:
+			for i := 0; i < f.Len(); i++ {
+				zero(f.Index(i), names[1:])
+			}
+		default:
+			zero(f, names[1:])
+		}
+	}
+	zero(reflect.ValueOf(&r).Elem(), strings.Split(path, "."))
+	return r
+}
+
+// TestRendererConformance holds every registered format to representing
+// every Result field: each format declares all of them, and zeroing any one
+// field of the fixture changes the rendered output.
+func TestRendererConformance(t *testing.T) {
+	t.Parallel()
+	all := resultFields(reflect.TypeOf(Result{}), "")
+	for name, r := range renderers {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			declared := append([]string(nil), r.fields...)
+			sort.Strings(declared)
+			expected := append([]string(nil), all...)
+			sort.Strings(expected)
+			require.Equal(t, expected, declared, "the format must declare every Result field")
+
+			var full bytes.Buffer
+			require.NoError(t, r.render(&full, []Result{resultFixture}, reportStyle{locale: locales["en"]}))
+			for _, field := range r.fields {
+				var out bytes.Buffer
+				require.NoError(t, r.render(&out, []Result{zeroField(resultFixture, field)}, reportStyle{locale: locales["en"]}))
+				require.NotEqual(t, full.String(), out.String(), "%s is not represented", field)
+			}
+		})
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	require.NoError(t, renderText(&out, []Result{resultFixture, {Bib: Bib{Number: 3}, Status: StatusNotStarted, Shots: 10}}, reportStyle{locale: locales["en"]}))
+	require.Equal(t, "1. 25:26.047 Competitor 7b: laps count 2, laps [{00:12:01.000, 4.850, 5.120}, {00:11:59.000, 4.870, 5.010}], "+
+		"Penalty [{00:00:29.000, 5.170}], Hits 8/10, Misses 2, Range 00:00:59.600 [00:00:31.200, 00:00:28.400], Course 00:24:26.447, Photo finish pending confirmation, Reason Lost a ski (medic=yes)\n"+
+		"- [NotStarted] Competitor 3: laps count 0, laps [], Penalty [], Hits 0/10, Misses 0, Range 00:00:00.000 []\n", out.String())
+}
+
+func TestResultsRanking(t *testing.T) {
+	t.Parallel()
+	start, _ := time.Parse(timeLayout, "10:00:00.000")
+	finisher := func(id int, total time.Duration) *Competitor {
+		return &Competitor{ID: id, Started: true, LapsCompleted: 1, StartTime: start, FinishTime: start.Add(total)}
+	}
+	penalized := finisher(5, 20*time.Minute)
+	penalized.RulePenalty = 2 * time.Minute
+	competitors := map[Bib]*Competitor{
+		{Number: 1}: finisher(1, 21*time.Minute),
+		{Number: 2}: finisher(2, 20*time.Minute+500*time.Microsecond),
+		{Number: 3}: finisher(3, 20*time.Minute),
+		{Number: 4}: {ID: 4, Started: true, LapsCompleted: 1, lateStart: true, StartTime: start, FinishTime: start.Add(time.Minute)},
+		{Number: 5}: penalized,
+		{Number: 6}: {ID: 6, Started: true},
+		{Number: 7}: {ID: 7, Started: true, LapsCompleted: 1, retired: true, StartTime: start, FinishTime: start.Add(time.Minute)},
+		{Number: 8}: {ID: 8},
+	}
+
+	var got []string
+	for _, r := range results(competitors, Config{Laps: 1}, nil) {
+		got = append(got, fmt.Sprintf("%d %s %s", r.Place, r.Bib, r.Status))
+	}
+	require.Equal(t, []string{
+		"1 2 Finished",
+		"1 3 Finished",
+		"3 1 Finished",
+		"4 5 Finished",
+		"0 6 NotFinished",
+		"0 7 NotFinished",
+		"0 4 Disqualified",
+		"0 8 NotStarted",
+	}, got)
+}
+
+// TestResultsPhotoFinish ranks two finishes on the same millisecond, the
+// later finish event first in the file, and a third competitor who started
+// two minutes later but finished one minute after them.
+func TestResultsPhotoFinish(t *testing.T) {
+	t.Parallel()
+	start, _ := time.Parse(timeLayout, "10:00:00.000")
+	competitors := map[Bib]*Competitor{
+		{Number: 1}: {ID: 1, Started: true, LapsCompleted: 1, StartTime: start, FinishTime: start.Add(20 * time.Minute), finishLine: 9},
+		{Number: 2}: {ID: 2, Started: true, LapsCompleted: 1, StartTime: start, FinishTime: start.Add(20 * time.Minute), finishLine: 7},
+		{Number: 3}: {ID: 3, Started: true, LapsCompleted: 1, StartTime: start.Add(2 * time.Minute), FinishTime: start.Add(21 * time.Minute), finishLine: 3},
+	}
+	byTime := []string{"1 3", "2 1", "2 2"}
+	byCrossing := []string{"1 2 photo finish", "2 1 photo finish", "3 3"}
+	tests := []struct {
+		discipline string
+		expected   []string
+	}{
+		{discipline: "", expected: byTime},
+		{discipline: "sprint", expected: byTime},
+		{discipline: "massstart", expected: byCrossing},
+		{discipline: "Mass Start", expected: byCrossing},
+		{discipline: "pursuit", expected: byCrossing},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, r := range results(competitors, Config{Laps: 1, Discipline: test.discipline}, nil) {
+			line := fmt.Sprintf("%d %s", r.Place, r.Bib)
+			if r.PhotoFinish {
+				line += " photo finish"
+			}
+			got = append(got, line)
+		}
+		require.Equal(t, test.expected, got, test.discipline)
+	}
+}
+
+// TestPhotoFinishFileOrder runs a mass start whose two finishes share a
+// millisecond and requires the finish logged first in the file to rank
+// first, although the sort orders the events by competitor.
+func TestPhotoFinishFileOrder(t *testing.T) {
+	t.Parallel()
+	config := `{"laps": 1, "lapLen": 3500, "penaltyLen": 150, "firingLines": 1, "start": "10:00:00.000", "startDelta": "00:00:00", "discipline": "massstart"}`
+	events := strings.Join([]string{
+		"[09:30:00.000] 1 1", "[09:30:01.000] 1 2", "[09:45:00.000] 2 1 10:00:00.000", "[09:45:01.000] 2 2 10:00:00.000",
+		"[10:00:00.000] 4 1", "[10:00:00.000] 4 2", "[10:20:00.000] 10 2", "[10:20:00.000] 10 1",
+	}, "\n")
+	results, err := ProcessRace(strings.NewReader(config), strings.NewReader(events))
+	require.NoError(t, err)
+	var got []string
+	for _, r := range results {
+		got = append(got, fmt.Sprintf("%d %s %t", r.Place, r.Bib, r.PhotoFinish))
+	}
+	require.Equal(t, []string{"1 2 true", "2 1 true"}, got)
+}
+
+// TestRenderMarkdownGolden pins the Markdown table of a small race with a
+// finisher, a competitor who didn't finish and one who didn't start.
+func TestRenderMarkdownGolden(t *testing.T) {
+	r, err := loadRace("config/config.json", "", "testdata/markdown.events", false)
+	require.NoError(t, err)
+	p := newProcessor(r, nil, io.Discard)
+	require.NoError(t, p.ProcessAll(r.events))
+	var out bytes.Buffer
+	require.NoError(t, Render(&out, p.Results(), "markdown"))
+	got := out.String()
+
+	golden := "testdata/markdown.golden"
+	if *update {
+		require.NoError(t, os.WriteFile(golden, []byte(got), 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	require.Equal(t, string(want), got)
+}
+
+func TestLocaleClock(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{d: 0, expected: "00:00"},
+		{d: 7*time.Minute + 5*time.Second, expected: "07:05"},
+		{d: 31*time.Second + 200*time.Millisecond, expected: "00:31.2"},
+		{d: time.Hour + 2*time.Minute + 31*time.Second + 47*time.Millisecond, expected: "1:02:31.047"},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, locales["en"].clock(test.d))
+	}
+	require.Equal(t, "00:31,2", locales["ru"].clock(31*time.Second+200*time.Millisecond))
+}
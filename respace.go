@@ -0,0 +1,124 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Respace is a start slot collision of the draw and how -respace resolved
+// it. ToSlot is 0 when no free slot was left and the collision stays
+// flagged.
+type Respace struct {
+	Bib      Bib    `json:"bib"`
+	FromSlot int    `json:"fromSlot"`
+	From     string `json:"from"`
+	ToSlot   int    `json:"toSlot,omitempty"`
+	To       string `json:"to,omitempty"`
+	// With is the competitor the slot was also drawn for.
+	With Bib `json:"with"`
+}
+
+func (r Respace) String() string {
+	if r.ToSlot == 0 {
+		return fmt.Sprintf("competitor(%s) shares slot #%d (%s) with competitor(%s): no free slot, left flagged", r.Bib, r.FromSlot, r.From, r.With)
+	}
+	return fmt.Sprintf("competitor(%s) moved from slot #%d (%s) to slot #%d (%s), it collided with competitor(%s)", r.Bib, r.FromSlot, r.From, r.ToSlot, r.To, r.With)
+}
+
+// respaceDraws resolves the start slot collisions of the draw: of the two
+// competitors drawn for the same slot, the one registered later is moved to
+// the next free slot on the grid. A competitor only ever moves to a later
+// slot, by whole slots, and no further than margin slots past the last
+// drawn slot; a collision with no such slot is left for the grid
+// validation to flag. The draw events are rewritten in place. A mass
+// start, with a delta of 0, has no slots to respace.
+func respaceDraws(events []Event, baseStart time.Time, delta time.Duration, margin int) []Respace {
+	if delta == 0 {
+		return nil
+	}
+	registered := map[Bib]int{}
+	for i, e := range events {
+		if _, ok := registered[e.Bib()]; e.EventID == register && !ok {
+			registered[e.Bib()] = i
+		}
+	}
+	order := func(bib Bib) int {
+		if i, ok := registered[bib]; ok {
+			return i
+		}
+		return math.MaxInt
+	}
+
+	type collision struct {
+		slot   int
+		holder Bib
+		event  int
+	}
+	holders := map[int]Bib{}
+	drawEvents := map[int]int{}
+	var collisions []collision
+	last := 0
+	for i, e := range events {
+		draw, ok := e.Payload.(DrawTime)
+		if e.EventID != startTime || !ok || draw.Time.Before(baseStart) {
+			continue
+		}
+		slot, onGrid := startSlot(draw.Time, baseStart, delta)
+		if !onGrid {
+			continue
+		}
+		last = max(last, slot)
+		holder, taken := holders[slot]
+		switch {
+		case !taken:
+			holders[slot] = e.Bib()
+			drawEvents[slot] = i
+		case holder != e.Bib():
+			collisions = append(collisions, collision{slot: slot, holder: holder, event: i})
+		}
+	}
+
+	var respaces []Respace
+	for _, c := range collisions {
+		mover, event, with := events[c.event].Bib(), c.event, c.holder
+		if order(c.holder) > order(mover) {
+			mover, event, with = c.holder, drawEvents[c.slot], mover
+		}
+		r := Respace{Bib: mover, FromSlot: c.slot, From: slotTime(baseStart, delta, c.slot).Format(timeLayout), With: with}
+		free := c.slot + 1
+		for ; free <= last+margin; free++ {
+			if _, taken := holders[free]; !taken {
+				break
+			}
+		}
+		if free > last+margin {
+			respaces = append(respaces, r)
+			continue
+		}
+		to := slotTime(baseStart, delta, free)
+		r.ToSlot, r.To = free, to.Format(timeLayout)
+		if event != c.event {
+			holders[c.slot], drawEvents[c.slot] = with, c.event
+		}
+		holders[free], drawEvents[free] = mover, event
+		events[event].Payload = DrawTime{Time: to}
+		events[event].Extra = r.To
+		respaces = append(respaces, r)
+	}
+	return respaces
+}
+
+// slotTime is the start time of the 1-based slot on the grid.
+func slotTime(baseStart time.Time, delta time.Duration, slot int) time.Time {
+	return baseStart.Add(time.Duration(slot-1) * delta)
+}
+
+// printRespaces prints every collision -respace looked at before the
+// commentary, so that the moved competitors stand out.
+func printRespaces(w io.Writer, respaces []Respace) {
+	for _, r := range respaces {
+		fmt.Fprintln(w, "RESPACED:", r)
+	}
+}
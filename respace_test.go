@@ -0,0 +1,65 @@
+package biathlon
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRespaceDraws(t *testing.T) {
+	r := newTestRace(t,
+		"[09:30:00.000] 1 1",
+		"[09:30:01.000] 1 2",
+		"[09:30:02.000] 1 3",
+		"[09:30:03.000] 1 4",
+		"[09:50:00.000] 2 1 10:00:00.000",
+		"[09:50:01.000] 2 2 10:01:30.000",
+		"[09:50:02.000] 2 3 10:01:30.000",
+		"[09:50:03.000] 2 4 10:06:00.000",
+	)
+	respaces := respaceDraws(r.events, r.baseStart, r.delta, 0)
+	require.Equal(t, []Respace{{Bib: Bib{Number: 3}, FromSlot: 2, From: "10:01:30.000", ToSlot: 3, To: "10:03:00.000", With: Bib{Number: 2}}}, respaces)
+	require.Equal(t, "competitor(3) moved from slot #2 (10:01:30.000) to slot #3 (10:03:00.000), it collided with competitor(2)", respaces[0].String())
+
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.NotContains(t, out.String(), "slot_collision")
+	require.Equal(t, "10:03:00.000", p.Competitors()[Bib{Number: 3}].StartTime.Format(timeLayout))
+	require.Contains(t, out.String(), "[09:50:02.000] The start time for the competitor(3) was set by a draw to 10:03:00.000 (slot #3)\n")
+}
+
+func TestRespaceMovesTheLaterRegistered(t *testing.T) {
+	r := newTestRace(t,
+		"[09:30:00.000] 1 5",
+		"[09:30:01.000] 1 6",
+		"[09:50:00.000] 2 6 10:00:00.000",
+		"[09:50:01.000] 2 5 10:00:00.000",
+	)
+	respaces := respaceDraws(r.events, r.baseStart, r.delta, 1)
+	require.Equal(t, []Respace{{Bib: Bib{Number: 6}, FromSlot: 1, From: "10:00:00.000", ToSlot: 2, To: "10:01:30.000", With: Bib{Number: 5}}}, respaces)
+
+	p := newProcessor(r, nil, &bytes.Buffer{})
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Equal(t, "10:00:00.000", p.Competitors()[Bib{Number: 5}].StartTime.Format(timeLayout))
+	require.Equal(t, "10:01:30.000", p.Competitors()[Bib{Number: 6}].StartTime.Format(timeLayout))
+}
+
+func TestRespaceWithoutFreeSlot(t *testing.T) {
+	r := newTestRace(t,
+		"[09:30:00.000] 1 1",
+		"[09:30:01.000] 1 2",
+		"[09:50:00.000] 2 1 10:00:00.000",
+		"[09:50:01.000] 2 2 10:00:00.000",
+	)
+	respaces := respaceDraws(r.events, r.baseStart, r.delta, 0)
+	require.Equal(t, []Respace{{Bib: Bib{Number: 2}, FromSlot: 1, From: "10:00:00.000", With: Bib{Number: 1}}}, respaces)
+	require.Equal(t, "competitor(2) shares slot #1 (10:00:00.000) with competitor(1): no free slot, left flagged", respaces[0].String())
+
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Contains(t, out.String(), "slot_collision: slot #1 is already assigned to competitor(1)")
+	require.Equal(t, "10:00:00.000", p.Competitors()[Bib{Number: 2}].StartTime.Format(timeLayout))
+}
@@ -0,0 +1,367 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"BiathlonCompetitions/parser"
+)
+
+// RuleConfig is a custom rule as written in the config: when the condition
+// When holds for a competitor's computed results, Action is taken.
+type RuleConfig struct {
+	Name   string `json:"name"`
+	When   string `json:"when"`
+	Action string `json:"action"`
+	// Penalty is the time added by the "penalty" action, in the startDelta
+	// format.
+	Penalty string `json:"penalty,omitempty"`
+}
+
+// Rule actions.
+const (
+	ActionFlag    = "flag"
+	ActionDSQ     = "dsq"
+	ActionPenalty = "penalty"
+)
+
+// ruleFields are the values a rule condition can refer to. Times are in
+// seconds: lapTime is the slowest main lap, penaltyTime the time spent in
+// the penalty laps, totalTime the total time of a finisher (0 otherwise)
+// and medianLapTime the median main lap of the field.
+var ruleFields = []string{"misses", "hits", "lapTime", "penaltyTime", "totalTime", "medianLapTime"}
+
+// Rule is a compiled RuleConfig.
+type Rule struct {
+	Name    string
+	Action  string
+	Penalty time.Duration
+	when    condition
+}
+
+// RuleHit is a rule triggered for a competitor.
+type RuleHit struct {
+	Rule   string
+	Action string
+	// Penalty is the time added by a penalty action.
+	Penalty time.Duration
+}
+
+// condition evaluates a parsed rule condition against the rule fields.
+type condition func(values map[string]float64) bool
+
+// compileRules compiles the rules of the config.
+func compileRules(configs []RuleConfig) ([]Rule, error) {
+	var rules []Rule
+	for i, rc := range configs {
+		r, err := compileRule(rc)
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func compileRule(rc RuleConfig) (Rule, error) {
+	r := Rule{Name: rc.Name, Action: rc.Action}
+	if r.Name == "" {
+		r.Name = rc.When
+	}
+	switch rc.Action {
+	case ActionFlag, ActionDSQ:
+	case ActionPenalty:
+		d, err := parser.ParseDelta(rc.Penalty)
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w: %q: penalty: %w", ErrInvalidRule, r.Name, err)
+		}
+		r.Penalty = d
+	default:
+		return Rule{}, fmt.Errorf("%w: %q: unknown action %q, want flag, dsq or penalty", ErrInvalidRule, r.Name, rc.Action)
+	}
+	when, err := parseCondition(rc.When)
+	if err != nil {
+		return Rule{}, fmt.Errorf("%w: %q: %w", ErrInvalidRule, r.Name, err)
+	}
+	r.when = when
+	return r, nil
+}
+
+// parseCondition parses a rule condition:
+//
+//	or      = and { "or" and }
+//	and     = compare { "and" compare }
+//	compare = product ( ">" | ">=" | "<" | "<=" | "==" | "!=" ) product
+//	product = operand [ "*" operand ]
+//	operand = number | HH:MM:SS[.sss] | field
+func parseCondition(s string) (condition, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &conditionParser{tokens: tokens}
+	c, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return c, nil
+}
+
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		r := rune(s[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '.':
+			j := i
+			for j < len(s) && (unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j])) || s[j] == '.' || s[j] == ':') {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		case strings.ContainsRune("<>=!", r):
+			j := i + 1
+			if j < len(s) && s[j] == '=' {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		case r == '*':
+			tokens = append(tokens, "*")
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+type conditionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *conditionParser) next() string {
+	if p.pos == len(p.tokens) {
+		return ""
+	}
+	p.pos++
+	return p.tokens[p.pos-1]
+}
+
+func (p *conditionParser) accept(token string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos] == token {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) or() (condition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(v map[string]float64) bool { return l(v) || right(v) }
+	}
+	return left, nil
+}
+
+func (p *conditionParser) and() (condition, error) {
+	left, err := p.compare()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.compare()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(v map[string]float64) bool { return l(v) && right(v) }
+	}
+	return left, nil
+}
+
+var comparisons = map[string]func(a, b float64) bool{
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+	"==": func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+}
+
+func (p *conditionParser) compare() (condition, error) {
+	left, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	cmp, ok := comparisons[op]
+	if !ok {
+		return nil, fmt.Errorf("expected a comparison, got %q", op)
+	}
+	right, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	return func(v map[string]float64) bool { return cmp(left(v), right(v)) }, nil
+}
+
+func (p *conditionParser) product() (func(map[string]float64) float64, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("*") {
+		return left, nil
+	}
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return func(v map[string]float64) float64 { return left(v) * right(v) }, nil
+}
+
+func (p *conditionParser) operand() (func(map[string]float64) float64, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of condition")
+	case strings.Contains(token, ":"):
+		d, err := parser.ParseDelta(token)
+		if err != nil {
+			return nil, err
+		}
+		return func(map[string]float64) float64 { return d.Seconds() }, nil
+	case !unicode.IsLetter(rune(token[0])) && !unicode.IsDigit(rune(token[0])) && token[0] != '.':
+		return nil, fmt.Errorf("expected a number, a time or a field, got %q", token)
+	case unicode.IsDigit(rune(token[0])) || token[0] == '.':
+		f, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", token)
+		}
+		return func(map[string]float64) float64 { return f }, nil
+	default:
+		for _, name := range ruleFields {
+			if name == token {
+				return func(v map[string]float64) float64 { return v[name] }, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown field %q, want one of %s", token, strings.Join(ruleFields, ", "))
+	}
+}
+
+// ruleValues computes the rule fields of c. medianLap is the median main
+// lap of the field.
+func ruleValues(c *Competitor, cfg Config, medianLap time.Duration) map[string]float64 {
+	var slowest, penalty time.Duration
+	for _, lap := range c.lapTimes {
+		slowest = max(slowest, lap)
+	}
+	for _, lap := range c.PenaltyTimes {
+		penalty += lap
+	}
+	var total time.Duration
+	if c.finished(cfg) {
+		total = c.totalTime()
+	}
+	return map[string]float64{
+		"misses":        float64(c.misses(cfg)),
+		"hits":          float64(c.Hits),
+		"lapTime":       slowest.Seconds(),
+		"penaltyTime":   penalty.Seconds(),
+		"totalTime":     total.Seconds(),
+		"medianLapTime": medianLap.Seconds(),
+	}
+}
+
+// fieldMedianLap is the median of the main laps of every competitor.
+func fieldMedianLap(competitors map[Bib]*Competitor) time.Duration {
+	var laps []time.Duration
+	for _, c := range competitors {
+		laps = append(laps, c.lapTimes...)
+	}
+	if len(laps) == 0 {
+		return 0
+	}
+	sort.Slice(laps, func(i, j int) bool { return laps[i] < laps[j] })
+	if len(laps)%2 == 1 {
+		return laps[len(laps)/2]
+	}
+	return (laps[len(laps)/2-1] + laps[len(laps)/2]) / 2
+}
+
+// applyRules evaluates the rules for every competitor that started, in the
+// config order, and takes the actions of the triggered ones: a DSQ
+// disqualifies the competitor and a penalty adds to their total time. The
+// median lap is computed before any action is taken.
+func applyRules(competitors map[Bib]*Competitor, rules []Rule, cfg Config) {
+	if len(rules) == 0 {
+		return
+	}
+	medianLap := fieldMedianLap(competitors)
+	values := make(map[Bib]map[string]float64)
+	for bib, c := range competitors {
+		if c.Started {
+			values[bib] = ruleValues(c, cfg, medianLap)
+		}
+	}
+	for bib, v := range values {
+		c := competitors[bib]
+		for _, r := range rules {
+			if !r.when(v) {
+				continue
+			}
+			c.RuleHits = append(c.RuleHits, RuleHit{Rule: r.Name, Action: r.Action, Penalty: r.Penalty})
+			switch r.Action {
+			case ActionDSQ:
+				c.disqualified = true
+			case ActionPenalty:
+				c.RulePenalty += r.Penalty
+			}
+		}
+	}
+}
+
+// printRuleHits lists the custom rules triggered for every competitor.
+// Nothing is printed when no rule was triggered.
+func printRuleHits(w io.Writer, competitors map[Bib]*Competitor) {
+	header := false
+	for _, bib := range sortedBibs(competitors) {
+		c := competitors[bib]
+		if len(c.RuleHits) == 0 {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "\nRules triggered:")
+			header = true
+		}
+		hits := make([]string, len(c.RuleHits))
+		for i, h := range c.RuleHits {
+			action := h.Action
+			if h.Action == ActionPenalty {
+				action = "+" + formatDuration(h.Penalty)
+			}
+			hits[i] = fmt.Sprintf("%s (%s)", h.Rule, action)
+		}
+		fmt.Fprintf(w, "Competitor %s: %s\n", bib, strings.Join(hits, ", "))
+	}
+}
@@ -0,0 +1,100 @@
+package biathlon
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRules(t *testing.T) {
+	t.Parallel()
+	rules, err := compileRules([]RuleConfig{
+		{Name: "too many misses", When: "misses > 6", Action: ActionDSQ},
+		{Name: "slow lap", When: "lapTime > 2 * medianLapTime", Action: ActionFlag},
+		{When: "penaltyTime >= 00:05:00 and hits < 3", Action: ActionPenalty, Penalty: "00:01:00"},
+	})
+	require.NoError(t, err)
+	cfg := Config{Laps: 2, TargetsPerLine: 5}
+	competitors := map[Bib]*Competitor{
+		{Number: 1}: {ID: 1, Started: true, Bouts: make([]Bout, 2), Hits: 3, lapTimes: []time.Duration{10 * time.Minute, 10 * time.Minute}},
+		{Number: 2}: {ID: 2, Started: true, Bouts: make([]Bout, 2), Hits: 9, lapTimes: []time.Duration{10 * time.Minute, 25 * time.Minute}},
+		{Number: 3}: {ID: 3, Started: true, Bouts: make([]Bout, 2), Hits: 10, lapTimes: []time.Duration{10 * time.Minute, 11 * time.Minute}},
+	}
+
+	applyRules(competitors, rules, cfg)
+	require.Equal(t, []RuleHit{{Rule: "too many misses", Action: ActionDSQ}}, competitors[Bib{Number: 1}].RuleHits)
+	require.True(t, competitors[Bib{Number: 1}].disqualified)
+	require.Equal(t, []RuleHit{{Rule: "slow lap", Action: ActionFlag}}, competitors[Bib{Number: 2}].RuleHits)
+	require.False(t, competitors[Bib{Number: 2}].disqualified)
+	require.Empty(t, competitors[Bib{Number: 3}].RuleHits)
+
+	var out bytes.Buffer
+	printRuleHits(&out, competitors)
+	require.Equal(t, "\nRules triggered:\n"+
+		"Competitor 1: too many misses (dsq)\n"+
+		"Competitor 2: slow lap (flag)\n", out.String())
+}
+
+func TestRulePenalty(t *testing.T) {
+	t.Parallel()
+	rules, err := compileRules([]RuleConfig{{Name: "long penalty", When: "penaltyTime >= 00:01:30", Action: ActionPenalty, Penalty: "00:00:30"}})
+	require.NoError(t, err)
+	start, _ := time.Parse(timeLayout, "10:00:00.000")
+	c := &Competitor{ID: 1, Started: true, LapsCompleted: 1, StartTime: start, FinishTime: start.Add(20 * time.Minute),
+		PenaltyTimes: []time.Duration{time.Minute, 30 * time.Second}}
+	applyRules(map[Bib]*Competitor{{Number: 1}: c}, rules, Config{Laps: 1, TargetsPerLine: 5})
+	require.Equal(t, 20*time.Minute+30*time.Second, c.totalTime())
+
+	var out bytes.Buffer
+	printRuleHits(&out, map[Bib]*Competitor{{Number: 1}: c})
+	require.Contains(t, out.String(), "Competitor 1: long penalty (+00:00:30.000)\n")
+}
+
+func TestCompileRuleInvalid(t *testing.T) {
+	t.Parallel()
+	for _, rc := range []RuleConfig{
+		{When: "misses > 6", Action: "ban"},
+		{When: "misses > 6", Action: ActionPenalty, Penalty: "1m"},
+		{When: "mises > 6", Action: ActionFlag},
+		{When: "misses 6", Action: ActionFlag},
+		{When: "misses > 6 and", Action: ActionFlag},
+		{When: "misses > 6 hits", Action: ActionFlag},
+		{When: "misses > 6 % 2", Action: ActionFlag},
+	} {
+		_, err := compileRule(rc)
+		require.True(t, errors.Is(err, ErrInvalidRule), rc.When)
+	}
+}
+
+func TestConfigRules(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"laps": 2, "lapLen": 3500, "penaltyLen": 150, "start": "10:00:00.000",
+		"startDelta": "00:01:30", "rules": [{"name": "too many misses", "when": "misses >> 6", "action": "dsq"}]}`), 0o644))
+	_, err := LoadConfig(path)
+	require.ErrorContains(t, err, `rules[0]: invalid rule: "too many misses": expected a number, a time or a field, got ">"`)
+}
+
+func TestParseCondition(t *testing.T) {
+	t.Parallel()
+	values := map[string]float64{"misses": 4, "hits": 6, "lapTime": 700}
+	tests := map[string]bool{
+		"misses > 3":                   true,
+		"misses >= 4 and hits == 6":    true,
+		"misses != 4 or lapTime > 600": true,
+		"misses < 2 or hits <= 5":      false,
+		"lapTime > 00:11:40":           false,
+		"lapTime > 00:11:39.5":         true,
+		"lapTime <= 1.5 * 467":         true,
+	}
+	for when, expected := range tests {
+		c, err := parseCondition(when)
+		require.NoError(t, err, when)
+		require.Equal(t, expected, c(values), when)
+	}
+}
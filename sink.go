@@ -0,0 +1,131 @@
+package biathlon
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// sinkOptions configure where and how the report is written.
+type sinkOptions struct {
+	contentType string
+	// authEnv names the environment variable holding the Authorization
+	// header sent with HTTP uploads.
+	authEnv string
+	retries int
+	backoff time.Duration
+	// timeout bounds every upload attempt, 0 for none.
+	timeout time.Duration
+	client  *http.Client
+}
+
+// openSink opens the report destination dest: "-" is stdout, http(s) URLs
+// receive the report with a PUT when the sink is closed, anything else is a
+// file path, created or truncated. The report is only complete once Close
+// succeeds.
+func openSink(dest string, stdout io.Writer, o sinkOptions) (io.WriteCloser, error) {
+	switch {
+	case dest == "-":
+		return nopWriteCloser{stdout}, nil
+	case strings.HasPrefix(dest, "http://"), strings.HasPrefix(dest, "https://"):
+		s := &httpSink{url: dest, opts: o}
+		if o.authEnv != "" {
+			s.auth = os.Getenv(o.authEnv)
+			if s.auth == "" {
+				return nil, fmt.Errorf("environment variable %s with the authorization header is not set", o.authEnv)
+			}
+		}
+		return s, nil
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return nil, err
+	}
+	return fileSink{Writer: bufio.NewWriter(f), f: f}, nil
+}
+
+// fileSink buffers the report written to a file. A failed write, such as
+// on a full disk, fails every later one and Close.
+type fileSink struct {
+	*bufio.Writer
+	f *os.File
+}
+
+func (s fileSink) Close() error {
+	err := s.Flush()
+	if cerr := s.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// httpSink buffers the report and uploads it with an HTTP PUT on Close.
+type httpSink struct {
+	url  string
+	auth string
+	opts sinkOptions
+	buf  bytes.Buffer
+}
+
+func (s *httpSink) Write(p []byte) (int, error) {
+	return s.buf.Write(p)
+}
+
+// Close uploads the report, retrying failed attempts, including those over
+// the timeout, with a doubling backoff. Client errors (4xx) are not retried.
+func (s *httpSink) Close() error {
+	client := s.opts.client
+	if client == nil {
+		client = &http.Client{Timeout: s.opts.timeout}
+	}
+	backoff := s.opts.backoff
+	attempts := 0
+	for {
+		attempts++
+		retry, err := s.put(client)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempts > s.opts.retries {
+			return fmt.Errorf("PUT %s failed after %d attempts: %w", s.url, attempts, err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+var errUploadStatus = errors.New("unexpected status")
+
+// put makes one upload attempt and tells whether a failure is worth retrying.
+func (s *httpSink) put(client *http.Client) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPut, s.url, bytes.NewReader(s.buf.Bytes()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", s.opts.contentType)
+	if s.auth != "" {
+		req.Header.Set("Authorization", s.auth)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	return resp.StatusCode >= 500, fmt.Errorf("%w %s", errUploadStatus, resp.Status)
+}
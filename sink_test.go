@@ -0,0 +1,168 @@
+package biathlon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSinkStdoutAndFile(t *testing.T) {
+	var stdout bytes.Buffer
+	s, err := openSink("-", &stdout, sinkOptions{})
+	require.NoError(t, err)
+	fmt.Fprint(s, "report")
+	require.NoError(t, s.Close())
+	require.Equal(t, "report", stdout.String())
+
+	path := filepath.Join(t.TempDir(), "report.txt")
+	s, err = openSink(path, &stdout, sinkOptions{})
+	require.NoError(t, err)
+	fmt.Fprint(s, "file report")
+	require.NoError(t, s.Close())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "file report", string(data))
+}
+
+func TestHTTPSinkPut(t *testing.T) {
+	t.Setenv("BIATHLON_TEST_AUTH", "Bearer secret")
+	var method, contentType, auth, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, contentType, auth = r.Method, r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer srv.Close()
+
+	s, err := openSink(srv.URL+"/results.txt", io.Discard, sinkOptions{contentType: "text/plain", authEnv: "BIATHLON_TEST_AUTH"})
+	require.NoError(t, err)
+	fmt.Fprint(s, "Final results:")
+	require.NoError(t, s.Close())
+	require.Equal(t, http.MethodPut, method)
+	require.Equal(t, "text/plain", contentType)
+	require.Equal(t, "Bearer secret", auth)
+	require.Equal(t, "Final results:", body)
+}
+
+func TestHTTPSinkMissingAuth(t *testing.T) {
+	t.Setenv("BIATHLON_TEST_AUTH", "")
+	_, err := openSink("https://results.example/r.txt", io.Discard, sinkOptions{authEnv: "BIATHLON_TEST_AUTH"})
+	require.ErrorContains(t, err, "BIATHLON_TEST_AUTH")
+}
+
+func TestHTTPSinkRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		attempts int32
+		err      string
+	}{
+		{name: "retry then succeed", statuses: []int{500, 503, 200}, attempts: 3},
+		{name: "retry then fail", statuses: []int{500, 500, 500}, attempts: 3, err: "failed after 3 attempts: unexpected status 500 Internal Server Error"},
+		{name: "client error is not retried", statuses: []int{403}, attempts: 1, err: "failed after 1 attempts: unexpected status 403 Forbidden"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.statuses[calls.Add(1)-1])
+			}))
+			defer srv.Close()
+
+			s, err := openSink(srv.URL, io.Discard, sinkOptions{retries: 2, backoff: time.Millisecond})
+			require.NoError(t, err)
+			err = s.Close()
+			require.Equal(t, test.attempts, calls.Load())
+			if test.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestHTTPSinkTimeout(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-release
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	s, err := openSink(srv.URL, io.Discard, sinkOptions{retries: 1, backoff: time.Millisecond, timeout: 50 * time.Millisecond})
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+	require.Equal(t, int32(2), calls.Load(), "the attempt over the timeout is retried")
+
+	s, err = openSink(srv.URL, io.Discard, sinkOptions{timeout: 50 * time.Millisecond})
+	require.NoError(t, err)
+	calls.Store(0)
+	require.ErrorContains(t, s.Close(), "failed after 1 attempts")
+}
+
+func TestRunUploadFailureExitCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	var stdout bytes.Buffer
+	code := Run([]string{"-out", srv.URL, "-out-retries", "1", "-out-backoff", "1ms"}, &stdout, io.Discard)
+	require.Equal(t, 1, code)
+	require.Contains(t, stdout.String(), "Output error: PUT "+srv.URL+" failed after 2 attempts")
+	require.NotContains(t, stdout.String(), "Final results:")
+}
+
+// TestRunReportToFile requires -o to take the report alone, matching the
+// golden file, while the commentary stays on stdout.
+func TestRunReportToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	require.NoError(t, os.WriteFile(path, []byte("a longer report of an earlier run to truncate\n"), 0o644))
+	var stdout bytes.Buffer
+	require.Equal(t, 0, Run([]string{"-o", path}, &stdout, io.Discard))
+	require.Contains(t, stdout.String(), "The competitor(1) registered")
+	require.NotContains(t, stdout.String(), "Final results:")
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	golden := "testdata/report.golden"
+	if *update {
+		require.NoError(t, os.WriteFile(golden, got, 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got))
+}
+
+func TestRunReportFileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "missing directory", path: filepath.Join(t.TempDir(), "missing", "report.txt")},
+		{name: "full disk", path: "/dev/full"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := os.Stat(test.path); test.path == "/dev/full" && err != nil {
+				t.Skip("no /dev/full")
+			}
+			var stdout bytes.Buffer
+			require.Equal(t, 1, Run([]string{"-o", test.path}, &stdout, io.Discard))
+			require.Contains(t, stdout.String(), "Output error:")
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package biathlon
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	WarnOffGridStart    WarningCode = "off_grid_start"
+	WarnSlotCollision   WarningCode = "slot_collision"
+	WarnDrawBeforeStart WarningCode = "draw_before_start"
+	WarnRetroactiveDraw WarningCode = "retroactive_draw"
+)
+
+// startSlot returns the 1-based start slot a drawn start time falls into,
+// counting startDelta intervals from the base start. onGrid reports whether
+// the drawn time lies exactly on a slot boundary. drawn must not be before
+// baseStart, and delta must be positive.
+func startSlot(drawn, baseStart time.Time, delta time.Duration) (slot int, onGrid bool) {
+	offset := drawn.Sub(baseStart)
+	return int(offset/delta) + 1, offset%delta == 0
+}
+
+// slotMap keeps track of which competitor each start slot was drawn for.
+type slotMap map[int]Bib
+
+// assign records the drawn start time of competitor bib and returns its slot
+// together with warnings for off-grid times and slots already taken. A time
+// drawn before the base start is an invalid draw: it gets slot 0 and is kept
+// out of the grid. A mass start, with a delta of 0, has no grid: every draw
+// gets slot 0.
+func (m slotMap) assign(bib Bib, drawn, baseStart time.Time, delta time.Duration) (int, []Warning) {
+	if drawn.Before(baseStart) {
+		return 0, []Warning{{Code: WarnDrawBeforeStart, Message: fmt.Sprintf("start time %s is before the race start %s", drawn.Format(timeLayout), baseStart.Format(timeLayout))}}
+	}
+	if delta == 0 {
+		return 0, nil
+	}
+	slot, onGrid := startSlot(drawn, baseStart, delta)
+	var warnings []Warning
+	if !onGrid {
+		warnings = append(warnings, Warning{Code: WarnOffGridStart, Message: fmt.Sprintf("start time %s is off the startDelta grid", drawn.Format(timeLayout))})
+	}
+	if other, ok := m[slot]; ok && other != bib {
+		warnings = append(warnings, Warning{Code: WarnSlotCollision, Message: fmt.Sprintf("slot #%d is already assigned to competitor(%s)", slot, other)})
+		return slot, warnings
+	}
+	m[slot] = bib
+	return slot, warnings
+}
@@ -0,0 +1,194 @@
+package biathlon
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"BiathlonCompetitions/parser"
+)
+
+func TestStartSlot(t *testing.T) {
+	t.Parallel()
+	baseStart, _ := time.Parse(timeLayout, "10:00:00.000")
+	tests := []struct {
+		name           string
+		drawn          string
+		expectedSlot   int
+		expectedOnGrid bool
+	}{
+		{
+			name:           "test_first_slot",
+			drawn:          "10:00:00.000",
+			expectedSlot:   1,
+			expectedOnGrid: true,
+		},
+		{
+			name:           "test_seventh_slot",
+			drawn:          "10:09:00.000",
+			expectedSlot:   7,
+			expectedOnGrid: true,
+		},
+		{
+			name:           "test_off_grid",
+			drawn:          "10:02:00.000",
+			expectedSlot:   2,
+			expectedOnGrid: false,
+		},
+		{
+			name:           "test_off_grid_by_millisecond",
+			drawn:          "10:01:30.001",
+			expectedSlot:   2,
+			expectedOnGrid: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			drawn, _ := time.Parse(timeLayout, test.drawn)
+			slot, onGrid := startSlot(drawn, baseStart, 90*time.Second)
+			require.Equal(t, test.expectedSlot, slot)
+			require.Equal(t, test.expectedOnGrid, onGrid)
+		})
+	}
+}
+
+func TestSlotMapAssign(t *testing.T) {
+	baseStart, _ := time.Parse(timeLayout, "10:00:00.000")
+	delta := 90 * time.Second
+	slots := make(slotMap)
+
+	slot, warnings := slots.assign(Bib{Number: 1}, baseStart, baseStart, delta)
+	require.Equal(t, 1, slot)
+	require.Empty(t, warnings)
+
+	slot, warnings = slots.assign(Bib{Number: 2}, baseStart.Add(delta), baseStart, delta)
+	require.Equal(t, 2, slot)
+	require.Empty(t, warnings)
+
+	slot, warnings = slots.assign(Bib{Number: 3}, baseStart.Add(delta), baseStart, delta)
+	require.Equal(t, 2, slot)
+	require.Len(t, warnings, 1)
+	require.Equal(t, WarnSlotCollision, warnings[0].Code)
+	require.Equal(t, Bib{Number: 2}, slots[2])
+
+	slot, warnings = slots.assign(Bib{Number: 4}, baseStart.Add(delta+time.Second), baseStart, delta)
+	require.Equal(t, 2, slot)
+	require.Len(t, warnings, 2)
+	require.Equal(t, WarnOffGridStart, warnings[0].Code)
+	require.Equal(t, WarnSlotCollision, warnings[1].Code)
+}
+
+func TestDrawBeforeBaseStart(t *testing.T) {
+	baseStart, _ := time.Parse(timeLayout, "10:00:00.000")
+	slots := make(slotMap)
+	slot, warnings := slots.assign(Bib{Number: 1}, baseStart.Add(-30*time.Second), baseStart, 90*time.Second)
+	require.Equal(t, 0, slot)
+	require.Equal(t, []Warning{{Code: WarnDrawBeforeStart, Message: "start time 09:59:30.000 is before the race start 10:00:00.000"}}, warnings)
+	require.Empty(t, slots)
+
+	tests := []struct {
+		name         string
+		started      string
+		disqualified bool
+	}{
+		{name: "started in the interval", started: "09:59:35.000"},
+		{name: "started late", started: "10:01:05.000", disqualified: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := newTestRace(t,
+				"[09:31:49.285] 1 1",
+				"[09:55:00.000] 2 1 09:59:30.000",
+				"["+test.started+"] 4 1",
+			)
+			var out bytes.Buffer
+			p := newProcessor(r, nil, &out)
+			require.NoError(t, p.ProcessAll(r.events))
+			require.Contains(t, out.String(), "[09:55:00.000] The start time for the competitor(1) was set by a draw to 09:59:30.000\n")
+			require.Contains(t, out.String(), "draw_before_start")
+			require.Equal(t, test.disqualified, p.Competitors()[Bib{Number: 1}].lateStart)
+		})
+	}
+}
+
+func TestRetroactiveDraw(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		started string
+		status  Status
+	}{
+		{name: "on time", started: "[10:00:01.500] 4 1", status: StatusNotFinished},
+		{name: "late", started: "[10:01:35.000] 4 1", status: StatusDisqualified},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			run := func(lines ...string) (*Processor, string) {
+				r := newTestRace(t, lines...)
+				var out bytes.Buffer
+				p := newProcessor(r, nil, &out)
+				require.NoError(t, p.ProcessAll(r.events))
+				return p, out.String()
+			}
+			before, beforeOut := run("[09:31:49.285] 1 1", "[09:55:00.000] 2 1 10:00:00.000", test.started)
+			after, afterOut := run("[09:31:49.285] 1 1", test.started, "[10:02:00.000] 2 1 10:00:00.000")
+
+			require.Equal(t, before.Results(), after.Results())
+			require.Equal(t, test.status, after.Results()[0].Status)
+			require.NotContains(t, beforeOut, "retroactive_draw")
+			require.Contains(t, afterOut, "retroactive_draw")
+			require.Equal(t, strings.Contains(beforeOut, "disqualified for late start"), strings.Contains(afterOut, "disqualified for late start"))
+		})
+	}
+}
+
+func TestFractionalStartDelta(t *testing.T) {
+	t.Parallel()
+	var lines []string
+	for i := 1; i <= 10; i++ {
+		lines = append(lines, fmt.Sprintf("[09:30:00.000] 1 %d", i))
+	}
+	baseStart, _ := time.Parse(timeLayout, "10:00:00.000")
+	for i := 1; i <= 10; i++ {
+		drawn := baseStart.Add(time.Duration(i-1) * 37500 * time.Millisecond)
+		lines = append(lines, fmt.Sprintf("[09:55:00.000] 2 %d %s", i, drawn.Format(timeLayout)))
+	}
+	r := newTestRace(t, lines...)
+	r.cfg.StartDelta = "00:00:37.5"
+	var err error
+	r.delta, err = parser.ParseDelta(r.cfg.StartDelta)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Contains(t, out.String(), "The start time for the competitor(10) was set by a draw to 10:05:37.500 (slot #10)\n")
+	require.NotContains(t, out.String(), "Warning")
+	for i := 1; i <= 10; i++ {
+		c := p.Competitors()[Bib{Number: i}]
+		require.False(t, c.lateStart, i)
+	}
+	require.Equal(t, 337500*time.Millisecond, p.Competitors()[Bib{Number: 10}].StartTime.Sub(baseStart))
+}
+
+// TestMassStartWithoutDelta runs a mass start, whose startDelta of 0 leaves
+// no grid to slot the draws into.
+func TestMassStartWithoutDelta(t *testing.T) {
+	config := writeConfig(t, `{`+strings.Replace(baseConfigFields, `"00:01:30"`, `"00:00:00"`, 1)+`, "discipline": "massstart"}`)
+	events := filepath.Join(t.TempDir(), "events")
+	require.NoError(t, os.WriteFile(events, []byte("[09:30:00.000] 1 1\n[09:30:01.000] 1 2\n[09:45:00.000] 2 1 10:00:00.000\n"+
+		"[09:45:01.000] 2 2 10:00:00.000\n[10:00:00.500] 4 1\n[10:00:00.600] 4 2\n"), 0o644))
+	var stdout bytes.Buffer
+	require.Equal(t, 0, Run([]string{"-config", config, "-events", events, "-respace"}, &stdout, &bytes.Buffer{}))
+	require.Contains(t, stdout.String(), "The start time for the competitor(2) was set by a draw to 10:00:00.000\n")
+	require.NotContains(t, stdout.String(), "slot_collision")
+}
@@ -0,0 +1,102 @@
+package biathlon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sparkline scopes: the lap times of a finisher are scaled between the
+// fastest and slowest lap of the finisher alone or of all finishers.
+const (
+	SparklineCompetitor = "competitor"
+	SparklineField      = "field"
+	SparklineOff        = "off"
+)
+
+var (
+	sparkGlyphs = []rune("▁▂▃▅▇")
+	asciiGlyphs = []rune("_.-=#")
+)
+
+// reportStyle is how the text report is drawn: the locale of its numbers
+// and the lap sparklines. The zero sparkline draws none.
+type reportStyle struct {
+	locale
+	sparkline string
+	ascii     bool
+}
+
+// style resolves the report flags, rejecting an unknown locale or
+// sparkline scope.
+func (o reportOptions) style() (reportStyle, error) {
+	loc, err := lookupLocale(o.locale)
+	if err != nil {
+		return reportStyle{}, err
+	}
+	switch o.sparkline {
+	case SparklineCompetitor, SparklineField, SparklineOff:
+	default:
+		return reportStyle{}, fmt.Errorf("unknown sparkline %q: want %s, %s or %s", o.sparkline, SparklineCompetitor, SparklineField, SparklineOff)
+	}
+	return reportStyle{locale: loc, sparkline: o.sparkline, ascii: o.noUnicode}, nil
+}
+
+// lapRange returns the fastest and slowest of the lap times.
+func lapRange(laps []LapResult) (lo, hi time.Duration) {
+	for i, lap := range laps {
+		if i == 0 || lap.Time < lo {
+			lo = lap.Time
+		}
+		if i == 0 || lap.Time > hi {
+			hi = lap.Time
+		}
+	}
+	return lo, hi
+}
+
+// sparkline draws one glyph per lap, the slowest lap in lo..hi the tallest.
+// The laps of a flat range, such as a single lap, all get the middle glyph.
+func sparkline(laps []LapResult, lo, hi time.Duration, glyphs []rune) string {
+	var b strings.Builder
+	top := len(glyphs) - 1
+	for _, lap := range laps {
+		level := top / 2
+		if hi > lo {
+			level = int((int64(lap.Time-lo)*int64(top)*2 + int64(hi-lo)) / (int64(hi-lo) * 2))
+		}
+		b.WriteRune(glyphs[level])
+	}
+	return b.String()
+}
+
+// sparklines returns the sparkline of every finisher by bib, scaled as the
+// style asks, or nil when it asks for none.
+func sparklines(results []Result, style reportStyle) map[Bib]string {
+	if style.sparkline == "" || style.sparkline == SparklineOff {
+		return nil
+	}
+	glyphs := sparkGlyphs
+	if style.ascii {
+		glyphs = asciiGlyphs
+	}
+	var field []LapResult
+	for _, r := range results {
+		if r.Status == StatusFinished {
+			field = append(field, r.Laps...)
+		}
+	}
+	fieldLo, fieldHi := lapRange(field)
+	lines := make(map[Bib]string)
+	for _, r := range results {
+		if r.Status != StatusFinished || len(r.Laps) == 0 {
+			continue
+		}
+		lo, hi := fieldLo, fieldHi
+		if style.sparkline == SparklineCompetitor {
+			lo, hi = lapRange(r.Laps)
+		}
+		lines[r.Bib] = sparkline(r.Laps, lo, hi, glyphs)
+	}
+	return lines
+}
@@ -0,0 +1,67 @@
+package biathlon
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sparkLaps(seconds ...int) []LapResult {
+	laps := make([]LapResult, len(seconds))
+	for i, s := range seconds {
+		laps[i] = LapResult{Time: time.Duration(s) * time.Second}
+	}
+	return laps
+}
+
+func TestSparklines(t *testing.T) {
+	t.Parallel()
+	field := []Result{
+		{Bib: Bib{Number: 1}, Status: StatusFinished, Laps: sparkLaps(100, 110, 120, 130, 140)},
+		{Bib: Bib{Number: 2}, Status: StatusFinished, Laps: sparkLaps(80, 160, 120)},
+		{Bib: Bib{Number: 3}, Status: StatusFinished, Laps: sparkLaps(125)},
+		{Bib: Bib{Number: 4}, Status: StatusNotFinished, Laps: sparkLaps(10, 500)},
+	}
+	tests := []struct {
+		name  string
+		style reportStyle
+		want  map[Bib]string
+	}{
+		{
+			name:  "competitor",
+			style: reportStyle{sparkline: SparklineCompetitor},
+			want:  map[Bib]string{{Number: 1}: "▁▂▃▅▇", {Number: 2}: "▁▇▃", {Number: 3}: "▃"},
+		},
+		{
+			name:  "field",
+			style: reportStyle{sparkline: SparklineField},
+			want:  map[Bib]string{{Number: 1}: "▂▃▃▅▅", {Number: 2}: "▁▇▃", {Number: 3}: "▃"},
+		},
+		{
+			name:  "ascii",
+			style: reportStyle{sparkline: SparklineCompetitor, ascii: true},
+			want:  map[Bib]string{{Number: 1}: "_.-=#", {Number: 2}: "_#-", {Number: 3}: "-"},
+		},
+		{
+			name:  "off",
+			style: reportStyle{sparkline: SparklineOff},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.want, sparklines(field, test.style))
+		})
+	}
+}
+
+func TestRenderTextSparkline(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	style := reportStyle{locale: locales["en"], sparkline: SparklineCompetitor, ascii: true}
+	require.NoError(t, renderText(&out, []Result{resultFixture}, style))
+	require.Equal(t, "1. 25:26.047 Competitor 7b: laps count 2, laps [{00:12:01.000, 4.850, 5.120}, {00:11:59.000, 4.870, 5.010}] #_, "+
+		"Penalty [{00:00:29.000, 5.170}], Hits 8/10, Misses 2, Range 00:00:59.600 [00:00:31.200, 00:00:28.400], Course 00:24:26.447, Photo finish pending confirmation, Reason Lost a ski (medic=yes)\n", out.String())
+}
@@ -0,0 +1,89 @@
+package biathlon
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const WarnStartLineTimeout WarningCode = "start_line_timeout"
+
+// startLineWatch tracks the competitors on the start line who haven't
+// started yet and flags those still there when the timeout expires: the
+// athlete likely refused the start or the gate failed.
+type startLineWatch struct {
+	timeout time.Duration
+	open    map[Bib]time.Time
+}
+
+func newStartLineWatch(timeout time.Duration) *startLineWatch {
+	return &startLineWatch{timeout: timeout, open: make(map[Bib]time.Time)}
+}
+
+// StartLineAlert is a start line state that timed out.
+type StartLineAlert struct {
+	Bib     Bib
+	OnLine  time.Time
+	Expired time.Time
+}
+
+func (a StartLineAlert) Warning(timeout time.Duration) Warning {
+	return Warning{Code: WarnStartLineTimeout, Message: fmt.Sprintf("no start within %s of the start line at %s",
+		formatDuration(timeout), a.OnLine.Format(timeLayout))}
+}
+
+// line records that bib is on the start line at t.
+func (s *startLineWatch) line(bib Bib, t time.Time) {
+	s.open[bib] = t
+}
+
+// resolve closes the start line state of bib, when it started.
+func (s *startLineWatch) resolve(bib Bib) {
+	delete(s.open, bib)
+}
+
+// expire closes and returns the start line states whose timeout has passed
+// at now, in expiry order.
+func (s *startLineWatch) expire(now time.Time) []StartLineAlert {
+	var alerts []StartLineAlert
+	for bib, t := range s.open {
+		if expired := t.Add(s.timeout); now.After(expired) {
+			alerts = append(alerts, StartLineAlert{Bib: bib, OnLine: t, Expired: expired})
+			delete(s.open, bib)
+		}
+	}
+	sortAlerts(alerts)
+	return alerts
+}
+
+// close closes and returns every open start line state: at the end of the
+// events none of them can be resolved any more.
+func (s *startLineWatch) close() []StartLineAlert {
+	var alerts []StartLineAlert
+	for bib, t := range s.open {
+		alerts = append(alerts, StartLineAlert{Bib: bib, OnLine: t, Expired: t.Add(s.timeout)})
+		delete(s.open, bib)
+	}
+	sortAlerts(alerts)
+	return alerts
+}
+
+func sortAlerts(alerts []StartLineAlert) {
+	sort.Slice(alerts, func(i, j int) bool {
+		if !alerts[i].Expired.Equal(alerts[j].Expired) {
+			return alerts[i].Expired.Before(alerts[j].Expired)
+		}
+		return alerts[i].Bib.Less(alerts[j].Bib)
+	})
+}
+
+// alertLine formats a start line alert for the commentary, at the time the
+// timeout expired.
+func alertLine(a StartLineAlert, timeout time.Duration) string {
+	return fmt.Sprintf("[%s] %s", formatStamp(a.Expired), alertRecord(a, timeout).Message)
+}
+
+// alertRecord is the commentary record of a start line alert.
+func alertRecord(a StartLineAlert, timeout time.Duration) LogRecord {
+	return LogRecord{Level: LevelWarn, Time: a.Expired, Competitor: a.Bib, Message: fmt.Sprintf("Warning for competitor(%s): %s", a.Bib, a.Warning(timeout))}
+}
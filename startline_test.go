@@ -0,0 +1,69 @@
+package biathlon
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartLineTimeout(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:31:50.285] 1 2",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[09:55:01.000] 2 2 10:01:30.000",
+		"[09:59:45.000] 3 1",
+		"[10:00:01.000] 4 1",
+		"[10:01:10.000] 3 2",
+	)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	for _, e := range r.events {
+		require.NoError(t, p.Process(e))
+	}
+
+	// Competitor 1 started in time, competitor 2 is still within the window.
+	clock := r.events[len(r.events)-1].Time
+	p.Tick(clock.Add(2 * time.Minute))
+	require.NotContains(t, out.String(), "start_line_timeout")
+
+	p.Tick(clock.Add(2*time.Minute + time.Millisecond))
+	require.Contains(t, out.String(), "[10:03:10.000] Warning for competitor(2): start_line_timeout: no start within 00:02:00.000 of the start line at 10:01:10.000\n")
+
+	// The alert is raised once.
+	out.Reset()
+	p.Tick(clock.Add(time.Hour))
+	require.Empty(t, out.String())
+}
+
+func TestStartLineResolvedByStart(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[09:59:45.000] 3 1",
+		"[10:00:01.000] 4 1",
+		"[10:30:00.000] 10 1",
+	)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.NotContains(t, out.String(), "start_line_timeout")
+}
+
+func TestStartLineOpenAtTheEnd(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[09:59:45.000] 3 1",
+	)
+	var out bytes.Buffer
+	p := newProcessor(r, nil, &out)
+	require.NoError(t, p.ProcessAll(r.events))
+	require.Contains(t, out.String(), "[10:01:45.000] Warning for competitor(1): start_line_timeout")
+
+	require.Equal(t, []string{
+		"[10:01:45.000] Warning for competitor(1): start_line_timeout: no start within 00:02:00.000 of the start line at 09:59:45.000",
+	}, preflight(r))
+}
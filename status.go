@@ -0,0 +1,57 @@
+package biathlon
+
+import "time"
+
+// Status is how a competitor's race ended.
+type Status string
+
+// Competitor statuses.
+const (
+	// StatusFinished is a competitor who completed every lap.
+	StatusFinished Status = "Finished"
+	// StatusLapped is a competitor pulled from the course for being over
+	// an intermediate cutoff.
+	StatusLapped Status = "Lapped"
+	// StatusNotFinished is a competitor who started but didn't complete
+	// every lap, including one who commented they can't continue.
+	StatusNotFinished Status = "NotFinished"
+	// StatusDisqualified is a competitor who started late or was
+	// disqualified by a rule.
+	StatusDisqualified Status = "Disqualified"
+	// StatusNotStarted is a competitor who never started.
+	StatusNotStarted Status = "NotStarted"
+)
+
+// statusOrder is the order in which results without a place follow the
+// finishers.
+var statusOrder = map[Status]int{StatusFinished: 0, StatusLapped: 1, StatusNotFinished: 2, StatusDisqualified: 3, StatusNotStarted: 4}
+
+// status derives the status of c from the events applied so far.
+func (c *Competitor) status(cfg Config) Status {
+	switch {
+	case !c.Started:
+		return StatusNotStarted
+	case c.lateStart || c.disqualified:
+		return StatusDisqualified
+	case c.pulled != 0:
+		return StatusLapped
+	case c.retired || c.FinishTime.IsZero() || c.LapsCompleted != cfg.Laps:
+		return StatusNotFinished
+	default:
+		return StatusFinished
+	}
+}
+
+// startedLate reports whether c started after its drawn start plus the
+// start interval. A start without a draw is late.
+func (c *Competitor) startedLate(delta time.Duration) bool {
+	return c.StartTime.IsZero() || c.ActualStart.After(c.StartTime.Add(delta))
+}
+
+// settleStatuses sets the final status of every competitor once all the
+// events are applied.
+func settleStatuses(competitors map[Bib]*Competitor, cfg Config) {
+	for _, c := range competitors {
+		c.Status = c.status(cfg)
+	}
+}
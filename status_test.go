@@ -0,0 +1,82 @@
+package biathlon
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+	registered := []string{"[09:31:49.285] 1 1", "[09:55:00.000] 2 1 10:00:00.000"}
+	tests := []struct {
+		name   string
+		lines  []string
+		status Status
+		tag    string
+	}{
+		{
+			name:   "registers only",
+			lines:  []string{"[09:31:49.285] 1 1"},
+			status: StatusNotStarted,
+			tag:    "- [NotStarted] Competitor 1:",
+		},
+		{
+			name:   "drawn but never started",
+			lines:  registered,
+			status: StatusNotStarted,
+			tag:    "- [NotStarted] Competitor 1:",
+		},
+		{
+			name:   "start after the delta",
+			lines:  append(registered, "[10:01:30.001] 4 1", "[10:13:00.000] 10 1", "[10:26:00.000] 10 1"),
+			status: StatusDisqualified,
+			tag:    "- [Disqualified] Competitor 1:",
+		},
+		{
+			name:   "started without a draw",
+			lines:  []string{"[09:31:49.285] 1 1", "[10:00:01.000] 4 1", "[10:13:00.000] 10 1", "[10:26:00.000] 10 1"},
+			status: StatusDisqualified,
+			tag:    "- [Disqualified] Competitor 1:",
+		},
+		{
+			name:   "draw after a skipped slot",
+			lines:  []string{"[09:31:49.285] 1 1", "[09:55:00.000] 2 1 10:03:00.000", "[10:03:01.000] 4 1", "[10:16:00.000] 10 1", "[10:29:00.000] 10 1"},
+			status: StatusFinished,
+			tag:    "1. 26:00 Competitor 1:",
+		},
+		{
+			name:   "can't continue",
+			lines:  append(registered, "[10:00:01.000] 4 1", "[10:13:00.000] 10 1", "[10:20:00.000] 11 1 Lost in the forest"),
+			status: StatusNotFinished,
+			tag:    "- [NotFinished] Competitor 1:",
+		},
+		{
+			name:   "laps missing",
+			lines:  append(registered, "[10:00:01.000] 4 1", "[10:13:00.000] 10 1"),
+			status: StatusNotFinished,
+			tag:    "- [NotFinished] Competitor 1:",
+		},
+		{
+			name:   "finished",
+			lines:  append(registered, "[10:00:01.000] 4 1", "[10:13:00.000] 10 1", "[10:26:00.000] 10 1"),
+			status: StatusFinished,
+			tag:    "1. 26:00 Competitor 1:",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			r := newTestRace(t, test.lines...)
+			p := newProcessor(r, nil, io.Discard)
+			require.NoError(t, p.ProcessAll(r.events))
+			require.Equal(t, test.status, p.Competitors()[Bib{Number: 1}].Status)
+
+			var out bytes.Buffer
+			printResults(&out, p.Competitors(), r.cfg, nil, reportStyle{locale: locales["en"]})
+			require.Contains(t, out.String(), "\n"+test.tag)
+		})
+	}
+}
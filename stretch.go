@@ -0,0 +1,111 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Span is a period of time with a start and, once it is over, an end.
+type Span struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// skiingStretches splits the competitor's race, from the actual start to
+// the last checkpoint crossed, into the stretches skied without stopping
+// at the firing range or going into the penalty laps. Lap crossings don't
+// interrupt a stretch.
+func (c *Competitor) skiingStretches() []Span {
+	if !c.Started {
+		return nil
+	}
+	end := c.ActualStart
+	var stops []Span
+	for _, b := range c.Bouts {
+		stops = append(stops, Span{b.Start, b.End})
+	}
+	for _, p := range c.Penalties {
+		stops = append(stops, p.Span)
+	}
+	for _, stop := range stops {
+		end = latest(end, stop.Start, stop.End)
+	}
+	for _, t := range c.LapEnds {
+		end = latest(end, t)
+	}
+	sort.Slice(stops, func(i, j int) bool {
+		return stops[i].Start.Before(stops[j].Start)
+	})
+
+	var stretches []Span
+	from := c.ActualStart
+	for _, stop := range stops {
+		if stop.Start.After(from) {
+			stretches = append(stretches, Span{from, stop.Start})
+		}
+		if stop.End.IsZero() {
+			return stretches
+		}
+		from = latest(from, stop.End)
+	}
+	if end.After(from) {
+		stretches = append(stretches, Span{from, end})
+	}
+	return stretches
+}
+
+func latest(t time.Time, others ...time.Time) time.Time {
+	for _, o := range others {
+		if o.After(t) {
+			t = o
+		}
+	}
+	return t
+}
+
+// longestStretch returns the longest uninterrupted skiing stretch of c.
+func (c *Competitor) longestStretch() (longest Span, ok bool) {
+	for _, s := range c.skiingStretches() {
+		if !ok || s.Duration() > longest.Duration() {
+			longest, ok = s, true
+		}
+	}
+	return longest, ok
+}
+
+// printFunFacts prints the field record for the longest uninterrupted
+// skiing stretch followed by every competitor's longest stretch.
+func printFunFacts(w io.Writer, competitors map[Bib]*Competitor) {
+	var record Span
+	var recordBib Bib
+	found := false
+	var lines []string
+	for _, bib := range sortedBibs(competitors) {
+		s, ok := competitors[bib].longestStretch()
+		if !ok {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  competitor(%s) %s", bib, formatStretch(s)))
+		if !found || s.Duration() > record.Duration() {
+			record, recordBib, found = s, bib, true
+		}
+	}
+	if !found {
+		return
+	}
+	fmt.Fprintln(w, "\nFun facts:")
+	fmt.Fprintf(w, "Longest skiing stretch: competitor(%s) %s\n", recordBib, formatStretch(record))
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+func formatStretch(s Span) string {
+	return fmt.Sprintf("%s (%s - %s)", formatDuration(s.Duration()), s.Start.Format(timeLayout), s.End.Format(timeLayout))
+}
@@ -0,0 +1,50 @@
+package biathlon
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkiingStretches(t *testing.T) {
+	r := newTestRace(t,
+		"[09:31:49.285] 1 1",
+		"[09:31:50.285] 1 2",
+		"[09:55:00.000] 2 1 10:00:00.000",
+		"[09:55:01.000] 2 2 10:01:30.000",
+		"[10:00:00.000] 4 1",
+		"[10:08:00.000] 5 1 1",
+		"[10:09:00.000] 7 1",
+		"[10:09:10.000] 8 1",
+		"[10:10:00.000] 9 1",
+		// The lap line doesn't interrupt the stretch.
+		"[10:15:00.000] 10 1",
+		"[10:21:00.000] 5 1 2",
+		"[10:22:00.000] 7 1",
+		"[10:30:00.000] 10 1",
+		"[10:01:30.000] 4 2",
+		"[10:05:30.000] 5 2 1",
+	)
+	p := newProcessor(r, nil, io.Discard)
+	require.NoError(t, p.ProcessAll(r.events))
+
+	first := p.Competitors()[Bib{Number: 1}]
+	var durations []string
+	for _, s := range first.skiingStretches() {
+		durations = append(durations, formatDuration(s.Duration()))
+	}
+	require.Equal(t, []string{"00:08:00.000", "00:00:10.000", "00:11:00.000", "00:08:00.000"}, durations)
+
+	// Competitor 2 is still on the range: only the stretch before it counts.
+	second := p.Competitors()[Bib{Number: 2}]
+	require.Len(t, second.skiingStretches(), 1)
+
+	var out bytes.Buffer
+	printFunFacts(&out, p.Competitors())
+	require.Equal(t, "\nFun facts:\n"+
+		"Longest skiing stretch: competitor(1) 00:11:00.000 (10:10:00.000 - 10:21:00.000)\n"+
+		"  competitor(1) 00:11:00.000 (10:10:00.000 - 10:21:00.000)\n"+
+		"  competitor(2) 00:04:00.000 (10:01:30.000 - 10:05:30.000)\n", out.String())
+}
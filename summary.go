@@ -0,0 +1,83 @@
+package biathlon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// exitSummary is the JSON line written to stderr when the CLI exits, for
+// the orchestration wrapping it. It is filled in while the command runs,
+// possibly from another goroutine than the one writing it.
+type exitSummary struct {
+	mu        sync.Mutex
+	OK        bool     `json:"ok"`
+	ExitCode  int      `json:"exitCode"`
+	RaceID    string   `json:"raceId"`
+	Finishers int      `json:"finishers"`
+	Warnings  int      `json:"warnings"`
+	Errors    []string `json:"errors"`
+}
+
+// fail prints the error made of a, formatted like fmt.Println, to w,
+// records it and returns the exit code of a failed command.
+func (s *exitSummary) fail(w io.Writer, a ...any) int {
+	msg := strings.TrimSuffix(fmt.Sprintln(a...), "\n")
+	fmt.Fprintln(w, msg)
+	s.addError(msg)
+	return 1
+}
+
+func (s *exitSummary) addError(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Errors = append(s.Errors, msg)
+}
+
+// update changes the summary under its lock.
+func (s *exitSummary) update(f func(s *exitSummary)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(s)
+}
+
+// write writes the summary with the exit code as a single line to w.
+func (s *exitSummary) write(w io.Writer, code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ExitCode = code
+	s.OK = code == 0
+	if s.Errors == nil {
+		s.Errors = []string{}
+	}
+	// The summary only holds strings and numbers, which always marshal.
+	data, _ := json.Marshal(s)
+	fmt.Fprintf(w, "%s\n", data)
+}
+
+// lastWriter passes writes through until it is sealed and drops them
+// after, so that nothing follows the summary on stderr.
+type lastWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	sealed bool
+}
+
+func (l *lastWriter) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.sealed {
+		return len(b), nil
+	}
+	return l.w.Write(b)
+}
+
+// seal writes the summary as the last write to the underlying writer.
+func (l *lastWriter) seal(s *exitSummary, code int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s.write(l.w, code)
+	l.sealed = true
+}
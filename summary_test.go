@@ -0,0 +1,87 @@
+package biathlon
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitSummary(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "race.json")
+	typoPath := filepath.Join(dir, "typo.json")
+	eventsPath := filepath.Join(dir, "race.log")
+	const config = `"laps": 1, "lapLen": 3000, "penaltyLen": 150, "firingLines": 1,
+		"targetsPerLine": 5, "start": "10:00:00.000", "startDelta": "00:00:30"`
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"raceId": "sprint-7", `+config+`}`), 0o644))
+	require.NoError(t, os.WriteFile(typoPath, []byte(`{"lasp": 2, `+config+`}`), 0o644))
+	require.NoError(t, os.WriteFile(eventsPath, []byte("[09:30:00.000] 1 42\n"+
+		"[09:31:00.000] 2 42 10:00:00.000\n"+
+		"[10:00:01.000] 4 42\n"+
+		"[10:10:00.000] 10 42\n"+
+		"[10:11:00.000] 1 43\n"+
+		"[10:12:00.000] 4 44\n"), 0o644))
+
+	tests := []struct {
+		name string
+		args []string
+		want *exitSummary
+	}{
+		{
+			name: "success",
+			args: []string{"-config", configPath, "-events", eventsPath},
+			// Two defaulted config fields and the orphan start of 44.
+			want: &exitSummary{OK: true, RaceID: "sprint-7", Finishers: 1, Warnings: 3, Errors: []string{}},
+		},
+		{
+			name: "missing config",
+			args: []string{"-config", filepath.Join(dir, "nope.json"), "-events", eventsPath},
+			want: &exitSummary{ExitCode: 1, Errors: []string{"config error: config file not found: open " + filepath.Join(dir, "nope.json") + ": no such file or directory"}},
+		},
+		{
+			name: "strict config",
+			args: []string{"-strict-config", "-config", typoPath, "-events", eventsPath},
+			want: &exitSummary{ExitCode: 1, Errors: []string{"config error: " + typoPath + `: json: unknown field "lasp" (did you mean "laps"?)`}},
+		},
+		{
+			name: "unknown flag",
+			args: []string{"-nope"},
+			want: &exitSummary{ExitCode: 2, Errors: []string{"flag provided but not defined: -nope"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			code := Run(test.args, &bytes.Buffer{}, &stderr)
+			require.Equal(t, test.want.ExitCode, code)
+			require.True(t, strings.HasSuffix(stderr.String(), "\n"))
+			lines := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+
+			var got exitSummary
+			require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &got))
+			require.Equal(t, test.want.OK, got.OK)
+			require.Equal(t, test.want.ExitCode, got.ExitCode)
+			require.Equal(t, test.want.RaceID, got.RaceID)
+			require.Equal(t, test.want.Finishers, got.Finishers)
+			require.Equal(t, test.want.Warnings, got.Warnings)
+			require.Equal(t, test.want.Errors, got.Errors)
+		})
+	}
+}
+
+func TestLastWriterDropsWritesAfterSeal(t *testing.T) {
+	var stderr bytes.Buffer
+	w := &lastWriter{w: &stderr}
+	_, err := w.Write([]byte("before\n"))
+	require.NoError(t, err)
+	w.seal(&exitSummary{}, 0)
+	n, err := w.Write([]byte("after\n"))
+	require.NoError(t, err)
+	require.Equal(t, 6, n)
+	require.Equal(t, "before\n"+`{"ok":true,"exitCode":0,"raceId":"","finishers":0,"warnings":0,"errors":[]}`+"\n", stderr.String())
+}
@@ -0,0 +1,72 @@
+package biathlon
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// devel stands in for build details that weren't recorded.
+const devel = "devel"
+
+// version and buildDate may be set by release builds with
+// -ldflags "-X main.version=... -X main.buildDate=...". They take
+// precedence over the build info embedded by the Go toolchain.
+var (
+	version   string
+	buildDate string
+)
+
+// BuildInfo describes the build of the running binary.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	Dirty     bool   `json:"dirty"`
+	Date      string `json:"date"`
+	GoVersion string `json:"goVersion"`
+}
+
+// currentBuild returns the build info of the running binary.
+func currentBuild() BuildInfo {
+	info, _ := debug.ReadBuildInfo()
+	return buildInfoFrom(info, version, buildDate)
+}
+
+// buildInfoFrom composes the build info from the toolchain build info, which
+// may be nil, and the linker overrides. Missing values become "devel".
+func buildInfoFrom(info *debug.BuildInfo, version, date string) BuildInfo {
+	b := BuildInfo{Version: version, Date: date, GoVersion: runtime.Version()}
+	if info != nil {
+		b.GoVersion = info.GoVersion
+		if b.Version == "" && info.Main.Version != "(devel)" {
+			b.Version = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				b.Revision = s.Value
+			case "vcs.modified":
+				b.Dirty = s.Value == "true"
+			case "vcs.time":
+				if b.Date == "" {
+					b.Date = s.Value
+				}
+			}
+		}
+	}
+	for _, field := range []*string{&b.Version, &b.Revision, &b.Date} {
+		if *field == "" {
+			*field = devel
+		}
+	}
+	return b
+}
+
+// String formats the build info for -version and the report footers.
+func (b BuildInfo) String() string {
+	revision := b.Revision
+	if b.Dirty {
+		revision += "-dirty"
+	}
+	return fmt.Sprintf("%s (revision %s, built %s, %s)", b.Version, revision, b.Date, b.GoVersion)
+}
@@ -0,0 +1,51 @@
+package biathlon
+
+import (
+	"bytes"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInfoFrom(t *testing.T) {
+	t.Parallel()
+	info := &debug.BuildInfo{
+		GoVersion: "go1.23.4",
+		Main:      debug.Module{Path: "BiathlonCompetitions", Version: "v1.4.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123abcd"},
+			{Key: "vcs.modified", Value: "true"},
+			{Key: "vcs.time", Value: "2026-02-01T10:00:00Z"},
+		},
+	}
+	tests := []struct {
+		name     string
+		info     *debug.BuildInfo
+		version  string
+		date     string
+		expected string
+	}{
+		{name: "vcs build", info: info, expected: "v1.4.0 (revision 0123abcd-dirty, built 2026-02-01T10:00:00Z, go1.23.4)"},
+		{name: "linker overrides", info: info, version: "v1.5.0-rc1", date: "2026-03-01", expected: "v1.5.0-rc1 (revision 0123abcd-dirty, built 2026-03-01, go1.23.4)"},
+		{name: "no vcs info", info: &debug.BuildInfo{GoVersion: "go1.23.4", Main: debug.Module{Version: "(devel)"}}, expected: "devel (revision devel, built devel, go1.23.4)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.expected, buildInfoFrom(test.info, test.version, test.date).String())
+		})
+	}
+
+	b := buildInfoFrom(nil, "", "")
+	require.Equal(t, devel, b.Version)
+	require.Equal(t, devel, b.Revision)
+	require.False(t, b.Dirty)
+	require.NotEmpty(t, b.GoVersion)
+}
+
+func TestRunVersion(t *testing.T) {
+	var stdout bytes.Buffer
+	require.Equal(t, 0, Run([]string{"-version"}, &stdout, &bytes.Buffer{}))
+	require.Equal(t, "biathlon "+currentBuild().String()+"\n", stdout.String())
+}
@@ -0,0 +1,31 @@
+//go:build js && wasm
+
+package wasm
+
+import "syscall/js"
+
+// jsArgument is a js.Value passed as an Argument.
+type jsArgument struct {
+	js.Value
+}
+
+func (a jsArgument) IsString() bool {
+	return a.Type() == js.TypeString
+}
+
+// Register sets the global JavaScript function processRace. It returns an
+// object with the JSON report in results, or with the message in error
+// when the race can't be processed.
+func Register() {
+	js.Global().Set("processRace", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		wrapped := make([]Argument, len(args))
+		for i, a := range args {
+			wrapped[i] = jsArgument{a}
+		}
+		results, err := ProcessRace(wrapped)
+		if err != nil {
+			return map[string]any{"error": err.Error()}
+		}
+		return map[string]any{"results": results}
+	}))
+}
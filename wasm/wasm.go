@@ -0,0 +1,46 @@
+// Package wasm exposes the race processing to JavaScript when built for
+// GOOS=js GOARCH=wasm. The arguments are marshaled through the Argument
+// interface, so that everything but the syscall/js binding builds and is
+// tested natively.
+package wasm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	biathlon "BiathlonCompetitions"
+)
+
+// Argument is a JavaScript value passed to processRace.
+type Argument interface {
+	IsString() bool
+	String() string
+}
+
+// processRaceParams names the parameters of processRace, in order.
+var processRaceParams = []string{"configJSON", "eventsText"}
+
+// ProcessRace is processRace(configJSON, eventsText): it runs the race of
+// the JSON config and the event lines and returns the final results as
+// the JSON report.
+func ProcessRace(args []Argument) (string, error) {
+	if len(args) != len(processRaceParams) {
+		return "", fmt.Errorf("processRace expects %d arguments (%s), got %d",
+			len(processRaceParams), strings.Join(processRaceParams, ", "), len(args))
+	}
+	for i, a := range args {
+		if !a.IsString() {
+			return "", fmt.Errorf("processRace argument %s must be a string", processRaceParams[i])
+		}
+	}
+	results, err := biathlon.ProcessRace(strings.NewReader(args[0].String()), strings.NewReader(args[1].String()))
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := biathlon.Render(&out, results, "json"); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,79 @@
+package wasm
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stringArg and numberArg stand in for the JavaScript values.
+type stringArg string
+
+func (a stringArg) IsString() bool { return true }
+func (a stringArg) String() string { return string(a) }
+
+type numberArg float64
+
+func (numberArg) IsString() bool { return false }
+func (numberArg) String() string { return "<number>" }
+
+const testConfig = `{"laps": 1, "lapLen": 3500, "penaltyLen": 150, "start": "10:00:00.000", "startDelta": "00:01:30"}`
+
+func TestProcessRace(t *testing.T) {
+	t.Parallel()
+	events := "[09:30:00.000] 1 1\n[09:30:01.000] 1 2\n[09:45:00.000] 2 1 10:00:00.000\n[10:00:00.500] 4 1\n[10:12:40.000] 10 1\n"
+	got, err := ProcessRace([]Argument{stringArg(testConfig), stringArg(events)})
+	require.NoError(t, err)
+	var results []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(got), &results))
+	require.Len(t, results, 2)
+	require.Equal(t, "1", results[0]["competitor"])
+	require.Equal(t, "Finished", results[0]["status"])
+	require.Equal(t, float64(760000), results[0]["totalMs"])
+	require.Equal(t, "NotStarted", results[1]["status"])
+}
+
+func TestProcessRaceArguments(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		args     []Argument
+		expected string
+	}{
+		{name: "no arguments", expected: "processRace expects 2 arguments (configJSON, eventsText), got 0"},
+		{name: "too many", args: []Argument{stringArg(testConfig), stringArg(""), stringArg("")}, expected: "got 3"},
+		{name: "config not a string", args: []Argument{numberArg(1), stringArg("")}, expected: "processRace argument configJSON must be a string"},
+		{name: "events not a string", args: []Argument{stringArg(testConfig), numberArg(1)}, expected: "processRace argument eventsText must be a string"},
+		{name: "invalid config", args: []Argument{stringArg(`{"laps": 1}`), stringArg("")}, expected: "config error: "},
+		{name: "invalid events", args: []Argument{stringArg(testConfig), stringArg("not an event\n")}, expected: "events error: "},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ProcessRace(test.args)
+			require.ErrorContains(t, err, test.expected)
+		})
+	}
+}
+
+// TestBuildWASM builds the browser command, and with it the core
+// packages, for GOOS=js GOARCH=wasm.
+func TestBuildWASM(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the wasm target")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go tool")
+	}
+	out := filepath.Join(t.TempDir(), "biathlon.wasm")
+	cmd := exec.Command(goTool, "build", "-o", out, "BiathlonCompetitions/cmd/biathlon-wasm")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	require.FileExists(t, out)
+}
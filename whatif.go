@@ -0,0 +1,78 @@
+package biathlon
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// WhatIf compares a finisher's actual result with the hypothetical result
+// of a clean shooting: no penalty laps and no range time lost to misses.
+type WhatIf struct {
+	Bib              Bib
+	Actual           time.Duration
+	ActualRank       int
+	Hypothetical     time.Duration
+	HypotheticalRank int
+}
+
+// finished reports whether c completed the race and has a valid total time.
+func (c *Competitor) finished(cfg Config) bool {
+	return c.status(cfg) == StatusFinished
+}
+
+// misses is the number of targets missed in the observed bouts shot so far.
+func (c *Competitor) misses(cfg Config) int {
+	return max(c.observedBouts()*cfg.TargetsPerLine-c.Hits, 0)
+}
+
+// whatIfClean ranks the finishers by their actual total time and by the
+// total time less the penalty laps and missOverhead per miss.
+func whatIfClean(competitors map[Bib]*Competitor, cfg Config, missOverhead time.Duration) []WhatIf {
+	var results []WhatIf
+	for _, bib := range sortedBibs(competitors) {
+		comp := competitors[bib]
+		if !comp.finished(cfg) {
+			continue
+		}
+		actual := comp.totalTime()
+		results = append(results, WhatIf{
+			Bib:          bib,
+			Actual:       actual,
+			Hypothetical: actual - totalDuration(comp.PenaltyTimes) - time.Duration(comp.misses(cfg))*missOverhead,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Hypothetical < results[j].Hypothetical
+	})
+	for i := range results {
+		results[i].HypotheticalRank = i + 1
+		if i > 0 && results[i].Hypothetical == results[i-1].Hypothetical {
+			results[i].HypotheticalRank = results[i-1].HypotheticalRank
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Actual < results[j].Actual
+	})
+	for i := range results {
+		results[i].ActualRank = i + 1
+		if i > 0 && results[i].Actual == results[i-1].Actual {
+			results[i].ActualRank = results[i-1].ActualRank
+		}
+	}
+	return results
+}
+
+// printWhatIf prints the actual and the hypothetical clean shooting ranks
+// side by side, in actual rank order.
+func printWhatIf(w io.Writer, results []WhatIf, missOverhead time.Duration) {
+	if len(results) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nHypothetical results with clean shooting (no penalty laps, %s range time per miss):\n", formatDuration(missOverhead))
+	for _, r := range results {
+		fmt.Fprintf(w, "Competitor %s: actual %d. %s, hypothetical %d. %s\n",
+			r.Bib, r.ActualRank, formatDuration(r.Actual), r.HypotheticalRank, formatDuration(r.Hypothetical))
+	}
+}
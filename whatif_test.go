@@ -0,0 +1,40 @@
+package biathlon
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhatIfClean(t *testing.T) {
+	cfg := Config{Laps: 2, TargetsPerLine: 5}
+	start := time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)
+	finisher := func(id int, total time.Duration, hits int, penalties ...time.Duration) *Competitor {
+		return &Competitor{
+			ID: id, Started: true, LapsCompleted: 2, Hits: hits,
+			Bouts:     make([]Bout, 2),
+			StartTime: start, FinishTime: start.Add(total),
+			PenaltyTimes: penalties,
+		}
+	}
+	competitors := map[Bib]*Competitor{
+		{Number: 1}: finisher(1, 30*time.Minute, 10),
+		// Two misses cost competitor 2 the lead.
+		{Number: 2}: finisher(2, 31*time.Minute, 8, time.Minute, time.Minute),
+		{Number: 3}: {ID: 3, Started: true, LapsCompleted: 1, StartTime: start, FinishTime: start.Add(10 * time.Minute)},
+	}
+
+	results := whatIfClean(competitors, cfg, 10*time.Second)
+	require.Equal(t, []WhatIf{
+		{Bib: Bib{Number: 1}, Actual: 30 * time.Minute, ActualRank: 1, Hypothetical: 30 * time.Minute, HypotheticalRank: 2},
+		{Bib: Bib{Number: 2}, Actual: 31 * time.Minute, ActualRank: 2, Hypothetical: 28*time.Minute + 40*time.Second, HypotheticalRank: 1},
+	}, results)
+
+	var out bytes.Buffer
+	printWhatIf(&out, results, 10*time.Second)
+	require.Equal(t, "\nHypothetical results with clean shooting (no penalty laps, 00:00:10.000 range time per miss):\n"+
+		"Competitor 1: actual 1. 00:30:00.000, hypothetical 2. 00:30:00.000\n"+
+		"Competitor 2: actual 2. 00:31:00.000, hypothetical 1. 00:28:40.000\n", out.String())
+}